@@ -0,0 +1,21 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/Pythonyan3/payment-service/internal/providers"
+)
+
+func main() {
+	// local payment provider API backed by fake provider, see SANDBOX_PROVIDER_URL setting
+	var port string = os.Getenv("SANDBOX_PORT")
+
+	if port == "" {
+		port = "8001"
+	}
+
+	log.Printf("sandbox payment provider is listening on :%s", port)
+	log.Fatal(http.ListenAndServe(":"+port, providers.NewSandboxHandler(providers.NewFakeProvider("sandbox"))))
+}
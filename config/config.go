@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"sync"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
@@ -20,7 +21,56 @@ type Config struct {
 	DBPassword  string `envconfig:"DB_PASSWORD" required:"true"`
 	DBName      string `envconfig:"DB_NAME" required:"true"`
 	DBSSLMode   string `envconfig:"DB_SSL_MODE" required:"true"`
-	JWTSignKey  string `envconfig:"JWT_SIGN_KEY" required:"true"`
+	JWTSignKey  string `envconfig:"JWT_SIGN_KEY"`
+
+	// JWKS file path or URL with public keys to verify RS256/ES256 tokens
+	JWKSSource          string        `envconfig:"JWKS_SOURCE"`
+	JWKSRefreshInterval time.Duration `envconfig:"JWKS_REFRESH_INTERVAL" default:"5m"`
+	JWKSRequestTimeout  time.Duration `envconfig:"JWKS_REQUEST_TIMEOUT" default:"10s"`
+	JWTIssuer           string        `envconfig:"JWT_ISSUER"`
+	JWTAudience         string        `envconfig:"JWT_AUDIENCE"`
+
+	// Secrets used by providers to sign proceed requests, format: "provider1:secret1,provider2:secret2"
+	ProviderSigningSecrets map[string]string `envconfig:"PROVIDER_SIGNING_SECRETS"`
+	SignatureTolerance     time.Duration     `envconfig:"SIGNATURE_TOLERANCE" default:"5m"`
+
+	IdempotencyKeyTTL time.Duration `envconfig:"IDEMPOTENCY_KEY_TTL" default:"24h"`
+
+	// Default time to live of NEW transaction, expired transactions are swept every interval by batches
+	TransactionTTL           time.Duration `envconfig:"TRANSACTION_TTL" default:"24h"`
+	TransactionSweepInterval time.Duration `envconfig:"TRANSACTION_SWEEP_INTERVAL" default:"1m"`
+	TransactionSweepBatch    int           `envconfig:"TRANSACTION_SWEEP_BATCH" default:"100"`
+
+	// Max number of created transactions per RATE_LIMIT_PERIOD by authenticated subject, IP and user_id, 0 disables limit
+	RateLimitSubject int           `envconfig:"RATE_LIMIT_SUBJECT" default:"60"`
+	RateLimitIP      int           `envconfig:"RATE_LIMIT_IP" default:"120"`
+	RateLimitUser    int           `envconfig:"RATE_LIMIT_USER" default:"30"`
+	RateLimitPeriod  time.Duration `envconfig:"RATE_LIMIT_PERIOD" default:"1m"`
+
+	WebhookDispatchInterval time.Duration `envconfig:"WEBHOOK_DISPATCH_INTERVAL" default:"1s"`
+	WebhookRequestTimeout   time.Duration `envconfig:"WEBHOOK_REQUEST_TIMEOUT" default:"10s"`
+	WebhookMaxAttempts      int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"10"`
+	WebhookBaseBackoff      time.Duration `envconfig:"WEBHOOK_BASE_BACKOFF" default:"10s"`
+	WebhookMaxBackoff       time.Duration `envconfig:"WEBHOOK_MAX_BACKOFF" default:"1h"`
+
+	// Name of payment provider used when there are no routing rules: "sandbox" or "fake" (in-memory, dev and tests only)
+	PaymentProvider string `envconfig:"PAYMENT_PROVIDER" required:"true"`
+	// Payment providers routing rules, format: "EUR:sandbox,USD:fake" and "merchant1:sandbox"
+	PaymentProviderCurrencies map[string]string `envconfig:"PAYMENT_PROVIDER_CURRENCIES"`
+	PaymentProviderMerchants  map[string]string `envconfig:"PAYMENT_PROVIDER_MERCHANTS"`
+	PaymentProviderTimeout    time.Duration     `envconfig:"PAYMENT_PROVIDER_TIMEOUT" default:"10s"`
+	// URL of sandbox provider API, sandbox provider is not registered if it is not set
+	SandboxProviderURL string `envconfig:"SANDBOX_PROVIDER_URL"`
+
+	// JSON file with risk rules evaluated on transaction creation, no rules are applied if it is not set
+	RiskRulesSource string `envconfig:"RISK_RULES_SOURCE"`
+
+	// CSV or JSON file with daily FX rates, rates are not loaded if it is not set
+	FxRatesSource          string        `envconfig:"FX_RATES_SOURCE"`
+	FxRatesRefreshInterval time.Duration `envconfig:"FX_RATES_REFRESH_INTERVAL" default:"1h"`
+
+	// Max time to drain in-flight requests and stop background workers on SIGINT/SIGTERM
+	ShutdownDrainTimeout time.Duration `envconfig:"SHUTDOWN_DRAIN_TIMEOUT" default:"20s"`
 }
 
 func GetConfig() *Config {
@@ -1,18 +1,25 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 
 	"github.com/Pythonyan3/payment-service/config"
 	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/fx"
 	"github.com/Pythonyan3/payment-service/internal/handlers"
 	"github.com/Pythonyan3/payment-service/internal/middleware"
+	"github.com/Pythonyan3/payment-service/internal/providers"
 	"github.com/Pythonyan3/payment-service/internal/repositories"
+	"github.com/Pythonyan3/payment-service/internal/risk"
 	"github.com/Pythonyan3/payment-service/internal/server"
 	"github.com/Pythonyan3/payment-service/internal/services"
+	"github.com/Pythonyan3/payment-service/internal/workers"
 
 	"github.com/gorilla/mux"
 )
@@ -29,21 +36,71 @@ func (app *Application) Run() error {
 	var router *mux.Router
 	var postgresDB *database.PostgresDB
 	var httpServer *server.Server
+	var serverErrors chan error
+	var shutdownContext context.Context
+	var cancelShutdown context.CancelFunc
+	var shutdownSteps []shutdownStep
+	var paymentProviders *providers.Registry
+	var riskRules *risk.Rules
 	// repositories
 	var transactionRepository *repositories.TransactionPostgresRepository
 	var userRepository *repositories.UserPostgresRepository
+	var idempotencyKeyRepository *repositories.IdempotencyKeyPostgresRepository
+	var refundRepository *repositories.RefundPostgresRepository
+	var webhookRepository *repositories.WebhookPostgresRepository
+	var apiKeyRepository *repositories.ApiKeyPostgresRepository
+	var fxRepository *repositories.FxPostgresRepository
+	var ledgerRepository *repositories.LedgerPostgresRepository
+	var walletRepository *repositories.WalletPostgresRepository
+	var statsRepository *repositories.StatsPostgresRepository
+	var riskRepository *repositories.RiskPostgresRepository
 	// services
 	var transactionService *services.TransactionService
 	var userService *services.UserService
+	var idempotencyService *services.IdempotencyService
+	var refundService *services.RefundService
+	var webhookService *services.WebhookService
+	var apiKeyService *services.ApiKeyService
+	var fxService *services.FxService
+	var ledgerService *services.LedgerService
+	var walletService *services.WalletService
+	var statsService *services.StatsService
+	var exportService *services.ExportService
 	// middlewares
+	var keySet *middleware.KeySet
+	var keySource middleware.KeySource
 	var authMiddleware *middleware.AuthMiddleware
+	var apiKeyMiddleware *middleware.ApiKeyMiddleware
+	var credentialsMiddleware *middleware.CredentialsMiddleware
+	var idempotencyMiddleware *middleware.IdempotencyMiddleware
+	var signatureMiddleware *middleware.SignatureMiddleware
+	var rateLimiter *middleware.MemoryLimiter
+	var rateLimitMiddleware *middleware.RateLimitMiddleware
 	// handlers
 	var userHandler *handlers.UserHandler
 	var transactionHandler *handlers.TransactionHandler
+	var refundHandler *handlers.RefundHandler
+	var webhookHandler *handlers.WebhookHandler
+	var apiKeyHandler *handlers.ApiKeyHandler
+	var fxHandler *handlers.FxHandler
+	var ledgerHandler *handlers.LedgerHandler
+	var walletHandler *handlers.WalletHandler
+	var statsHandler *handlers.StatsHandler
+	var exportHandler *handlers.ExportHandler
+	// background workers
+	var webhookDispatcher *workers.WebhookDispatcher
+	var fxRateLoader *workers.FxRateLoader
+	var transactionSweeper *workers.TransactionSweeper
+	// started workers, they are stopped in reverse order
+	var backgroundWorkers []*worker
 
 	// parse config (env variables)
 	cfg = config.GetConfig()
 
+	if cfg.JWTSignKey == "" && cfg.JWKSSource == "" {
+		return fmt.Errorf("JWT_SIGN_KEY or JWKS_SOURCE must be set")
+	}
+
 	// create postgres DB connection
 	postgresDB, err = database.NewPostgresDB(cfg)
 	if err != nil {
@@ -53,42 +110,239 @@ func (app *Application) Run() error {
 	// create repositories
 	transactionRepository = repositories.NewTransactionPostgresRepository(postgresDB)
 	userRepository = repositories.NewUserPostgresRepository(postgresDB)
+	idempotencyKeyRepository = repositories.NewIdempotencyKeyPostgresRepository(postgresDB)
+	refundRepository = repositories.NewRefundPostgresRepository(postgresDB)
+	webhookRepository = repositories.NewWebhookPostgresRepository(postgresDB)
+	apiKeyRepository = repositories.NewApiKeyPostgresRepository(postgresDB)
+	fxRepository = repositories.NewFxPostgresRepository(postgresDB)
+	ledgerRepository = repositories.NewLedgerPostgresRepository(postgresDB)
+	walletRepository = repositories.NewWalletPostgresRepository(postgresDB)
+	statsRepository = repositories.NewStatsPostgresRepository(postgresDB)
+	riskRepository = repositories.NewRiskPostgresRepository(postgresDB)
+
+	// create payment providers registry
+	paymentProviders, err = newPaymentProviders(cfg)
+	if err != nil {
+		postgresDB.Close()
+		return fmt.Errorf("newPaymentProviders failed: %w", err)
+	}
+
+	// load risk rules, every transaction is allowed if rules file is not configured
+	riskRules = risk.NewRules()
+	if cfg.RiskRulesSource != "" {
+		if riskRules, err = risk.LoadRules(cfg.RiskRulesSource); err != nil {
+			postgresDB.Close()
+			return fmt.Errorf("risk.LoadRules failed: %w", err)
+		}
+	}
 
 	// create services
-	transactionService = services.NewTransactionService(transactionRepository)
+	transactionService = services.NewTransactionService(
+		transactionRepository,
+		userRepository,
+		paymentProviders,
+		risk.NewEngine(riskRules, riskRepository),
+		cfg.TransactionTTL,
+	)
 	userService = services.NewUserService(userRepository)
+	idempotencyService = services.NewIdempotencyService(idempotencyKeyRepository, cfg.IdempotencyKeyTTL)
+	refundService = services.NewRefundService(refundRepository, transactionRepository, paymentProviders)
+	webhookService = services.NewWebhookService(webhookRepository)
+	apiKeyService = services.NewApiKeyService(apiKeyRepository)
+	fxService = services.NewFxService(fxRepository)
+	ledgerService = services.NewLedgerService(ledgerRepository)
+	walletService = services.NewWalletService(walletRepository)
+	statsService = services.NewStatsService(statsRepository)
+	exportService = services.NewExportService(transactionRepository)
+
+	// load public keys to verify asymmetrically signed tokens
+	if cfg.JWKSSource != "" {
+		keySet = middleware.NewKeySet(cfg.JWKSSource, &http.Client{Timeout: cfg.JWKSRequestTimeout})
+		if err = keySet.Refresh(context.Background()); err != nil {
+			postgresDB.Close()
+			return fmt.Errorf("keySet.Refresh failed: %w", err)
+		}
+		keySource = keySet
+	}
 
 	// create middleware
-	authMiddleware = middleware.NewAuthMiddleware(cfg.JWTSignKey)
+	authMiddleware = middleware.NewAuthMiddleware(cfg.JWTSignKey, keySource, cfg.JWTIssuer, cfg.JWTAudience)
+	apiKeyMiddleware = middleware.NewApiKeyMiddleware(apiKeyService)
+	// both bearer tokens and API keys are accepted
+	credentialsMiddleware = middleware.NewCredentialsMiddleware(authMiddleware, apiKeyMiddleware)
+	idempotencyMiddleware = middleware.NewIdempotencyMiddleware(idempotencyService)
+	signatureMiddleware = middleware.NewSignatureMiddleware(
+		middleware.StaticSigningSecrets(cfg.ProviderSigningSecrets), cfg.SignatureTolerance,
+	)
+
+	// token buckets are kept in memory, so limits are applied per replica
+	rateLimiter = middleware.NewMemoryLimiter()
+	rateLimitMiddleware = middleware.NewRateLimitMiddleware(rateLimiter, middleware.RateLimits{
+		Subject: middleware.Rate{Limit: cfg.RateLimitSubject, Period: cfg.RateLimitPeriod},
+		IP:      middleware.Rate{Limit: cfg.RateLimitIP, Period: cfg.RateLimitPeriod},
+		User:    middleware.Rate{Limit: cfg.RateLimitUser, Period: cfg.RateLimitPeriod},
+	})
 
 	// create handlers
-	transactionHandler = handlers.NewTransactionHandler(transactionService, authMiddleware)
-	userHandler = handlers.NewUserHandler(userService)
+	transactionHandler = handlers.NewTransactionHandler(
+		transactionService, credentialsMiddleware, idempotencyMiddleware, signatureMiddleware, rateLimitMiddleware,
+	)
+	userHandler = handlers.NewUserHandler(userService, credentialsMiddleware)
+	refundHandler = handlers.NewRefundHandler(refundService, credentialsMiddleware, signatureMiddleware)
+	webhookHandler = handlers.NewWebhookHandler(webhookService, credentialsMiddleware)
+	apiKeyHandler = handlers.NewApiKeyHandler(apiKeyService, credentialsMiddleware)
+	fxHandler = handlers.NewFxHandler(fxService, credentialsMiddleware)
+	ledgerHandler = handlers.NewLedgerHandler(ledgerService, credentialsMiddleware)
+	walletHandler = handlers.NewWalletHandler(walletService, credentialsMiddleware)
+	statsHandler = handlers.NewStatsHandler(statsService, credentialsMiddleware)
+	exportHandler = handlers.NewExportHandler(exportService, credentialsMiddleware)
 
 	router = mux.NewRouter().PathPrefix("/api").Subrouter()
 
 	// init routes
 	userHandler.InitRoutes(router)
 	transactionHandler.InitRoutes(router)
+	refundHandler.InitRoutes(router)
+	webhookHandler.InitRoutes(router)
+	apiKeyHandler.InitRoutes(router)
+	fxHandler.InitRoutes(router)
+	ledgerHandler.InitRoutes(router)
+	walletHandler.InitRoutes(router)
+	statsHandler.InitRoutes(router)
+	exportHandler.InitRoutes(router)
+
+	// create and starting background workers, workers used by request handlers are started first,
+	// so they are stopped after workers producing webhook events and provider calls
+	if keySet != nil {
+		backgroundWorkers = append(backgroundWorkers, startWorker("JWKS refresher", func(ctx context.Context) {
+			keySet.Run(ctx, cfg.JWKSRefreshInterval)
+		}))
+	}
+
+	if cfg.RateLimitPeriod > 0 {
+		backgroundWorkers = append(backgroundWorkers, startWorker("rate limiter", func(ctx context.Context) {
+			rateLimiter.Run(ctx, cfg.RateLimitPeriod)
+		}))
+	}
+
+	if cfg.FxRatesSource != "" {
+		fxRateLoader = workers.NewFxRateLoader(
+			fxRepository, fx.NewFileRateProvider(cfg.FxRatesSource), cfg.FxRatesRefreshInterval,
+		)
+		backgroundWorkers = append(backgroundWorkers, startWorker("FX rate loader", fxRateLoader.Run))
+	}
+
+	// webhook dispatcher is stopped after transaction sweeper to deliver events of the last sweep
+	webhookDispatcher = workers.NewWebhookDispatcher(
+		webhookRepository,
+		workers.NewWebhookClient(cfg.WebhookRequestTimeout),
+		cfg.WebhookDispatchInterval,
+		cfg.WebhookMaxAttempts,
+		cfg.WebhookBaseBackoff,
+		cfg.WebhookMaxBackoff,
+	)
+	backgroundWorkers = append(backgroundWorkers, startWorker("webhook dispatcher", webhookDispatcher.Run))
+
+	transactionSweeper = workers.NewTransactionSweeper(
+		transactionService, cfg.TransactionSweepInterval, cfg.TransactionSweepBatch,
+	)
+	backgroundWorkers = append(backgroundWorkers, startWorker("transaction sweeper", transactionSweeper.Run))
 
 	// create and starting server
 	httpServer = server.NewServer(cfg.ServicePort, router)
+	serverErrors = make(chan error, 1)
 
 	go func() {
-		if err := httpServer.Run(); err != nil {
-			log.Fatalf("error occured while running http server: %s", err.Error())
-		}
+		serverErrors <- httpServer.Run()
 	}()
 
-	// waiting for Ctrl + C to exit application
+	// waiting for Ctrl + C or SIGTERM (sent by docker and kubernetes) to exit application
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case sig := <-quit:
+		log.Printf("Received %s signal, shutting down...", sig)
+	case err = <-serverErrors:
+		// server is failed to start, but already started workers must be stopped anyway
+		err = fmt.Errorf("error occured while running http server: %w", err)
+	}
+
+	// in-flight requests are drained first, than workers and db connections are closed
+	shutdownSteps = append(shutdownSteps, shutdownStep{name: "http server", stop: httpServer.Shutdown})
+	for i := len(backgroundWorkers) - 1; i >= 0; i-- {
+		shutdownSteps = append(shutdownSteps, workerStep(backgroundWorkers[i]))
+	}
+	shutdownSteps = append(shutdownSteps, shutdownStep{name: "db", stop: func(ctx context.Context) error {
+		return postgresDB.Close()
+	}})
+
+	shutdownContext, cancelShutdown = context.WithTimeout(context.Background(), cfg.ShutdownDrainTimeout)
+	defer cancelShutdown()
 
-	log.Println("Disconnecting db...")
-	postgresDB.Close()
+	if shutdownErr := shutdown(shutdownContext, shutdownSteps); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
 
 	log.Println("Service is shutted down!")
 
-	return nil
+	return err
+}
+
+func newPaymentProviders(cfg *config.Config) (*providers.Registry, error) {
+	/*
+		Create registry of configured payment providers with routing rules.
+
+		In-memory fake provider loses payments on restart, so it is registered only when it is configured explicitly.
+	*/
+	var registered map[string]providers.PaymentProvider = make(map[string]providers.PaymentProvider)
+	var registry *providers.Registry
+
+	if usesPaymentProvider(cfg, "fake") {
+		registered["fake"] = providers.NewFakeProvider("fake")
+	}
+	if cfg.SandboxProviderURL != "" {
+		registered["sandbox"] = providers.NewHTTPProvider(
+			"sandbox", cfg.SandboxProviderURL, &http.Client{Timeout: cfg.PaymentProviderTimeout},
+		)
+	}
+
+	defaultProvider, ok := registered[cfg.PaymentProvider]
+	if !ok {
+		return nil, fmt.Errorf("payment provider %q is not configured", cfg.PaymentProvider)
+	}
+
+	registry = providers.NewRegistry(defaultProvider)
+	for _, provider := range registered {
+		registry.Register(provider)
+	}
+	for currency, name := range cfg.PaymentProviderCurrencies {
+		if err := registry.RouteCurrency(currency, name); err != nil {
+			return nil, err
+		}
+	}
+	for merchantId, name := range cfg.PaymentProviderMerchants {
+		if err := registry.RouteMerchant(merchantId, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+func usesPaymentProvider(cfg *config.Config, name string) bool {
+	/*Check provider with the name is default provider or it is used by any of routing rules.*/
+	if cfg.PaymentProvider == name {
+		return true
+	}
+	for _, routes := range []map[string]string{cfg.PaymentProviderCurrencies, cfg.PaymentProviderMerchants} {
+		for _, routed := range routes {
+			if routed == name {
+				return true
+			}
+		}
+	}
+
+	return false
 }
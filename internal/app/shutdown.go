@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Background worker running in it's own goroutine until it's context is canceled
+type worker struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func startWorker(name string, run func(ctx context.Context)) *worker {
+	/*Run worker function in new goroutine with it's own context, so workers can be stopped one by one.*/
+	ctx, cancel := context.WithCancel(context.Background())
+	var w *worker = &worker{name: name, cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(w.done)
+		run(ctx)
+	}()
+
+	return w
+}
+
+func (w *worker) Stop(ctx context.Context) error {
+	/*Cancel worker context and wait until worker returns or ctx is done.*/
+	w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Step of application shutdown sequence
+type shutdownStep struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+func workerStep(w *worker) shutdownStep {
+	/*Return shutdown step stopping the worker.*/
+	return shutdownStep{name: w.name, stop: w.Stop}
+}
+
+func shutdown(ctx context.Context, steps []shutdownStep) error {
+	/*
+		Run shutdown steps in given order sharing ctx deadline.
+		Failed step does not prevent next steps from running, the first error is returned.
+	*/
+	var firstErr error
+
+	for _, step := range steps {
+		log.Printf("Stopping %s...", step.name)
+
+		if err := step.stop(ctx); err != nil {
+			log.Printf("Stopping %s failed: %s", step.name, err.Error())
+			if firstErr == nil {
+				firstErr = fmt.Errorf("stopping %s failed: %w", step.name, err)
+			}
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdown(t *testing.T) {
+	// Arrange
+	var stopped []string
+	var someError error = errors.New("some error")
+	step := func(name string, err error) shutdownStep {
+		return shutdownStep{name: name, stop: func(ctx context.Context) error {
+			stopped = append(stopped, name)
+			return err
+		}}
+	}
+
+	// Act
+	err := shutdown(context.Background(), []shutdownStep{
+		step("http server", nil),
+		step("sweeper", someError),
+		step("dispatcher", errors.New("another error")),
+		step("db", nil),
+	})
+
+	// Assert
+	// steps are run in order even if some of them failed, the first error is returned
+	assert.Equal(t, []string{"http server", "sweeper", "dispatcher", "db"}, stopped)
+	assert.True(t, errors.Is(err, someError), err)
+}
+
+func TestWorker_Stop(t *testing.T) {
+	// Arrange
+	var stoppedAt time.Time
+	w := startWorker("test worker", func(ctx context.Context) {
+		<-ctx.Done()
+		// worker completes current iteration before return
+		time.Sleep(20 * time.Millisecond)
+		stoppedAt = time.Now()
+	})
+
+	// Act
+	err := w.Stop(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, stoppedAt.IsZero(), "Stop must wait for worker to return")
+}
+
+func TestWorker_StopTimeout(t *testing.T) {
+	// Arrange
+	var release chan struct{} = make(chan struct{})
+	defer close(release)
+
+	w := startWorker("stuck worker", func(ctx context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := w.Stop(ctx)
+
+	// Assert
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
+
+func TestShutdown_WorkersOrder(t *testing.T) {
+	// Arrange
+	var stopped chan string = make(chan string, 2)
+	run := func(name string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			<-ctx.Done()
+			stopped <- name
+		}
+	}
+	sweeper := startWorker("sweeper", run("sweeper"))
+	dispatcher := startWorker("dispatcher", run("dispatcher"))
+
+	// Act
+	err := shutdown(context.Background(), []shutdownStep{workerStep(sweeper), workerStep(dispatcher)})
+
+	// Assert
+	// next worker is canceled only after previous one returned
+	assert.NoError(t, err)
+	assert.Equal(t, "sweeper", <-stopped)
+	assert.Equal(t, "dispatcher", <-stopped)
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Scope which allows to access entities of any user
+	ScopeAdmin = "admin"
+	// Scope which allows to refund transactions of any merchant
+	ScopeRefunds = "refunds"
+
+	// Prefix of API key principal subject, so provider name can not be taken for user id
+	ProviderSubjectPrefix = "provider:"
+)
+
+// Authenticated client of the request, built from access token claims
+type Principal struct {
+	Subject    string
+	Email      string
+	MerchantId string
+	Scopes     []string
+}
+
+// unexported type of context key prevents collisions with keys of other packages
+type principalContextKey struct{}
+
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	/*Return copy of context which carries principal.*/
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	/*Retrieve principal from context, ok is false for unauthenticated requests.*/
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+
+	return principal, ok && principal != nil
+}
+
+func (principal *Principal) HasScope(scopes ...string) bool {
+	/*Check principal has at least one of the scopes.*/
+	for _, scope := range scopes {
+		for _, principalScope := range principal.Scopes {
+			if principalScope == scope {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (principal *Principal) IsAdmin() bool {
+	/*Check principal has access to entities of any user.*/
+	return principal.HasScope(ScopeAdmin)
+}
+
+func (principal *Principal) IsUser(userId int) bool {
+	/*Check principal subject is the user with passed id.*/
+	return principal.Subject == strconv.Itoa(userId)
+}
+
+func (principal *Principal) Provider() string {
+	/*Return provider name of principal: API key provider (subject without prefix) or token subject.*/
+	return strings.TrimPrefix(principal.Subject, ProviderSubjectPrefix)
+}
+
+func (principal *Principal) IsUserEmail(email string) bool {
+	/*Check principal email (from token claims) is the passed one.*/
+	return principal.Email != "" && strings.EqualFold(principal.Email, email)
+}
@@ -0,0 +1,144 @@
+package fx
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/money"
+)
+
+// Source name of rates loaded from file
+const FileSource = "file"
+
+// Layout of rate effective date
+const dateLayout = "2006-01-02"
+
+// Columns of CSV rates file header, columns order is not fixed
+var csvColumns = []string{"date", "base", "quote", "rate"}
+
+// Rate record of JSON rates file
+type fileRate struct {
+	Date  string      `json:"date"`
+	Base  string      `json:"base"`
+	Quote string      `json:"quote"`
+	Rate  json.Number `json:"rate"`
+}
+
+// FX rates provider which reads daily rates from local CSV or JSON file
+type FileRateProvider struct {
+	path string
+}
+
+func NewFileRateProvider(path string) *FileRateProvider {
+	/*File rates provider constructor function, file format is selected by ".csv" or ".json" extension.*/
+	return &FileRateProvider{path: path}
+}
+
+func (provider *FileRateProvider) Rates(ctx context.Context) ([]*models.FxRate, error) {
+	/*Read and validate all rates of the file.*/
+	var records []fileRate
+	var rates []*models.FxRate
+
+	data, err := os.ReadFile(provider.path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(provider.path)) {
+	case ".csv":
+		records, err = parseCSV(data)
+	case ".json":
+		err = json.Unmarshal(data, &records)
+	default:
+		return nil, fmt.Errorf("unsupported rates file format %q", filepath.Ext(provider.path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rates = make([]*models.FxRate, 0, len(records))
+	for i, record := range records {
+		rate, err := record.fxRate()
+		if err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+func parseCSV(data []byte) ([]fileRate, error) {
+	/*Parse CSV rates file, first line is a header with column names.*/
+	var records []fileRate
+	var columns map[string]int = map[string]int{}
+	var reader *csv.Reader = csv.NewReader(bytes.NewReader(data))
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("header: column %q is missing", name)
+		}
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, fileRate{
+			Date:  strings.TrimSpace(row[columns["date"]]),
+			Base:  strings.TrimSpace(row[columns["base"]]),
+			Quote: strings.TrimSpace(row[columns["quote"]]),
+			Rate:  json.Number(strings.TrimSpace(row[columns["rate"]])),
+		})
+	}
+}
+
+func (record fileRate) fxRate() (*models.FxRate, error) {
+	/*Validate rate record and convert it to rate entity.*/
+	date, err := time.Parse(dateLayout, record.Date)
+	if err != nil {
+		return nil, fmt.Errorf("date: %w", err)
+	}
+	if !money.IsCurrency(record.Base) {
+		return nil, fmt.Errorf("base: %w", money.ErrUnknownCurrency)
+	}
+	if !money.IsCurrency(record.Quote) {
+		return nil, fmt.Errorf("quote: %w", money.ErrUnknownCurrency)
+	}
+	if record.Base == record.Quote {
+		return nil, errors.New("base and quote currencies must differ")
+	}
+	if _, err := money.ParseRate(record.Rate.String()); err != nil {
+		return nil, fmt.Errorf("rate: %w", err)
+	}
+
+	return &models.FxRate{
+		BaseCurrency:  record.Base,
+		QuoteCurrency: record.Quote,
+		Rate:          record.Rate.String(),
+		EffectiveDate: date,
+		Source:        FileSource,
+	}, nil
+}
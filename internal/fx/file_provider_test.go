@@ -0,0 +1,108 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileRateProvider_Rates(t *testing.T) {
+	expectedRates := []*models.FxRate{
+		{
+			BaseCurrency:  "EUR",
+			QuoteCurrency: "USD",
+			Rate:          "1.0845",
+			EffectiveDate: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			Source:        FileSource,
+		},
+		{
+			BaseCurrency:  "USD",
+			QuoteCurrency: "JPY",
+			Rate:          "141.5",
+			EffectiveDate: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			Source:        FileSource,
+		},
+	}
+
+	testTable := []struct {
+		name          string
+		fileName      string
+		content       string
+		expectedRates []*models.FxRate
+		expectedErr   string
+	}{
+		{
+			name:          "Test CSV file",
+			fileName:      "rates.csv",
+			content:       "date,base,quote,rate\n2024-01-02,EUR,USD,1.0845\n2024-01-02, USD ,JPY,141.5\n",
+			expectedRates: expectedRates,
+		},
+		{
+			name:          "Test CSV file with another columns order",
+			fileName:      "rates.csv",
+			content:       "base,quote,rate,date\nEUR,USD,1.0845,2024-01-02\nUSD,JPY,141.5,2024-01-02\n",
+			expectedRates: expectedRates,
+		},
+		{
+			name:     "Test JSON file",
+			fileName: "rates.json",
+			content: `[
+				{"date": "2024-01-02", "base": "EUR", "quote": "USD", "rate": "1.0845"},
+				{"date": "2024-01-02", "base": "USD", "quote": "JPY", "rate": 141.5}
+			]`,
+			expectedRates: expectedRates,
+		},
+		{
+			name:        "Test CSV file without rate column",
+			fileName:    "rates.csv",
+			content:     "date,base,quote\n2024-01-02,EUR,USD\n",
+			expectedErr: `header: column "rate" is missing`,
+		},
+		{
+			name:        "Test file with unknown currency",
+			fileName:    "rates.csv",
+			content:     "date,base,quote,rate\n2024-01-02,EUR,ABC,1.0845\n",
+			expectedErr: "rate 1: quote: unknown currency",
+		},
+		{
+			name:        "Test file with negative rate",
+			fileName:    "rates.csv",
+			content:     "date,base,quote,rate\n2024-01-02,EUR,USD,1.0845\n2024-01-02,EUR,GBP,-0.86\n",
+			expectedErr: "rate 2: rate: rate must be a positive decimal number",
+		},
+		{
+			name:        "Test file with the same currencies",
+			fileName:    "rates.json",
+			content:     `[{"date": "2024-01-02", "base": "EUR", "quote": "EUR", "rate": "1"}]`,
+			expectedErr: "rate 1: base and quote currencies must differ",
+		},
+		{
+			name:        "Test file of unsupported format",
+			fileName:    "rates.xml",
+			content:     "<rates/>",
+			expectedErr: `unsupported rates file format ".xml"`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), testCase.fileName)
+			assert.NoError(t, os.WriteFile(path, []byte(testCase.content), 0o600))
+
+			rates, err := NewFileRateProvider(path).Rates(context.Background())
+
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedRates, rates)
+		})
+	}
+}
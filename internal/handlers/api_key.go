@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+
+	"github.com/gorilla/mux"
+)
+
+type ApiKeyService interface {
+	Create(apiKeyInput *models.ApiKeyInput) (*models.ApiKey, error)
+	List() ([]*models.ApiKey, error)
+	Revoke(apiKeyId int) error
+}
+
+type ApiKeyHandler struct {
+	service        ApiKeyService
+	authMiddleware AuthMiddleware
+}
+
+func NewApiKeyHandler(service ApiKeyService, authMiddleware AuthMiddleware) *ApiKeyHandler {
+	/*API key routes handler constructor function.*/
+	return &ApiKeyHandler{service: service, authMiddleware: authMiddleware}
+}
+
+func (handler *ApiKeyHandler) InitRoutes(router *mux.Router) {
+	/*Perform initialization of all required routes for API key entity.*/
+	var subRouter *mux.Router = router.PathPrefix("/api-keys").Subrouter()
+	// API keys are managed by admins only
+	var adminOnly func(http.HandlerFunc) http.HandlerFunc = handler.authMiddleware.RequireScope(auth.ScopeAdmin)
+
+	subRouter.HandleFunc("/", adminOnly(handler.CreateApiKey)).Methods("POST")
+	subRouter.HandleFunc("/", adminOnly(handler.ApiKeysList)).Methods("GET")
+	subRouter.HandleFunc("/{pk:[0-9]+}/", adminOnly(handler.RevokeApiKey)).Methods("DELETE")
+}
+
+func (handler *ApiKeyHandler) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to create new API key of provider.*/
+	var err error
+	var apiKey *models.ApiKey
+	var apiKeyInput models.ApiKeyInput
+
+	// parsing and validating request body data
+	if !parseRequestBody(w, r, &apiKeyInput) {
+		return
+	}
+
+	// create new API key with a service
+	apiKey, err = handler.service.Create(&apiKeyInput)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(apiKey)
+}
+
+func (handler *ApiKeyHandler) ApiKeysList(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to retrieve list of API keys.*/
+	apiKeys, err := handler.service.List()
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(apiKeys)
+}
+
+func (handler *ApiKeyHandler) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to revoke API key.
+
+		Accept API key PK in URL params.
+	*/
+	var err error
+	var apiKeyId int
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve API key PK from URL variables
+	apiKeyId, err = strconv.Atoi(params["pk"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	if err = handler.service.Revoke(apiKeyId); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
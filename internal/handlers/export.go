@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/money"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Supported export formats and their content types
+	exportFormatCSV         string = "csv"
+	exportFormatNDJSON      string = "ndjson"
+	exportContentTypeCSV    string = "text/csv; charset=utf-8"
+	exportContentTypeNDJSON string = "application/x-ndjson"
+)
+
+// Number of rows written to the response before it is flushed to the client
+const exportFlushRows int = 100
+
+// Header row of transactions CSV export
+var exportCSVHeader = []string{
+	"id", "user_id", "user_email", "amount", "amount_formatted", "currency",
+	"status", "processed_at", "created_at", "updated_at", "provider", "provider_reference",
+	"expires_at",
+}
+
+type ExportService interface {
+	Transactions(ctx context.Context, filter *models.TransactionSearchFilter, fn func(*models.Transaction) error) error
+}
+
+type ExportHandler struct {
+	service        ExportService
+	authMiddleware AuthMiddleware
+}
+
+func NewExportHandler(service ExportService, authMiddleware AuthMiddleware) *ExportHandler {
+	/*Export routes handler constructor function.*/
+	return &ExportHandler{service: service, authMiddleware: authMiddleware}
+}
+
+func (handler *ExportHandler) InitRoutes(router *mux.Router) {
+	/*Perform initialization of all required routes for transactions export.*/
+	var transactionsRouter *mux.Router = router.PathPrefix("/transactions").Subrouter()
+	var usersRouter *mux.Router = router.PathPrefix("/users").Subrouter()
+
+	// export of all transactions is available to admins (finance team) only
+	transactionsRouter.HandleFunc(
+		"/export/", handler.authMiddleware.RequireScope(auth.ScopeAdmin)(handler.ExportTransactions),
+	).Methods("GET")
+	usersRouter.HandleFunc(
+		"/{userId:[0-9]+}/transactions/export/", handler.authMiddleware.AuthMiddleware(handler.ExportUserTransactions),
+	).Methods("GET")
+}
+
+func (handler *ExportHandler) ExportTransactions(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to export all transactions, accept search filter and sorting params in URL query.*/
+	handler.writeExport(w, r, nil)
+}
+
+func (handler *ExportHandler) ExportUserTransactions(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to export user's transactions.
+
+		Accept user PK in URL params and search filter and sorting params in URL query.
+	*/
+	var err error
+	var userId int
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve user PK from url variables
+	userId, err = strconv.Atoi(params["userId"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// only user itself and admin are allowed to export user's transactions
+	if err = services.AuthorizeUser(requestPrincipal(r), userId); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	handler.writeExport(w, r, &userId)
+}
+
+func (handler *ExportHandler) writeExport(w http.ResponseWriter, r *http.Request, userId *int) {
+	/*
+		Parse export params and stream transactions (of the user if passed) in requested format.
+
+		Response is started on the first row, so errors occurred before it are written as problem,
+		later errors can only abort the response.
+	*/
+	var err error
+	var format string
+	var filter *models.TransactionSearchFilter
+	var writer transactionWriter
+	var started bool
+	var rows int
+
+	format, err = exportFormat(r)
+	if err == nil {
+		filter, err = parseTransactionSearchFilter(r.URL.Query())
+	}
+	if err == nil {
+		err = newValidator().Struct(filter)
+	}
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+	if userId != nil {
+		filter.UserId = userId
+	}
+
+	start := func() {
+		started = true
+		writer = newTransactionWriter(w, format)
+	}
+
+	err = handler.service.Transactions(r.Context(), filter, func(transaction *models.Transaction) error {
+		if !started {
+			start()
+		}
+		if err := writer.Write(transaction); err != nil {
+			return err
+		}
+
+		// send rows to the client by portions instead of buffering the whole export
+		if rows++; rows%exportFlushRows == 0 {
+			return writer.Flush()
+		}
+		return nil
+	})
+
+	switch {
+	case err != nil && !started:
+		problem.WriteError(w, r, err)
+	case err != nil:
+		// client gone away or db failed in the middle of the export, response is already started
+		log.Printf("%s %s failed: %s", r.Method, r.URL.Path, err.Error())
+	default:
+		if !started {
+			start()
+		}
+		writer.Flush()
+	}
+}
+
+func exportFormat(r *http.Request) (string, error) {
+	/*Return export format requested by "format" URL query param or Accept header, CSV is used by default.*/
+	if format := r.URL.Query().Get("format"); format != "" {
+		if format != exportFormatCSV && format != exportFormatNDJSON {
+			return "", newParamError("format", "oneof", "must be one of: csv ndjson")
+		}
+		return format, nil
+	}
+
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "text/csv":
+			return exportFormatCSV, nil
+		case "application/x-ndjson", "application/ndjson":
+			return exportFormatNDJSON, nil
+		}
+	}
+
+	return exportFormatCSV, nil
+}
+
+// Writer of exported transactions in one of supported formats
+type transactionWriter interface {
+	Write(transaction *models.Transaction) error
+	Flush() error
+}
+
+func newTransactionWriter(w http.ResponseWriter, format string) transactionWriter {
+	/*Write export response headers and return writer of transactions in the format.*/
+	var writer transactionWriter
+	var contentType string = exportContentTypeCSV
+
+	if format == exportFormatNDJSON {
+		contentType = exportContentTypeNDJSON
+		writer = &ndjsonTransactionWriter{response: w, encoder: json.NewEncoder(w)}
+	} else {
+		writer = newCSVTransactionWriter(w)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="transactions.`+format+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	return writer
+}
+
+type csvTransactionWriter struct {
+	response http.ResponseWriter
+	writer   *csv.Writer
+}
+
+func newCSVTransactionWriter(w http.ResponseWriter) *csvTransactionWriter {
+	/*CSV transactions writer constructor function, header row is written at once (even for empty export).*/
+	var writer *csv.Writer = csv.NewWriter(w)
+
+	// write errors are kept by csv writer and returned on flush
+	writer.Write(exportCSVHeader)
+
+	return &csvTransactionWriter{response: w, writer: writer}
+}
+
+func (writer *csvTransactionWriter) Write(transaction *models.Transaction) error {
+	/*Write transaction as CSV row, amounts are written in minor units and formatted in major units.*/
+	var processedAt, expiresAt, provider, providerReference string
+
+	if transaction.ProcessedAt != nil {
+		processedAt = transaction.ProcessedAt.Format(time.RFC3339Nano)
+	}
+	if transaction.ExpiresAt != nil {
+		expiresAt = transaction.ExpiresAt.Format(time.RFC3339Nano)
+	}
+	if transaction.Provider != nil {
+		provider = *transaction.Provider
+	}
+	if transaction.ProviderReference != nil {
+		providerReference = csvSafe(*transaction.ProviderReference)
+	}
+
+	return writer.writer.Write([]string{
+		strconv.Itoa(transaction.Id),
+		strconv.Itoa(transaction.UserId),
+		csvSafe(transaction.UserEmail),
+		strconv.FormatInt(transaction.Amount, 10),
+		money.Format(transaction.Amount, transaction.Currency),
+		transaction.Currency,
+		transaction.Status,
+		processedAt,
+		transaction.CreatedAt.Format(time.RFC3339Nano),
+		transaction.UpdatedAt.Format(time.RFC3339Nano),
+		provider,
+		providerReference,
+		expiresAt,
+	})
+}
+
+func (writer *csvTransactionWriter) Flush() error {
+	/*Send buffered rows to the client.*/
+	writer.writer.Flush()
+	if err := writer.writer.Error(); err != nil {
+		return err
+	}
+
+	return flushResponse(writer.response)
+}
+
+type ndjsonTransactionWriter struct {
+	response http.ResponseWriter
+	encoder  *json.Encoder
+}
+
+func (writer *ndjsonTransactionWriter) Write(transaction *models.Transaction) error {
+	/*Write transaction as JSON line, in the same representation as API returns.*/
+	return writer.encoder.Encode(transaction)
+}
+
+func (writer *ndjsonTransactionWriter) Flush() error {
+	/*Send written rows to the client.*/
+	return flushResponse(writer.response)
+}
+
+func flushResponse(w http.ResponseWriter) error {
+	/*Send buffered response data to the client if response supports flushing.*/
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+func csvSafe(value string) string {
+	/*Escape value which spreadsheet applications would evaluate as formula.*/
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+	mock_services "github.com/Pythonyan3/payment-service/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ExportTransactions(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockExportService)
+	userId := 1
+	createdAt := time.Date(2022, 6, 12, 18, 9, 14, 0, time.UTC)
+	processedAt := createdAt.Add(2 * time.Minute)
+	exported := []*models.Transaction{
+		{Id: 1, UserId: 1, UserEmail: "email@mail.ru", Amount: 1500, Currency: "EUR",
+			Status: services.TransactionNewStatus, CreatedAt: createdAt, UpdatedAt: createdAt},
+		{Id: 2, UserId: 1, UserEmail: "=cmd@mail.ru", Amount: 100, Currency: "JPY", Status: services.TransactionSuccessStatus,
+			ProcessedAt: &processedAt, CreatedAt: createdAt, UpdatedAt: processedAt},
+	}
+	exportAll := func(ctx context.Context, filter *models.TransactionSearchFilter, fn func(*models.Transaction) error) error {
+		for _, transaction := range exported {
+			if err := fn(transaction); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	defaultFilter := &models.TransactionSearchFilter{Sort: "created_at", Order: "desc", Limit: defaultPageLimit}
+	csvHeader := "id,user_id,user_email,amount,amount_formatted,currency,status,processed_at,created_at,updated_at,provider,provider_reference,expires_at\n"
+	csvBody := csvHeader +
+		"1,1,email@mail.ru,1500,15.00,EUR,NEW,,2022-06-12T18:09:14Z,2022-06-12T18:09:14Z,,,\n" +
+		"2,1,'=cmd@mail.ru,100,100,JPY,SUCCESS,2022-06-12T18:11:14Z,2022-06-12T18:09:14Z,2022-06-12T18:11:14Z,,,\n"
+	ndjsonBody := `{"id":1,"user_id":1,"user_email":"email@mail.ru","amount":1500,"currency":"EUR",` +
+		`"created_at":"2022-06-12T18:09:14Z","updated_at":"2022-06-12T18:09:14Z","status":"NEW",` +
+		`"processed_at":null,"expires_at":null,"provider":null,"provider_reference":null,"amount_formatted":"15.00"}` + "\n" +
+		`{"id":2,"user_id":1,"user_email":"=cmd@mail.ru","amount":100,"currency":"JPY",` +
+		`"created_at":"2022-06-12T18:09:14Z","updated_at":"2022-06-12T18:11:14Z","status":"SUCCESS",` +
+		`"processed_at":"2022-06-12T18:11:14Z","expires_at":null,"provider":null,"provider_reference":null,"amount_formatted":"100"}` + "\n"
+
+	testTable := []struct {
+		name                string
+		url                 string
+		accept              string
+		principal           *auth.Principal
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedContentType string
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test export all transactions (csv by default)",
+			url:                 "/api/transactions/export/",
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedRequestBody: csvBody,
+			mockBehaviour: func(service *mock_services.MockExportService) {
+				service.EXPECT().Transactions(gomock.Any(), defaultFilter, gomock.Any()).DoAndReturn(exportAll)
+			},
+		},
+		{
+			name:                "Test export all transactions (ndjson by accept header)",
+			url:                 "/api/transactions/export/",
+			accept:              "application/x-ndjson",
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedRequestBody: ndjsonBody,
+			mockBehaviour: func(service *mock_services.MockExportService) {
+				service.EXPECT().Transactions(gomock.Any(), defaultFilter, gomock.Any()).DoAndReturn(exportAll)
+			},
+		},
+		{
+			name:                "Test export user transactions (format param overrides accept header)",
+			url:                 "/api/users/1/transactions/export/?format=csv&status=SUCCESS&sort=amount&order=asc",
+			accept:              "application/x-ndjson",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedRequestBody: csvBody,
+			mockBehaviour: func(service *mock_services.MockExportService) {
+				service.EXPECT().Transactions(gomock.Any(), &models.TransactionSearchFilter{
+					Statuses: []string{"SUCCESS"},
+					UserId:   &userId,
+					Sort:     "amount",
+					Order:    "asc",
+					Limit:    defaultPageLimit,
+				}, gomock.Any()).DoAndReturn(exportAll)
+			},
+		},
+		{
+			name:                "Test export user transactions (empty)",
+			url:                 "/api/users/1/transactions/export/",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedRequestBody: csvHeader,
+			mockBehaviour: func(service *mock_services.MockExportService) {
+				service.EXPECT().Transactions(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:                "Test export user transactions (another user)",
+			url:                 "/api/users/1/transactions/export/",
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedContentType: "application/problem+json",
+			expectedRequestBody: errorBody(services.ErrUserAccessDenied, "/api/users/1/transactions/export/"),
+			mockBehaviour:       func(service *mock_services.MockExportService) {},
+		},
+		{
+			name:                "Test export all transactions (unknown format)",
+			url:                 "/api/transactions/export/?format=xlsx",
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/problem+json",
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "format", Tag: "oneof", Message: "must be one of: csv ndjson"},
+			}}, "/api/transactions/export/"),
+			mockBehaviour: func(service *mock_services.MockExportService) {},
+		},
+		{
+			name:                "Test export all transactions (failed before first row)",
+			url:                 "/api/transactions/export/",
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusInternalServerError,
+			expectedContentType: "application/problem+json",
+			expectedRequestBody: errorBody(errors.New("some error"), "/api/transactions/export/"),
+			mockBehaviour: func(service *mock_services.MockExportService) {
+				service.EXPECT().Transactions(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+			},
+		},
+		{
+			name:                "Test export all transactions (failed in the middle)",
+			url:                 "/api/transactions/export/?format=ndjson",
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedRequestBody: ndjsonBody[:strings.Index(ndjsonBody, "\n")+1],
+			mockBehaviour: func(service *mock_services.MockExportService) {
+				service.EXPECT().Transactions(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, filter *models.TransactionSearchFilter, fn func(*models.Transaction) error) error {
+						fn(exported[0])
+						return errors.New("some error")
+					},
+				)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockExportService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			testCase.mockBehaviour(service)
+
+			handler := NewExportHandler(service, auth_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/transactions/export/", handler.ExportTransactions)
+			router.HandleFunc("/api/users/{userId:[0-9]+}/transactions/export/", handler.ExportUserTransactions)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", testCase.url, bytes.NewBufferString(""))
+			r.Header.Set("Accept", testCase.accept)
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(testCase.principal)))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
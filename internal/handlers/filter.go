@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+)
+
+const defaultPageLimit = 50
+
+func parseTransactionFilter(query url.Values) (*models.TransactionFilter, error) {
+	/*Parse transactions list filter and pagination params from URL query.*/
+	var err error
+	var filter *models.TransactionFilter = &models.TransactionFilter{
+		Status:   query.Get("status"),
+		Currency: query.Get("currency"),
+		Cursor:   query.Get("cursor"),
+		Limit:    defaultPageLimit,
+	}
+
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			return nil, newParamError("limit", "integer", "must be an integer")
+		}
+	}
+	if filter.AmountMin, err = parseInt64Param(query, "amount_min"); err != nil {
+		return nil, err
+	}
+	if filter.AmountMax, err = parseInt64Param(query, "amount_max"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedFrom, err = parseTimeParam(query, "created_from"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedTo, err = parseTimeParam(query, "created_to"); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+func parseTransactionSearchFilter(query url.Values) (*models.TransactionSearchFilter, error) {
+	/*
+		Parse transactions search params from URL query.
+
+		Statuses are passed as repeated or comma separated "status" param, transactions are sorted
+		from newest to oldest by default.
+	*/
+	var err error
+	var filter *models.TransactionSearchFilter = &models.TransactionSearchFilter{
+		Currency: query.Get("currency"),
+		Email:    query.Get("email"),
+		Sort:     "created_at",
+		Order:    "desc",
+		Cursor:   query.Get("cursor"),
+		Limit:    defaultPageLimit,
+	}
+
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status != "" {
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+	}
+	if value := query.Get("sort"); value != "" {
+		filter.Sort = value
+	}
+	if value := query.Get("order"); value != "" {
+		filter.Order = value
+	}
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			return nil, newParamError("limit", "integer", "must be an integer")
+		}
+	}
+	if value := query.Get("user_id"); value != "" {
+		userId, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, newParamError("user_id", "integer", "must be an integer")
+		}
+		filter.UserId = &userId
+	}
+	if filter.AmountMin, err = parseInt64Param(query, "amount_min"); err != nil {
+		return nil, err
+	}
+	if filter.AmountMax, err = parseInt64Param(query, "amount_max"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedFrom, err = parseTimeParam(query, "created_from"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedTo, err = parseTimeParam(query, "created_to"); err != nil {
+		return nil, err
+	}
+	if filter.UpdatedFrom, err = parseTimeParam(query, "updated_from"); err != nil {
+		return nil, err
+	}
+	if filter.UpdatedTo, err = parseTimeParam(query, "updated_to"); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+func parseTransactionStatsFilter(query url.Values) (*models.TransactionStatsFilter, error) {
+	/*Parse transactions statistics window params from URL query, transactions are bucketed by day by default.*/
+	var filter *models.TransactionStatsFilter = &models.TransactionStatsFilter{Interval: "day"}
+
+	if value := query.Get("interval"); value != "" {
+		filter.Interval = value
+	}
+	for name, field := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value, err := parseTimeParam(query, name)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			*field = *value
+		}
+	}
+
+	return filter, nil
+}
+
+func parseLedgerEntryFilter(query url.Values) (*models.LedgerEntryFilter, error) {
+	/*Parse ledger entries pagination params from URL query.*/
+	var err error
+	var filter *models.LedgerEntryFilter = &models.LedgerEntryFilter{
+		Cursor: query.Get("cursor"),
+		Limit:  defaultPageLimit,
+	}
+
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			return nil, newParamError("limit", "integer", "must be an integer")
+		}
+	}
+
+	return filter, nil
+}
+
+func parseInt64Param(query url.Values, name string) (*int64, error) {
+	/*Parse optional integer URL query param.*/
+	var value string = query.Get(name)
+
+	if value == "" {
+		return nil, nil
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, newParamError(name, "integer", "must be an integer")
+	}
+
+	return &number, nil
+}
+
+func parseTimeParam(query url.Values, name string) (*time.Time, error) {
+	/*Parse optional RFC 3339 datetime URL query param.*/
+	var value string = query.Get(name)
+
+	if value == "" {
+		return nil, nil
+	}
+
+	datetime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, newParamError(name, "datetime", "must be a datetime in RFC 3339 format")
+	}
+
+	return &datetime, nil
+}
+
+func newParamError(name string, tag string, message string) error {
+	/*Build validation error of URL query param which can not be parsed.*/
+	return &services.ValidationError{
+		Fields: []services.FieldError{{Field: name, Tag: tag, Message: message}},
+	}
+}
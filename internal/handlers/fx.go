@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/money"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+type FxService interface {
+	ConvertUserTransactions(userId int, currency string, filter *models.TransactionFilter) (*models.ConvertedTransactionsReport, error)
+}
+
+type FxHandler struct {
+	service        FxService
+	authMiddleware AuthMiddleware
+}
+
+func NewFxHandler(service FxService, authMiddleware AuthMiddleware) *FxHandler {
+	/*FX routes handler constructor function.*/
+	return &FxHandler{service: service, authMiddleware: authMiddleware}
+}
+
+func (handler *FxHandler) InitRoutes(router *mux.Router) {
+	/*Perform initialization of all required routes for currency conversion reports.*/
+	var subRouter *mux.Router = router.PathPrefix("/users").Subrouter()
+	subRouter.HandleFunc(
+		"/{userId:[0-9]+}/transactions/converted/",
+		handler.authMiddleware.AuthMiddleware(handler.ConvertedTransactionsByUserId),
+	).Methods("GET")
+}
+
+func (handler *FxHandler) ConvertedTransactionsByUserId(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to retrieve page of user's transactions converted to the currency.
+
+		Accept user PK in URL params, report currency in "to" URL query param
+		and filter params in URL query to perform filtering.
+	*/
+	// declare variables
+	var userId int
+	var err error
+	var currency string = r.URL.Query().Get("to")
+	var filter *models.TransactionFilter
+	var report *models.ConvertedTransactionsReport
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve user PK from url variables
+	userId, err = strconv.Atoi(params["userId"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// only user itself and admin are allowed to see user's transactions
+	if err = services.AuthorizeUser(requestPrincipal(r), userId); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	if !money.IsCurrency(currency) {
+		problem.WriteError(w, r, newParamError("to", "currency", "must be a supported ISO 4217 currency code"))
+		return
+	}
+
+	// parse and validate filter params
+	filter, err = parseTransactionFilter(r.URL.Query())
+	if err == nil {
+		err = newValidator().Struct(filter)
+	}
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// use service to convert user's transactions
+	report, err = handler.service.ConvertUserTransactions(userId, currency, filter)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
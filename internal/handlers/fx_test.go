@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+	mock_services "github.com/Pythonyan3/payment-service/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ConvertedTransactionsByUserId(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockFxService, userId int)
+	rateId := 1
+	report := &models.ConvertedTransactionsReport{
+		Currency: "USD",
+		Items: []*models.ConvertedTransaction{{
+			Transaction:              transaction,
+			ConvertedAmount:          1650,
+			ConvertedAmountFormatted: "16.50",
+			Rate:                     "1.1",
+			RateId:                   &rateId,
+			RateDate:                 "2022-06-12",
+		}},
+		Total: models.ConvertedTotal{Count: 1, Amount: 1650, AmountFormatted: "16.50"},
+		Limit: defaultPageLimit,
+	}
+	serializedReport, _ := json.Marshal(report)
+	rateNotFoundErr := services.NewError("fx_rate_not_found", "FX rate is not found.", services.ErrConflict)
+
+	testTable := []struct {
+		name                string
+		userId              int
+		query               string
+		principal           *auth.Principal
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test converted transactions (ok)",
+			userId:              transaction.UserId,
+			query:               "?to=USD",
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedReport) + "\n",
+			mockBehaviour: func(service *mock_services.MockFxService, userId int) {
+				service.EXPECT().ConvertUserTransactions(userId, "USD", defaultFilter).Return(report, nil)
+			},
+		},
+		{
+			name:               "Test converted transactions (no currency)",
+			userId:             transaction.UserId,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "to", Tag: "currency", Message: "must be a supported ISO 4217 currency code"},
+			}}, "/api/users/1/transactions/converted/"),
+			mockBehaviour: func(service *mock_services.MockFxService, userId int) {},
+		},
+		{
+			name:                "Test converted transactions (another user)",
+			userId:              transaction.UserId,
+			query:               "?to=USD",
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrUserAccessDenied, "/api/users/1/transactions/converted/"),
+			mockBehaviour:       func(service *mock_services.MockFxService, userId int) {},
+		},
+		{
+			name:                "Test converted transactions (rate not found)",
+			userId:              transaction.UserId,
+			query:               "?to=USD",
+			expectedStatusCode:  http.StatusConflict,
+			expectedRequestBody: errorBody(rateNotFoundErr, "/api/users/1/transactions/converted/"),
+			mockBehaviour: func(service *mock_services.MockFxService, userId int) {
+				service.EXPECT().ConvertUserTransactions(userId, "USD", defaultFilter).Return(nil, rateNotFoundErr)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockFxService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			testCase.mockBehaviour(service, testCase.userId)
+
+			handler := NewFxHandler(service, auth_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/users/{userId:[0-9]+}/transactions/converted/", handler.ConvertedTransactionsByUserId)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(
+				"GET",
+				fmt.Sprintf("/api/users/%d/transactions/converted/%s", testCase.userId, testCase.query),
+				bytes.NewBufferString(""),
+			)
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(testCase.principal)))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
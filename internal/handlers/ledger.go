@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+
+	"github.com/gorilla/mux"
+)
+
+type LedgerService interface {
+	ListAccounts() ([]*models.LedgerAccountBalance, error)
+	GetAccount(accountId int) (*models.LedgerAccountBalance, error)
+	GetAccountEntries(accountId int, filter *models.LedgerEntryFilter) (*models.LedgerEntryPage, error)
+}
+
+type LedgerHandler struct {
+	service        LedgerService
+	authMiddleware AuthMiddleware
+}
+
+func NewLedgerHandler(service LedgerService, authMiddleware AuthMiddleware) *LedgerHandler {
+	/*Ledger routes handler constructor function.*/
+	return &LedgerHandler{service: service, authMiddleware: authMiddleware}
+}
+
+func (handler *LedgerHandler) InitRoutes(router *mux.Router) {
+	/*Perform initialization of all required routes for ledger accounts.*/
+	var subRouter *mux.Router = router.PathPrefix("/ledger").Subrouter()
+	// ledger is available to admins only
+	var adminOnly func(http.HandlerFunc) http.HandlerFunc = handler.authMiddleware.RequireScope(auth.ScopeAdmin)
+
+	subRouter.HandleFunc("/accounts/", adminOnly(handler.AccountsList)).Methods("GET")
+	subRouter.HandleFunc("/accounts/{pk:[0-9]+}/", adminOnly(handler.RetrieveAccount)).Methods("GET")
+	subRouter.HandleFunc("/accounts/{pk:[0-9]+}/entries/", adminOnly(handler.AccountEntriesList)).Methods("GET")
+}
+
+func (handler *LedgerHandler) AccountsList(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to retrieve list of ledger accounts with their balances.*/
+	accounts, err := handler.service.ListAccounts()
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(accounts)
+}
+
+func (handler *LedgerHandler) RetrieveAccount(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to retrieve ledger account balance.
+
+		Accept account PK in URL params.
+	*/
+	var err error
+	var accountId int
+	var account *models.LedgerAccountBalance
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve account PK from URL variables
+	accountId, err = strconv.Atoi(params["pk"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	account, err = handler.service.GetAccount(accountId)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(account)
+}
+
+func (handler *LedgerHandler) AccountEntriesList(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to retrieve page of journal entries with postings to the account.
+
+		Accept account PK in URL params and pagination params in URL query.
+	*/
+	var err error
+	var accountId int
+	var filter *models.LedgerEntryFilter
+	var page *models.LedgerEntryPage
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve account PK from URL variables
+	accountId, err = strconv.Atoi(params["pk"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// parse and validate pagination params
+	filter, err = parseLedgerEntryFilter(r.URL.Query())
+	if err == nil {
+		err = newValidator().Struct(filter)
+	}
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	page, err = handler.service.GetAccountEntries(accountId, filter)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(page)
+}
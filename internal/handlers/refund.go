@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+type RefundService interface {
+	Create(transactionId int, refundInput *models.RefundInput) (*models.Refund, error)
+	UpdateStatus(transactionId int, refundId int, status string, role string) (*models.Refund, error)
+	GetById(transactionId int, refundId int) (*models.Refund, error)
+	ListByTransaction(transactionId int) ([]*models.Refund, error)
+	GetTransaction(transactionId int) (*models.Transaction, error)
+}
+
+type RefundHandler struct {
+	service             RefundService
+	authMiddleware      AuthMiddleware
+	signatureMiddleware SignatureMiddleware
+}
+
+func NewRefundHandler(
+	service RefundService, authMiddleware AuthMiddleware, signatureMiddleware SignatureMiddleware,
+) *RefundHandler {
+	/*Refund routes handler constructor function.*/
+	return &RefundHandler{service: service, authMiddleware: authMiddleware, signatureMiddleware: signatureMiddleware}
+}
+
+func (handler *RefundHandler) InitRoutes(router *mux.Router) {
+	/*Perform initialization of all required routes for refund entity.*/
+	var subRouter *mux.Router = router.PathPrefix("/transactions/{pk:[0-9]+}/refunds").Subrouter()
+
+	// refunds are created by transaction merchant or admin and read by transaction owner or admin
+	subRouter.HandleFunc("/", handler.authMiddleware.AuthMiddleware(handler.CreateRefund)).Methods("POST")
+	subRouter.HandleFunc("/", handler.authMiddleware.AuthMiddleware(handler.RefundsList)).Methods("GET")
+	subRouter.HandleFunc(
+		"/{refundPk:[0-9]+}/",
+		handler.authMiddleware.AuthMiddleware(handler.RetrieveRefund),
+	).Methods("GET")
+	subRouter.HandleFunc(
+		"/{refundPk:[0-9]+}/proceed/",
+		handler.authMiddleware.RequireScope(services.RoleProvider)(
+			handler.signatureMiddleware.SignatureMiddleware(handler.ProceedRefund),
+		),
+	).Methods("PUT", "PATCH")
+}
+
+func (handler *RefundHandler) CreateRefund(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to create new refund of transaction.
+
+		Accept transaction PK in URL params, only transaction merchant or admin can refund it.
+	*/
+	var err error
+	var transactionId int
+	var refund *models.Refund
+	var refundInput models.RefundInput
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve transaction PK from URL variables
+	transactionId, err = strconv.Atoi(params["pk"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// parsing and validating request body data
+	if !parseRequestBody(w, r, &refundInput) {
+		return
+	}
+
+	// check principal is allowed to refund transaction and create new refund with a service
+	err = handler.authorizeRefund(r, transactionId)
+	if err == nil {
+		refund, err = handler.service.Create(transactionId, &refundInput)
+	}
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(refund)
+}
+
+func (handler *RefundHandler) RefundsList(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to retrieve list of transaction refunds.
+
+		Accept transaction PK in URL params, only transaction owner or admin can access refunds.
+	*/
+	var err error
+	var transactionId int
+	var refunds []*models.Refund
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve transaction PK from URL variables
+	transactionId, err = strconv.Atoi(params["pk"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// check access to transaction and use service to retrieve transaction refunds
+	err = handler.authorizeTransaction(r, transactionId)
+	if err == nil {
+		refunds, err = handler.service.ListByTransaction(transactionId)
+	}
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(refunds)
+}
+
+func (handler *RefundHandler) RetrieveRefund(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to retrieve refund info.
+
+		Accept transaction PK and refund PK in URL params, only transaction owner or admin can access refund.
+	*/
+	var err error
+	var transactionId int
+	var refundId int
+	var refund *models.Refund
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve transaction PK and refund PK from URL variables
+	transactionId, err = strconv.Atoi(params["pk"])
+	if err == nil {
+		refundId, err = strconv.Atoi(params["refundPk"])
+	}
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// check access to transaction and retrieve refund info with service
+	err = handler.authorizeTransaction(r, transactionId)
+	if err == nil {
+		refund, err = handler.service.GetById(transactionId, refundId)
+	}
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(refund)
+}
+
+func (handler *RefundHandler) ProceedRefund(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to update refund status by payment service.*/
+	var err error
+	var transactionId int
+	var refundId int
+	var refund *models.Refund
+	var refundStatusInput models.RefundStatusInput
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve transaction PK and refund PK from URL variables
+	transactionId, err = strconv.Atoi(params["pk"])
+	if err == nil {
+		refundId, err = strconv.Atoi(params["refundPk"])
+	}
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// parsing and validating request body data
+	if !parseRequestBody(w, r, &refundStatusInput) {
+		return
+	}
+
+	// update refund status with a service struct
+	refund, err = handler.service.UpdateStatus(transactionId, refundId, refundStatusInput.Status, services.RoleProvider)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(refund)
+}
+
+func (handler *RefundHandler) authorizeTransaction(r *http.Request, transactionId int) error {
+	/*Check principal of the request is allowed to access transaction refunds: only owner and admin are.*/
+	transaction, err := handler.service.GetTransaction(transactionId)
+	if err != nil {
+		return err
+	}
+
+	return services.AuthorizeTransaction(requestPrincipal(r), transaction)
+}
+
+func (handler *RefundHandler) authorizeRefund(r *http.Request, transactionId int) error {
+	/*Check principal of the request is allowed to create transaction refunds: only merchant and admin are.*/
+	transaction, err := handler.service.GetTransaction(transactionId)
+	if err != nil {
+		return err
+	}
+
+	return services.AuthorizeRefund(requestPrincipal(r), transaction)
+}
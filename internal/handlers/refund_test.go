@@ -0,0 +1,470 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+	mock_services "github.com/Pythonyan3/payment-service/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	refund *models.Refund = &models.Refund{
+		Id:            1,
+		TransactionId: transaction.Id,
+		Amount:        500,
+		Status:        services.RefundPendingStatus,
+		CreatedAt:     currentTime,
+		UpdatedAt:     currentTime,
+	}
+	successRefund *models.Refund = &models.Refund{
+		Id:            refund.Id,
+		TransactionId: refund.TransactionId,
+		Amount:        refund.Amount,
+		Status:        services.RefundSuccessStatus,
+		CreatedAt:     refund.CreatedAt,
+		UpdatedAt:     refund.UpdatedAt,
+	}
+	inputRefund *models.RefundInput = &models.RefundInput{Amount: refund.Amount}
+	// transaction created by merchant client, refunded by the merchant
+	merchantId          string              = "merchant-1"
+	merchantTransaction *models.Transaction = &models.Transaction{
+		Id:         transaction.Id,
+		UserId:     transaction.UserId,
+		Amount:     transaction.Amount,
+		Currency:   transaction.Currency,
+		Status:     services.TransactionSuccessStatus,
+		MerchantId: &merchantId,
+	}
+	merchantPrincipal *auth.Principal = &auth.Principal{Subject: "client-1", MerchantId: merchantId}
+)
+
+func TestHandler_CreateRefund(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockRefundService, transactionId int)
+	serializedRefund, _ := json.Marshal(refund)
+	serializedInputRefund, _ := json.Marshal(inputRefund)
+
+	testTable := []struct {
+		name                string
+		transactionId       int
+		requestBody         []byte
+		principal           *auth.Principal
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test create refund (ok)",
+			transactionId:       transaction.Id,
+			requestBody:         serializedInputRefund,
+			principal:           merchantPrincipal,
+			expectedStatusCode:  http.StatusCreated,
+			expectedRequestBody: string(serializedRefund) + "\n",
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(merchantTransaction, nil)
+				service.EXPECT().Create(transactionId, inputRefund).Return(refund, nil)
+			},
+		},
+		{
+			name:                "Test create refund (amount exceeded)",
+			transactionId:       transaction.Id,
+			requestBody:         serializedInputRefund,
+			principal:           merchantPrincipal,
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedRequestBody: errorBody(services.ErrRefundAmountExceeded, "/api/transactions/1/refunds/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(merchantTransaction, nil)
+				service.EXPECT().Create(transactionId, inputRefund).Return(nil, services.ErrRefundAmountExceeded)
+			},
+		},
+		{
+			name:                "Test create refund (not refundable)",
+			transactionId:       transaction.Id,
+			requestBody:         serializedInputRefund,
+			principal:           merchantPrincipal,
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedRequestBody: errorBody(services.ErrRefundNotAllowed, "/api/transactions/1/refunds/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(merchantTransaction, nil)
+				service.EXPECT().Create(transactionId, inputRefund).Return(nil, services.ErrRefundNotAllowed)
+			},
+		},
+		{
+			name:                "Test create refund (not found)",
+			transactionId:       transaction.Id,
+			requestBody:         serializedInputRefund,
+			principal:           merchantPrincipal,
+			expectedStatusCode:  http.StatusNotFound,
+			expectedRequestBody: errorBody(services.ErrTransactionNotFound, "/api/transactions/1/refunds/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(nil, services.ErrTransactionNotFound)
+			},
+		},
+		{
+			name:                "Test create refund (admin)",
+			transactionId:       transaction.Id,
+			requestBody:         serializedInputRefund,
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusCreated,
+			expectedRequestBody: string(serializedRefund) + "\n",
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(merchantTransaction, nil)
+				service.EXPECT().Create(transactionId, inputRefund).Return(refund, nil)
+			},
+		},
+		{
+			name:                "Test create refund (refunds scope)",
+			transactionId:       transaction.Id,
+			requestBody:         serializedInputRefund,
+			principal:           &auth.Principal{Subject: "support", Scopes: []string{auth.ScopeRefunds}},
+			expectedStatusCode:  http.StatusCreated,
+			expectedRequestBody: string(serializedRefund) + "\n",
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(merchantTransaction, nil)
+				service.EXPECT().Create(transactionId, inputRefund).Return(refund, nil)
+			},
+		},
+		{
+			// payer can not move money of captured payment back
+			name:                "Test create refund (owner)",
+			transactionId:       transaction.Id,
+			requestBody:         serializedInputRefund,
+			principal:           ownerPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrRefundAccessDenied, "/api/transactions/1/refunds/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(merchantTransaction, nil)
+			},
+		},
+		{
+			name:                "Test create refund (another merchant)",
+			transactionId:       transaction.Id,
+			requestBody:         serializedInputRefund,
+			principal:           &auth.Principal{Subject: "client-2", MerchantId: "merchant-2"},
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrRefundAccessDenied, "/api/transactions/1/refunds/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(merchantTransaction, nil)
+			},
+		},
+		{
+			name:               "Test create refund (bad body)",
+			transactionId:      transaction.Id,
+			requestBody:        []byte(`{"amount": -1}`),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "amount", Tag: "gt", Message: "must be greater than 0"},
+			}}, "/api/transactions/1/refunds/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockRefundService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			signature_service := mock_services.NewMockSignatureMiddleware(controller)
+			testCase.mockBehaviour(service, testCase.transactionId)
+
+			handler := NewRefundHandler(service, auth_service, signature_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/transactions/{pk:[0-9]+}/refunds/", handler.CreateRefund)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(
+				"POST",
+				fmt.Sprintf("/api/transactions/%d/refunds/", testCase.transactionId),
+				bytes.NewBuffer(testCase.requestBody),
+			)
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(testCase.principal)))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_RefundsList(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockRefundService, transactionId int)
+	serializedRefunds, _ := json.Marshal([]*models.Refund{refund})
+
+	testTable := []struct {
+		name                string
+		principal           *auth.Principal
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test refunds list (ok)",
+			principal:           ownerPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedRefunds) + "\n",
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(transaction, nil)
+				service.EXPECT().ListByTransaction(transactionId).Return([]*models.Refund{refund}, nil)
+			},
+		},
+		{
+			name:                "Test refunds list (another user)",
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrTransactionAccessDenied, "/api/transactions/1/refunds/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(transaction, nil)
+			},
+		},
+		{
+			name:                "Test refunds list (not found)",
+			principal:           ownerPrincipal,
+			expectedStatusCode:  http.StatusNotFound,
+			expectedRequestBody: errorBody(services.ErrTransactionNotFound, "/api/transactions/1/refunds/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(nil, services.ErrTransactionNotFound)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockRefundService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			signature_service := mock_services.NewMockSignatureMiddleware(controller)
+			testCase.mockBehaviour(service, transaction.Id)
+
+			handler := NewRefundHandler(service, auth_service, signature_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/transactions/{pk:[0-9]+}/refunds/", handler.RefundsList)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", fmt.Sprintf("/api/transactions/%d/refunds/", transaction.Id), nil)
+			r = r.WithContext(auth.NewContext(r.Context(), testCase.principal))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_RetrieveRefund(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockRefundService, transactionId int, refundId int)
+	serializedRefund, _ := json.Marshal(refund)
+
+	testTable := []struct {
+		name                string
+		principal           *auth.Principal
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test retrieve refund (ok)",
+			principal:           ownerPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedRefund) + "\n",
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int, refundId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(transaction, nil)
+				service.EXPECT().GetById(transactionId, refundId).Return(refund, nil)
+			},
+		},
+		{
+			name:                "Test retrieve refund (admin)",
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedRefund) + "\n",
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int, refundId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(transaction, nil)
+				service.EXPECT().GetById(transactionId, refundId).Return(refund, nil)
+			},
+		},
+		{
+			name:                "Test retrieve refund (another user)",
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrTransactionAccessDenied, "/api/transactions/1/refunds/1/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int, refundId int) {
+				service.EXPECT().GetTransaction(transactionId).Return(transaction, nil)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockRefundService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			signature_service := mock_services.NewMockSignatureMiddleware(controller)
+			testCase.mockBehaviour(service, transaction.Id, refund.Id)
+
+			handler := NewRefundHandler(service, auth_service, signature_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/transactions/{pk:[0-9]+}/refunds/{refundPk:[0-9]+}/", handler.RetrieveRefund)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(
+				"GET", fmt.Sprintf("/api/transactions/%d/refunds/%d/", transaction.Id, refund.Id), nil,
+			)
+			r = r.WithContext(auth.NewContext(r.Context(), testCase.principal))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_ProceedRefund(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockRefundService, transactionId int, refundId int)
+	serializedRefund, _ := json.Marshal(successRefund)
+	requestBody := []byte(`{"status": "SUCCESS"}`)
+
+	testTable := []struct {
+		name                string
+		transactionId       int
+		refundId            int
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test proceed refund (ok)",
+			transactionId:       transaction.Id,
+			refundId:            refund.Id,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedRefund) + "\n",
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int, refundId int) {
+				service.EXPECT().UpdateStatus(
+					transactionId, refundId, services.RefundSuccessStatus, services.RoleProvider,
+				).Return(successRefund, nil)
+			},
+		},
+		{
+			name:               "Test proceed refund (terminal status)",
+			transactionId:      transaction.Id,
+			refundId:           refund.Id,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.TransitionError{
+				From: services.RefundFailedStatus,
+				To:   services.RefundSuccessStatus,
+				Role: services.RoleProvider,
+				Kind: services.ErrTerminalStatus,
+			}, "/api/transactions/1/refunds/1/proceed/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int, refundId int) {
+				service.EXPECT().UpdateStatus(
+					transactionId, refundId, services.RefundSuccessStatus, services.RoleProvider,
+				).Return(nil, &services.TransitionError{
+					From: services.RefundFailedStatus,
+					To:   services.RefundSuccessStatus,
+					Role: services.RoleProvider,
+					Kind: services.ErrTerminalStatus,
+				})
+			},
+		},
+		{
+			name:                "Test proceed refund (not found)",
+			transactionId:       transaction.Id,
+			refundId:            refund.Id,
+			expectedStatusCode:  http.StatusNotFound,
+			expectedRequestBody: errorBody(services.ErrRefundNotFound, "/api/transactions/1/refunds/1/proceed/"),
+			mockBehaviour: func(service *mock_services.MockRefundService, transactionId int, refundId int) {
+				service.EXPECT().UpdateStatus(
+					transactionId, refundId, services.RefundSuccessStatus, services.RoleProvider,
+				).Return(nil, services.ErrRefundNotFound)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockRefundService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			signature_service := mock_services.NewMockSignatureMiddleware(controller)
+			testCase.mockBehaviour(service, testCase.transactionId, testCase.refundId)
+
+			handler := NewRefundHandler(service, auth_service, signature_service)
+			router := mux.NewRouter()
+			router.HandleFunc(
+				"/api/transactions/{pk:[0-9]+}/refunds/{refundPk:[0-9]+}/proceed/", handler.ProceedRefund,
+			)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(
+				"PUT",
+				fmt.Sprintf("/api/transactions/%d/refunds/%d/proceed/", testCase.transactionId, testCase.refundId),
+				bytes.NewBuffer(requestBody),
+			)
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_ProceedRefundSignature(t *testing.T) {
+	// Arrange
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	passThrough := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	service := mock_services.NewMockRefundService(controller)
+	auth_service := mock_services.NewMockAuthMiddleware(controller)
+	signature_service := mock_services.NewMockSignatureMiddleware(controller)
+	auth_service.EXPECT().AuthMiddleware(gomock.Any()).DoAndReturn(passThrough).AnyTimes()
+	auth_service.EXPECT().RequireScope(services.RoleProvider).Return(passThrough)
+	// request without valid signature is rejected before refund status is updated
+	signature_service.EXPECT().SignatureMiddleware(gomock.Any()).Return(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	router := mux.NewRouter()
+	NewRefundHandler(service, auth_service, signature_service).InitRoutes(router.PathPrefix("/api").Subrouter())
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PUT", "/api/transactions/1/refunds/1/proceed/", bytes.NewBufferString(`{"status": "SUCCESS"}`))
+
+	// Act
+	router.ServeHTTP(w, r)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/money"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func newValidator() *validator.Validate {
+	/*Create validator which reports fields by their JSON names and supports custom tags.*/
+	var validate *validator.Validate = validator.New()
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+	// supported ISO 4217 currency code
+	validate.RegisterValidation("currency", func(field validator.FieldLevel) bool {
+		return money.IsCurrency(field.Field().String())
+	})
+
+	return validate
+}
+
+func parseRequestBody(w http.ResponseWriter, r *http.Request, input interface{}) bool {
+	/*Parse request body to input struct and validate it, write problem response on failure.*/
+
+	// parsing request body data to input struct
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		problem.Write(w, r, problem.New(
+			http.StatusBadRequest, problem.CodeInvalidRequest, fmt.Sprintf("invalid request: %s", err)))
+		return false
+	}
+
+	// validate parsed data
+	if err := newValidator().Struct(input); err != nil {
+		problem.WriteError(w, r, err)
+		return false
+	}
+
+	return true
+}
+
+func requestPrincipal(r *http.Request) *auth.Principal {
+	/*Return principal of authenticated request, empty principal (without any access) otherwise.*/
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return principal
+	}
+
+	return &auth.Principal{}
+}
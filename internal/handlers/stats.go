@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+type StatsService interface {
+	TransactionStats(filter *models.TransactionStatsFilter) (*models.TransactionStats, error)
+}
+
+type StatsHandler struct {
+	service        StatsService
+	authMiddleware AuthMiddleware
+}
+
+func NewStatsHandler(service StatsService, authMiddleware AuthMiddleware) *StatsHandler {
+	/*Statistics routes handler constructor function.*/
+	return &StatsHandler{service: service, authMiddleware: authMiddleware}
+}
+
+func (handler *StatsHandler) InitRoutes(router *mux.Router) {
+	/*Perform initialization of all required routes for transactions statistics.*/
+	var statsRouter *mux.Router = router.PathPrefix("/stats").Subrouter()
+	var usersRouter *mux.Router = router.PathPrefix("/users").Subrouter()
+
+	// statistics of all transactions are available to admins only
+	statsRouter.HandleFunc(
+		"/", handler.authMiddleware.RequireScope(auth.ScopeAdmin)(handler.TransactionStats),
+	).Methods("GET")
+	usersRouter.HandleFunc(
+		"/{userId:[0-9]+}/stats/", handler.authMiddleware.AuthMiddleware(handler.UserTransactionStats),
+	).Methods("GET")
+}
+
+func (handler *StatsHandler) TransactionStats(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to retrieve statistics of all transactions, accept window params in URL query.*/
+	handler.writeStats(w, r, nil)
+}
+
+func (handler *StatsHandler) UserTransactionStats(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to retrieve statistics of user's transactions.
+
+		Accept user PK in URL params and window params in URL query.
+	*/
+	var err error
+	var userId int
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve user PK from url variables
+	userId, err = strconv.Atoi(params["userId"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// only user itself and admin are allowed to see user's statistics
+	if err = services.AuthorizeUser(requestPrincipal(r), userId); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	handler.writeStats(w, r, &userId)
+}
+
+func (handler *StatsHandler) writeStats(w http.ResponseWriter, r *http.Request, userId *int) {
+	/*Parse and validate window params, write statistics of transactions (of the user if passed).*/
+	var err error
+	var filter *models.TransactionStatsFilter
+	var stats *models.TransactionStats
+
+	filter, err = parseTransactionStatsFilter(r.URL.Query())
+	if err == nil {
+		err = newValidator().Struct(filter)
+	}
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+	filter.UserId = userId
+
+	stats, err = handler.service.TransactionStats(filter)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+	mock_services "github.com/Pythonyan3/payment-service/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_TransactionStats(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockStatsService)
+	userId := 1
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	stats := &models.TransactionStats{
+		From:     from,
+		To:       to,
+		Interval: "day",
+		Buckets:  []*models.TransactionStatsBucket{},
+		Total:    models.TransactionStatsSummary{Totals: []*models.TransactionStatsTotal{}},
+	}
+	serializedStats, _ := json.Marshal(stats)
+	window := "?from=2022-06-01T00:00:00Z&to=2022-07-01T00:00:00Z"
+
+	testTable := []struct {
+		name                string
+		url                 string
+		principal           *auth.Principal
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test all transactions stats (ok)",
+			url:                 "/api/stats/" + window,
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedStats) + "\n",
+			mockBehaviour: func(service *mock_services.MockStatsService) {
+				service.EXPECT().TransactionStats(
+					&models.TransactionStatsFilter{From: from, To: to, Interval: "day"},
+				).Return(stats, nil)
+			},
+		},
+		{
+			name:                "Test user transactions stats (ok)",
+			url:                 "/api/users/1/stats/" + window + "&interval=month",
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedStats) + "\n",
+			mockBehaviour: func(service *mock_services.MockStatsService) {
+				service.EXPECT().TransactionStats(
+					&models.TransactionStatsFilter{From: from, To: to, Interval: "month", UserId: &userId},
+				).Return(stats, nil)
+			},
+		},
+		{
+			name:                "Test user transactions stats (another user)",
+			url:                 "/api/users/1/stats/" + window,
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrUserAccessDenied, "/api/users/1/stats/"),
+			mockBehaviour:       func(service *mock_services.MockStatsService) {},
+		},
+		{
+			name:               "Test transactions stats (unknown interval)",
+			url:                "/api/stats/" + window + "&interval=week",
+			principal:          adminPrincipal,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "interval", Tag: "oneof", Message: "must be one of: hour day month"},
+			}}, "/api/stats/"),
+			mockBehaviour: func(service *mock_services.MockStatsService) {},
+		},
+		{
+			name:               "Test transactions stats (missing window)",
+			url:                "/api/stats/?to=2022-07-01T00:00:00Z",
+			principal:          adminPrincipal,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "from", Tag: "required", Message: "is required"},
+			}}, "/api/stats/"),
+			mockBehaviour: func(service *mock_services.MockStatsService) {},
+		},
+		{
+			name:               "Test transactions stats (empty window)",
+			url:                "/api/stats/?from=2022-07-01T00:00:00Z&to=2022-07-01T00:00:00Z",
+			principal:          adminPrincipal,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "to", Tag: "gtfield", Message: "must be greater than From field"},
+			}}, "/api/stats/"),
+			mockBehaviour: func(service *mock_services.MockStatsService) {},
+		},
+		{
+			name:               "Test transactions stats (bad datetime)",
+			url:                "/api/stats/?from=yesterday&to=2022-07-01T00:00:00Z",
+			principal:          adminPrincipal,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "from", Tag: "datetime", Message: "must be a datetime in RFC 3339 format"},
+			}}, "/api/stats/"),
+			mockBehaviour: func(service *mock_services.MockStatsService) {},
+		},
+		{
+			name:                "Test transactions stats (window too large)",
+			url:                 "/api/stats/?from=2012-06-01T00:00:00Z&to=2022-07-01T00:00:00Z",
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedRequestBody: errorBody(services.ErrStatsWindowTooLarge, "/api/stats/"),
+			mockBehaviour: func(service *mock_services.MockStatsService) {
+				service.EXPECT().TransactionStats(gomock.Any()).Return(nil, services.ErrStatsWindowTooLarge)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockStatsService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			testCase.mockBehaviour(service)
+
+			handler := NewStatsHandler(service, auth_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/stats/", handler.TransactionStats)
+			router.HandleFunc("/api/users/{userId:[0-9]+}/stats/", handler.UserTransactionStats)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", testCase.url, bytes.NewBufferString(""))
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(testCase.principal)))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
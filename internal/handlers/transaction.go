@@ -1,60 +1,115 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
-	"fmt"
-	"log"
+	"io"
 	"net/http"
 	"strconv"
-	"strings"
 
+	"github.com/Pythonyan3/payment-service/internal/auth"
 	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
 	"github.com/Pythonyan3/payment-service/internal/services"
-	"github.com/go-playground/validator/v10"
 
 	"github.com/gorilla/mux"
 )
 
-const dbNotFoundErrorMsg = "sql: no rows in result set"
-
 type TransactionService interface {
 	GetById(transactionId int) (*models.Transaction, error)
 	Create(transactionInput *models.TransactionInput) (*models.Transaction, error)
-	UpdateStatus(transactionId int, status string) (*models.Transaction, error)
+	UpdateStatus(transactionId int, status string, role string) (*models.Transaction, error)
+	Cancel(transactionId int, principal *auth.Principal) (*models.Transaction, error)
+	Search(filter *models.TransactionSearchFilter) (*models.TransactionPage, error)
+	Sync(transactionId int) (*models.Transaction, error)
 }
 
 type AuthMiddleware interface {
 	AuthMiddleware(next http.HandlerFunc) http.HandlerFunc
+	RequireScope(scopes ...string) func(http.HandlerFunc) http.HandlerFunc
+}
+
+type IdempotencyMiddleware interface {
+	IdempotencyMiddleware(next http.HandlerFunc) http.HandlerFunc
+}
+
+type SignatureMiddleware interface {
+	SignatureMiddleware(next http.HandlerFunc) http.HandlerFunc
+}
+
+type RateLimitMiddleware interface {
+	RateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc
 }
 
 type TransactionHandler struct {
-	service        TransactionService
-	authMiddleware AuthMiddleware
+	service               TransactionService
+	authMiddleware        AuthMiddleware
+	idempotencyMiddleware IdempotencyMiddleware
+	signatureMiddleware   SignatureMiddleware
+	rateLimitMiddleware   RateLimitMiddleware
 }
 
-func NewTransactionHandler(service TransactionService, middleware AuthMiddleware) *TransactionHandler {
+func NewTransactionHandler(
+	service TransactionService,
+	authMiddleware AuthMiddleware,
+	idempotencyMiddleware IdempotencyMiddleware,
+	signatureMiddleware SignatureMiddleware,
+	rateLimitMiddleware RateLimitMiddleware,
+) *TransactionHandler {
 	/*Transaction routes handler constructor function.*/
-	return &TransactionHandler{service: service, authMiddleware: middleware}
+	return &TransactionHandler{
+		service:               service,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+		signatureMiddleware:   signatureMiddleware,
+		rateLimitMiddleware:   rateLimitMiddleware,
+	}
 }
 
 func (handler *TransactionHandler) InitRoutes(router *mux.Router) {
 	/*Perform initialization of all required routes for transaction entity.*/
 	var subRouter *mux.Router = router.PathPrefix("/transactions").Subrouter()
 
-	subRouter.HandleFunc("/", handler.CreateTransaction).Methods("POST")
-	subRouter.HandleFunc("/{pk:[0-9]+}/", handler.RetrieveTransaction).Methods("GET")
-	subRouter.HandleFunc("/{pk:[0-9]+}/cancel/", handler.CancelTransaction).Methods("PUT", "PATCH")
+	// access to the user is checked before user_id bucket is charged and before stored response is replayed,
+	// creation is limited by subject, IP and user_id, replayed requests are counted as well
+	subRouter.HandleFunc(
+		"/",
+		handler.authMiddleware.AuthMiddleware(
+			handler.authorizeTransactionUser(
+				handler.rateLimitMiddleware.RateLimitMiddleware(
+					handler.idempotencyMiddleware.IdempotencyMiddleware(handler.CreateTransaction),
+				),
+			),
+		),
+	).Methods("POST")
+	// search of all transactions is available to admins (support team) only
+	subRouter.HandleFunc("/", handler.authMiddleware.RequireScope(auth.ScopeAdmin)(handler.SearchTransactions)).Methods("GET")
+	subRouter.HandleFunc(
+		"/{pk:[0-9]+}/",
+		handler.authMiddleware.AuthMiddleware(handler.RetrieveTransaction),
+	).Methods("GET")
+	subRouter.HandleFunc(
+		"/{pk:[0-9]+}/cancel/",
+		handler.authMiddleware.AuthMiddleware(handler.CancelTransaction),
+	).Methods("PUT", "PATCH")
 	subRouter.HandleFunc(
 		"/{pk:[0-9]+}/proceed/",
-		handler.authMiddleware.AuthMiddleware(handler.ProceedTransaction),
+		handler.authMiddleware.RequireScope(services.RoleProvider)(
+			handler.signatureMiddleware.SignatureMiddleware(handler.ProceedTransaction),
+		),
 	).Methods("PUT", "PATCH")
+	// status is synchronized with payment provider by admins (support team) only
+	subRouter.HandleFunc(
+		"/{pk:[0-9]+}/sync/",
+		handler.authMiddleware.RequireScope(auth.ScopeAdmin)(handler.SyncTransaction),
+	).Methods("POST")
 }
 
 func (handler *TransactionHandler) RetrieveTransaction(w http.ResponseWriter, r *http.Request) {
 	/*
 		Handle request to retrieve transaction info.
 
-		Accept transaction PK in URL params to perform retriving.
+		Accept transaction PK in URL params to perform retriving, only owner or admin can access transaction.
 	*/
 	var err error
 	var transactionId int
@@ -64,51 +119,72 @@ func (handler *TransactionHandler) RetrieveTransaction(w http.ResponseWriter, r
 	// retrieve transaction PK from url variables
 	transactionId, err = strconv.Atoi(params["pk"])
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
+		return
 	}
 
-	// retriving transaction info with service
+	// retriving transaction info with service and check access to it
 	transaction, err = handler.service.GetById(transactionId)
+	if err == nil {
+		err = services.AuthorizeTransaction(requestPrincipal(r), transaction)
+	}
 
 	if err != nil {
-		if strings.Contains(err.Error(), dbNotFoundErrorMsg) {
-			// return HTTP 404 status code if transaction was not found
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-		} else {
-			// otherwise probably something went wrong...
-			log.Printf("handler.service.GetById failed: %s", err.Error())
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-		}
+		problem.WriteError(w, r, err)
 		return
 	}
 
 	json.NewEncoder(w).Encode(transaction)
 }
 
+func (handler *TransactionHandler) SearchTransactions(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to retrieve page of all transactions, accept filter, sorting and pagination params in URL query.*/
+	var err error
+	var filter *models.TransactionSearchFilter
+	var page *models.TransactionPage
+
+	// parse and validate search params
+	filter, err = parseTransactionSearchFilter(r.URL.Query())
+	if err == nil {
+		err = newValidator().Struct(filter)
+	}
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	page, err = handler.service.Search(filter)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(page)
+}
+
 func (handler *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
-	/*Handle request to create new transaction.*/
+	/*Handle request to create new transaction, user token is allowed to create only own transactions.*/
 	var err error
 	var transactionInput models.TransactionInput
 	var transaction *models.Transaction
-	var validator *validator.Validate = validator.New()
 
-	// parsing reqeust body data to transaction struct
-	if err := json.NewDecoder(r.Body).Decode(&transactionInput); err != nil {
-		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+	// parsing and validating request body data
+	if !parseRequestBody(w, r, &transactionInput) {
 		return
 	}
 
-	// validate parsed data
-	if err := validator.Struct(transactionInput); err != nil {
-		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+	if err = services.AuthorizeUser(requestPrincipal(r), transactionInput.UserId); err != nil {
+		problem.WriteError(w, r, err)
 		return
 	}
+	// payment provider is selected by merchant of the client
+	transactionInput.MerchantId = requestPrincipal(r).MerchantId
 
 	// create new transaction with a service
 	transaction, err = handler.service.Create(&transactionInput)
 	if err != nil {
-		log.Printf("handler.service.Create failed: %s", err.Error())
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
 		return
 	}
 
@@ -116,6 +192,33 @@ func (handler *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *h
 	json.NewEncoder(w).Encode(transaction)
 }
 
+func (handler *TransactionHandler) authorizeTransactionUser(next http.HandlerFunc) http.HandlerFunc {
+	/*Check principal is allowed to create transaction of the user passed in request body, body is restored for next handler.*/
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input struct {
+			UserId int `json:"user_id"`
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			problem.Write(w, r, problem.New(
+				http.StatusBadRequest, problem.CodeInvalidRequest, "Request body can not be read."))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// malformed body is rejected by the next handler
+		if json.Unmarshal(body, &input) == nil {
+			if err = services.AuthorizeUser(requestPrincipal(r), input.UserId); err != nil {
+				problem.WriteError(w, r, err)
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
+
 func (handler *TransactionHandler) ProceedTransaction(w http.ResponseWriter, r *http.Request) {
 	/*Handle request to update transaction status by payment service.*/
 	var err error
@@ -123,41 +226,24 @@ func (handler *TransactionHandler) ProceedTransaction(w http.ResponseWriter, r *
 	var transaction *models.Transaction
 	var transactionStatusInput models.TransactionStatusInput
 	var params map[string]string = mux.Vars(r)
-	var validator *validator.Validate = validator.New()
 
 	// retrieve transaction PK from URL variables
 	transactionId, err = strconv.Atoi(params["pk"])
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-	}
-
-	// parse request body data to transaction status struct
-	if err := json.NewDecoder(r.Body).Decode(&transactionStatusInput); err != nil {
-		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+		problem.WriteError(w, r, err)
 		return
 	}
 
-	// validate parsed data
-	if err := validator.Struct(transactionStatusInput); err != nil {
-		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+	// parsing and validating request body data
+	if !parseRequestBody(w, r, &transactionStatusInput) {
 		return
 	}
 
 	// update transaction status with a service struct
-	transaction, err = handler.service.UpdateStatus(transactionId, transactionStatusInput.Status)
+	transaction, err = handler.service.UpdateStatus(transactionId, transactionStatusInput.Status, services.RoleProvider)
 
 	if err != nil {
-		if strings.Contains(err.Error(), services.TerminalStatusErrorMessage) {
-			// return HTTP 400 status code if transaction has terminal status
-			http.Error(w, "Can not proceed transaction with it's current status.", http.StatusBadRequest)
-		} else if strings.Contains(err.Error(), dbNotFoundErrorMsg) {
-			// return HTTP 404 status code if transaction was not found
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-		} else {
-			// otherwise probably something went wrong...
-			log.Printf("handler.service.UpdateStatus failed: %s", err.Error())
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-		}
+		problem.WriteError(w, r, err)
 		return
 	}
 
@@ -165,7 +251,7 @@ func (handler *TransactionHandler) ProceedTransaction(w http.ResponseWriter, r *
 }
 
 func (handler *TransactionHandler) CancelTransaction(w http.ResponseWriter, r *http.Request) {
-	/*Handle request to cancel transaction.*/
+	/*Handle request to cancel transaction by it's owner or admin.*/
 	var err error
 	var transactionId int
 	var transaction *models.Transaction
@@ -174,24 +260,40 @@ func (handler *TransactionHandler) CancelTransaction(w http.ResponseWriter, r *h
 	// retrieve transaction PK from URL variables
 	transactionId, err = strconv.Atoi(params["pk"])
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
+		return
 	}
 
-	// update transaction status with a service
-	transaction, err = handler.service.UpdateStatus(transactionId, services.TransactionCanceledStatus)
+	// cancel transaction with a service
+	transaction, err = handler.service.Cancel(transactionId, requestPrincipal(r))
 
 	if err != nil {
-		if strings.Contains(err.Error(), services.TerminalStatusErrorMessage) {
-			// return HTTP 400 status code if transaction has terminal status
-			http.Error(w, "Can not proceed transaction with it's current status.", http.StatusBadRequest)
-		} else if strings.Contains(err.Error(), dbNotFoundErrorMsg) {
-			// return HTTP 404 status code if transaction was not found
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-		} else {
-			// otherwise probably something went wrong...
-			log.Printf("handler.service.UpdateStatus failed: %s", err.Error())
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-		}
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(transaction)
+}
+
+func (handler *TransactionHandler) SyncTransaction(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to synchronize transaction status with payment provider.*/
+	var err error
+	var transactionId int
+	var transaction *models.Transaction
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve transaction PK from URL variables
+	transactionId, err = strconv.Atoi(params["pk"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// synchronize transaction status with a service
+	transaction, err = handler.service.Sync(transactionId)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
 		return
 	}
 
@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/Pythonyan3/payment-service/internal/auth"
 	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
 	"github.com/Pythonyan3/payment-service/internal/services"
 	mock_services "github.com/Pythonyan3/payment-service/internal/services/mocks"
 
@@ -43,18 +46,19 @@ var (
 	}
 	transactionSlice []*models.Transaction    = []*models.Transaction{transaction}
 	inputTransaction *models.TransactionInput = &models.TransactionInput{
-		UserId:    transaction.Id,
-		UserEmail: transaction.UserEmail,
-		Amount:    transaction.Amount,
-		Currency:  transaction.Currency,
+		UserId:   transaction.Id,
+		Amount:   transaction.Amount,
+		Currency: transaction.Currency,
 	}
 	badInputTransaction *models.TransactionInput = &models.TransactionInput{
-		UserId:    transaction.Id,
-		UserEmail: "not email",
-		Amount:    transaction.Amount,
-		Currency:  transaction.Currency,
+		UserId:   -1,
+		Amount:   transaction.Amount,
+		Currency: transaction.Currency,
 	}
-	emptyBody []byte = []byte{}
+	emptyBody      []byte          = []byte{}
+	ownerPrincipal *auth.Principal = &auth.Principal{Subject: "1", Email: "email@mail.ru"}
+	otherPrincipal *auth.Principal = &auth.Principal{Subject: "2"}
+	adminPrincipal *auth.Principal = &auth.Principal{Subject: "2", Scopes: []string{auth.ScopeAdmin}}
 )
 
 func TestHandler_RetrieveTransaction(t *testing.T) {
@@ -65,6 +69,7 @@ func TestHandler_RetrieveTransaction(t *testing.T) {
 	testTable := []struct {
 		name                string
 		transactionId       int
+		principal           *auth.Principal
 		mockBehaviour       mockBehaviour
 		expectedStatusCode  int
 		expectedRequestBody string
@@ -72,26 +77,49 @@ func TestHandler_RetrieveTransaction(t *testing.T) {
 		{
 			name:                "Test retrieve transaction (ok)",
 			transactionId:       transaction.Id,
+			principal:           ownerPrincipal,
 			expectedStatusCode:  http.StatusOK,
 			expectedRequestBody: string(serializedTransactions) + "\n",
 			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
 				service.EXPECT().GetById(transactionId).Return(transaction, nil)
 			},
 		},
+		{
+			name:                "Test retrieve transaction (admin)",
+			transactionId:       transaction.Id,
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedTransactions) + "\n",
+			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
+				service.EXPECT().GetById(transactionId).Return(transaction, nil)
+			},
+		},
+		{
+			name:                "Test retrieve transaction (another user)",
+			transactionId:       transaction.Id,
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrTransactionAccessDenied, "/api/transactions/1/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
+				service.EXPECT().GetById(transactionId).Return(transaction, nil)
+			},
+		},
 		{
 			name:                "Test retrieve transaction (not found)",
 			transactionId:       transaction.Id,
+			principal:           ownerPrincipal,
 			expectedStatusCode:  http.StatusNotFound,
-			expectedRequestBody: "Not Found\n",
+			expectedRequestBody: errorBody(services.ErrTransactionNotFound, "/api/transactions/1/"),
 			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
-				service.EXPECT().GetById(transactionId).Return(nil, errors.New(dbNotFoundErrorMsg))
+				service.EXPECT().GetById(transactionId).Return(nil, services.ErrTransactionNotFound)
 			},
 		},
 		{
 			name:                "Test retrieve transaction (service error)",
 			transactionId:       transaction.Id,
+			principal:           ownerPrincipal,
 			expectedStatusCode:  http.StatusInternalServerError,
-			expectedRequestBody: "Internal Server Error\n",
+			expectedRequestBody: errorBody(errors.New("some error"), "/api/transactions/1/"),
 			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
 				service.EXPECT().GetById(transactionId).Return(nil, errors.New("some error"))
 			},
@@ -106,9 +134,12 @@ func TestHandler_RetrieveTransaction(t *testing.T) {
 
 			service := mock_services.NewMockTransactionService(controller)
 			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			idempotency_service := mock_services.NewMockIdempotencyMiddleware(controller)
+			signature_service := mock_services.NewMockSignatureMiddleware(controller)
+			rate_limit_service := mock_services.NewMockRateLimitMiddleware(controller)
 			testCase.mockBehaviour(service, testCase.transactionId)
 
-			handler := NewTransactionHandler(service, auth_service)
+			handler := NewTransactionHandler(service, auth_service, idempotency_service, signature_service, rate_limit_service)
 			router := mux.NewRouter()
 			router.HandleFunc("/api/transactions/{pk:[0-9]+}/", handler.RetrieveTransaction)
 
@@ -116,6 +147,7 @@ func TestHandler_RetrieveTransaction(t *testing.T) {
 			r := httptest.NewRequest(
 				"GET", fmt.Sprintf("/api/transactions/%d/", testCase.transactionId), bytes.NewBufferString(""),
 			)
+			r = r.WithContext(auth.NewContext(r.Context(), testCase.principal))
 
 			router.ServeHTTP(w, r)
 
@@ -137,6 +169,7 @@ func TestHandler_CreateTransaction(t *testing.T) {
 		name                string
 		inputTransaction    *models.TransactionInput
 		requestBody         []byte
+		principal           *auth.Principal
 		mockBehaviour       mockBehaviour
 		expectedStatusCode  int
 		expectedRequestBody string
@@ -151,31 +184,92 @@ func TestHandler_CreateTransaction(t *testing.T) {
 				service.EXPECT().Create(inputTransaction).Return(transaction, nil)
 			},
 		},
+		{
+			name:                "Test create transaction (another user)",
+			inputTransaction:    inputTransaction,
+			requestBody:         serializedInputTransaction,
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrUserAccessDenied, "/api/transactions/"),
+			mockBehaviour:       func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {},
+		},
 		{
 			name:                "Test create transaction (service error)",
 			inputTransaction:    inputTransaction,
 			requestBody:         serializedInputTransaction,
 			expectedStatusCode:  http.StatusInternalServerError,
-			expectedRequestBody: "Internal Server Error\n",
+			expectedRequestBody: errorBody(errors.New("some error"), "/api/transactions/"),
 			mockBehaviour: func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {
 				service.EXPECT().Create(inputTransaction).Return(nil, errors.New("some error"))
 			},
 		},
+		{
+			name:                "Test create transaction (insufficient funds)",
+			inputTransaction:    inputTransaction,
+			requestBody:         serializedInputTransaction,
+			expectedStatusCode:  http.StatusPaymentRequired,
+			expectedRequestBody: errorBody(services.ErrInsufficientFunds, "/api/transactions/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {
+				service.EXPECT().Create(inputTransaction).Return(nil, services.ErrInsufficientFunds)
+			},
+		},
 		{
 			name:                "Test create transaction (no body)",
 			inputTransaction:    inputTransaction,
 			requestBody:         emptyBody,
 			expectedStatusCode:  http.StatusBadRequest,
-			expectedRequestBody: "invalid request: EOF\n",
+			expectedRequestBody: problemBody(problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, "invalid request: EOF"), "/api/transactions/"),
 			mockBehaviour:       func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {},
 		},
 		{
-			name:                "Test create transaction (bad body)",
-			inputTransaction:    badInputTransaction,
-			requestBody:         serializedBadInputTransaction,
-			expectedStatusCode:  http.StatusBadRequest,
-			expectedRequestBody: "invalid request: Key: 'TransactionInput.UserEmail' Error:Field validation for 'UserEmail' failed on the 'email' tag\n",
-			mockBehaviour:       func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {},
+			name:               "Test create transaction (bad body)",
+			inputTransaction:   badInputTransaction,
+			requestBody:        serializedBadInputTransaction,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "user_id", Tag: "gt", Message: "must be greater than 0"},
+			}}, "/api/transactions/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {},
+		},
+		{
+			name:               "Test create transaction (unsupported currency)",
+			inputTransaction:   inputTransaction,
+			requestBody:        []byte(`{"user_id": 1, "amount": 100, "currency": "XXX"}`),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "currency", Tag: "currency", Message: "must be a supported ISO 4217 currency code"},
+			}}, "/api/transactions/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {},
+		},
+		{
+			name:               "Test create transaction (both amounts)",
+			inputTransaction:   inputTransaction,
+			requestBody:        []byte(`{"user_id": 1, "amount": 100, "amount_decimal": "1.00", "currency": "EUR"}`),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "amount_decimal", Tag: "excluded_with", Message: "must not be passed together with Amount"},
+			}}, "/api/transactions/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {},
+		},
+		{
+			name:               "Test create transaction (too short ttl)",
+			inputTransaction:   inputTransaction,
+			requestBody:        []byte(`{"user_id": 1, "amount": 100, "currency": "EUR", "ttl_seconds": 10}`),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "ttl_seconds", Tag: "min", Message: "must be at least 60"},
+			}}, "/api/transactions/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {},
+		},
+		{
+			name:               "Test create transaction (too long amount_decimal)",
+			inputTransaction:   inputTransaction,
+			requestBody:        []byte(`{"user_id": 1, "amount_decimal": "1.000000000000000000000000000000000", "currency": "EUR"}`),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "amount_decimal", Tag: "max", Message: "must be at most 32 characters long"},
+			}}, "/api/transactions/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, inputTransaction *models.TransactionInput) {},
 		},
 	}
 
@@ -187,14 +281,18 @@ func TestHandler_CreateTransaction(t *testing.T) {
 
 			service := mock_services.NewMockTransactionService(controller)
 			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			idempotency_service := mock_services.NewMockIdempotencyMiddleware(controller)
+			signature_service := mock_services.NewMockSignatureMiddleware(controller)
+			rate_limit_service := mock_services.NewMockRateLimitMiddleware(controller)
 			testCase.mockBehaviour(service, testCase.inputTransaction)
 
-			handler := NewTransactionHandler(service, auth_service)
+			handler := NewTransactionHandler(service, auth_service, idempotency_service, signature_service, rate_limit_service)
 			router := mux.NewRouter()
 			router.HandleFunc("/api/transactions/", handler.CreateTransaction)
 
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("POST", "/api/transactions/", bytes.NewBuffer(testCase.requestBody))
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(testCase.principal)))
 
 			router.ServeHTTP(w, r)
 
@@ -226,7 +324,7 @@ func TestHandler_CancelTransaction(t *testing.T) {
 			requestBody:         emptyBody,
 			expectedRequestBody: string(cancelSerializedTransaction) + "\n",
 			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
-				service.EXPECT().UpdateStatus(transactionId, services.TransactionCanceledStatus).Return(canceledTransaction, nil)
+				service.EXPECT().Cancel(transactionId, ownerPrincipal).Return(canceledTransaction, nil)
 			},
 		},
 		{
@@ -234,19 +332,39 @@ func TestHandler_CancelTransaction(t *testing.T) {
 			transactionId:       transaction.Id,
 			expectedStatusCode:  http.StatusNotFound,
 			requestBody:         emptyBody,
-			expectedRequestBody: "Not Found\n",
+			expectedRequestBody: errorBody(services.ErrTransactionNotFound, "/api/transactions/1/cancel/"),
 			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
-				service.EXPECT().UpdateStatus(transactionId, services.TransactionCanceledStatus).Return(nil, errors.New(dbNotFoundErrorMsg))
+				service.EXPECT().Cancel(transactionId, ownerPrincipal).Return(nil, services.ErrTransactionNotFound)
 			},
 		},
 		{
-			name:                "Test cancel transaction (terminal status)",
+			name:               "Test cancel transaction (terminal status)",
+			transactionId:      transaction.Id,
+			expectedStatusCode: http.StatusBadRequest,
+			requestBody:        emptyBody,
+			expectedRequestBody: errorBody(&services.TransitionError{
+				From: services.TransactionSuccessStatus,
+				To:   services.TransactionCanceledStatus,
+				Role: services.RoleUser,
+				Kind: services.ErrTerminalStatus,
+			}, "/api/transactions/1/cancel/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
+				service.EXPECT().Cancel(transactionId, ownerPrincipal).Return(nil, &services.TransitionError{
+					From: services.TransactionSuccessStatus,
+					To:   services.TransactionCanceledStatus,
+					Role: services.RoleUser,
+					Kind: services.ErrTerminalStatus,
+				})
+			},
+		},
+		{
+			name:                "Test cancel transaction (conflict)",
 			transactionId:       transaction.Id,
-			expectedStatusCode:  http.StatusBadRequest,
+			expectedStatusCode:  http.StatusConflict,
 			requestBody:         emptyBody,
-			expectedRequestBody: "Can not proceed transaction with it's current status.\n",
+			expectedRequestBody: errorBody(services.ErrConcurrentUpdate, "/api/transactions/1/cancel/"),
 			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
-				service.EXPECT().UpdateStatus(transactionId, services.TransactionCanceledStatus).Return(nil, errors.New(services.TerminalStatusErrorMessage))
+				service.EXPECT().Cancel(transactionId, ownerPrincipal).Return(nil, services.ErrConcurrentUpdate)
 			},
 		},
 		{
@@ -254,9 +372,9 @@ func TestHandler_CancelTransaction(t *testing.T) {
 			transactionId:       transaction.Id,
 			expectedStatusCode:  http.StatusInternalServerError,
 			requestBody:         emptyBody,
-			expectedRequestBody: "Internal Server Error\n",
+			expectedRequestBody: errorBody(errors.New("some error"), "/api/transactions/1/cancel/"),
 			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
-				service.EXPECT().UpdateStatus(transactionId, services.TransactionCanceledStatus).Return(nil, errors.New("some error"))
+				service.EXPECT().Cancel(transactionId, ownerPrincipal).Return(nil, errors.New("some error"))
 			},
 		},
 	}
@@ -269,14 +387,277 @@ func TestHandler_CancelTransaction(t *testing.T) {
 
 			service := mock_services.NewMockTransactionService(controller)
 			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			idempotency_service := mock_services.NewMockIdempotencyMiddleware(controller)
+			signature_service := mock_services.NewMockSignatureMiddleware(controller)
+			rate_limit_service := mock_services.NewMockRateLimitMiddleware(controller)
 			testCase.mockBehaviour(service, testCase.transactionId)
 
-			handler := NewTransactionHandler(service, auth_service)
+			handler := NewTransactionHandler(service, auth_service, idempotency_service, signature_service, rate_limit_service)
 			router := mux.NewRouter()
 			router.HandleFunc(fmt.Sprintf("/api/transactions/{pk:[0-9]}/cancel/"), handler.CancelTransaction)
 
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("PUT", fmt.Sprintf("/api/transactions/%d/cancel/", testCase.transactionId), bytes.NewBuffer(testCase.requestBody))
+			r = r.WithContext(auth.NewContext(r.Context(), ownerPrincipal))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_ProceedTransaction(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockTransactionService, transactionId int)
+	successTransaction := *transaction
+	successTransaction.Status = services.TransactionSuccessStatus
+	successSerializedTransaction, _ := json.Marshal(successTransaction)
+
+	testTable := []struct {
+		name                string
+		transactionId       int
+		requestBody         []byte
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test proceed transaction (ok)",
+			transactionId:       transaction.Id,
+			requestBody:         []byte(`{"status": "SUCCESS"}`),
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(successSerializedTransaction) + "\n",
+			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
+				service.EXPECT().
+					UpdateStatus(transactionId, services.TransactionSuccessStatus, services.RoleProvider).
+					Return(&successTransaction, nil)
+			},
+		},
+		{
+			name:                "Test proceed transaction (conflict)",
+			transactionId:       transaction.Id,
+			requestBody:         []byte(`{"status": "SUCCESS"}`),
+			expectedStatusCode:  http.StatusConflict,
+			expectedRequestBody: errorBody(services.ErrConcurrentUpdate, "/api/transactions/1/proceed/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
+				service.EXPECT().
+					UpdateStatus(transactionId, services.TransactionSuccessStatus, services.RoleProvider).
+					Return(nil, fmt.Errorf("service.repo.UpdateTransactionStatus failed: %w", services.ErrConcurrentUpdate))
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockTransactionService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			idempotency_service := mock_services.NewMockIdempotencyMiddleware(controller)
+			signature_service := mock_services.NewMockSignatureMiddleware(controller)
+			rate_limit_service := mock_services.NewMockRateLimitMiddleware(controller)
+			testCase.mockBehaviour(service, testCase.transactionId)
+
+			handler := NewTransactionHandler(service, auth_service, idempotency_service, signature_service, rate_limit_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/transactions/{pk:[0-9]}/proceed/", handler.ProceedTransaction)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", fmt.Sprintf("/api/transactions/%d/proceed/", testCase.transactionId), bytes.NewBuffer(testCase.requestBody))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_SyncTransaction(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockTransactionService, transactionId int)
+	serializedTransaction, _ := json.Marshal(transaction)
+
+	testTable := []struct {
+		name                string
+		transactionId       int
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test sync transaction (ok)",
+			transactionId:       transaction.Id,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedTransaction) + "\n",
+			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
+				service.EXPECT().Sync(transactionId).Return(transaction, nil)
+			},
+		},
+		{
+			name:                "Test sync transaction (no provider)",
+			transactionId:       transaction.Id,
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedRequestBody: errorBody(services.ErrNoPaymentProvider, "/api/transactions/1/sync/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
+				service.EXPECT().Sync(transactionId).Return(nil, services.ErrNoPaymentProvider)
+			},
+		},
+		{
+			name:                "Test sync transaction (provider unavailable)",
+			transactionId:       transaction.Id,
+			expectedStatusCode:  http.StatusServiceUnavailable,
+			expectedRequestBody: errorBody(services.ErrPaymentProviderUnavailable, "/api/transactions/1/sync/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService, transactionId int) {
+				service.EXPECT().Sync(transactionId).Return(nil, services.ErrPaymentProviderUnavailable)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockTransactionService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			idempotency_service := mock_services.NewMockIdempotencyMiddleware(controller)
+			signature_service := mock_services.NewMockSignatureMiddleware(controller)
+			rate_limit_service := mock_services.NewMockRateLimitMiddleware(controller)
+			testCase.mockBehaviour(service, testCase.transactionId)
+
+			handler := NewTransactionHandler(service, auth_service, idempotency_service, signature_service, rate_limit_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/transactions/{pk:[0-9]}/sync/", handler.SyncTransaction)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", fmt.Sprintf("/api/transactions/%d/sync/", testCase.transactionId), bytes.NewBuffer(emptyBody))
+			r = r.WithContext(auth.NewContext(r.Context(), adminPrincipal))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func problemBody(body *problem.Problem, instance string) string {
+	/*Return expected problem response body.*/
+	body.Instance = instance
+	data, _ := json.Marshal(body)
+
+	return string(data) + "\n"
+}
+
+func errorBody(err error, instance string) string {
+	/*Return expected problem response body for the error.*/
+	return problemBody(problem.FromError(err), instance)
+}
+
+func testPrincipal(principal *auth.Principal) *auth.Principal {
+	/*Return principal of test case, transaction owner is used by default.*/
+	if principal == nil {
+		return ownerPrincipal
+	}
+
+	return principal
+}
+
+func TestHandler_SearchTransactions(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockTransactionService)
+	serializedPage, _ := json.Marshal(transactionPage)
+	userId := 1
+	defaultSearchFilter := &models.TransactionSearchFilter{Sort: "created_at", Order: "desc", Limit: defaultPageLimit}
+
+	testTable := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test search transactions (ok)",
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedPage) + "\n",
+			mockBehaviour: func(service *mock_services.MockTransactionService) {
+				service.EXPECT().Search(defaultSearchFilter).Return(transactionPage, nil)
+			},
+		},
+		{
+			name:                "Test search transactions (filters)",
+			query:               "?status=NEW,FAILED&status=SUCCESS&user_id=1&email=mail&sort=amount&order=asc&limit=10",
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedPage) + "\n",
+			mockBehaviour: func(service *mock_services.MockTransactionService) {
+				service.EXPECT().Search(&models.TransactionSearchFilter{
+					Statuses: []string{"NEW", "FAILED", "SUCCESS"},
+					UserId:   &userId,
+					Email:    "mail",
+					Sort:     "amount",
+					Order:    "asc",
+					Limit:    10,
+				}).Return(transactionPage, nil)
+			},
+		},
+		{
+			name:               "Test search transactions (unknown sort)",
+			query:              "?sort=user_email",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "sort", Tag: "oneof", Message: "must be one of: created_at updated_at amount"},
+			}}, "/api/transactions/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService) {},
+		},
+		{
+			name:               "Test search transactions (bad user id)",
+			query:              "?user_id=me",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "user_id", Tag: "integer", Message: "must be an integer"},
+			}}, "/api/transactions/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService) {},
+		},
+		{
+			name:                "Test search transactions (invalid cursor)",
+			query:               "?cursor=abc",
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedRequestBody: errorBody(services.ErrInvalidCursor, "/api/transactions/"),
+			mockBehaviour: func(service *mock_services.MockTransactionService) {
+				searchFilter := *defaultSearchFilter
+				searchFilter.Cursor = "abc"
+				service.EXPECT().Search(&searchFilter).Return(nil, services.ErrInvalidCursor)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockTransactionService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			testCase.mockBehaviour(service)
+
+			handler := NewTransactionHandler(service, auth_service, nil, nil, nil)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/transactions/", handler.SearchTransactions)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/transactions/"+testCase.query, bytes.NewBufferString(""))
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(adminPrincipal)))
 
 			router.ServeHTTP(w, r)
 
@@ -286,3 +667,81 @@ func TestHandler_CancelTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_AuthorizeTransactionUser(t *testing.T) {
+	// Arrange
+	serializedInputTransaction, _ := json.Marshal(inputTransaction)
+
+	testTable := []struct {
+		name               string
+		principal          *auth.Principal
+		requestBody        []byte
+		expectedCalled     bool
+		expectedStatusCode int
+	}{
+		{
+			name:               "Test owner",
+			principal:          ownerPrincipal,
+			requestBody:        serializedInputTransaction,
+			expectedCalled:     true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Test admin",
+			principal:          adminPrincipal,
+			requestBody:        serializedInputTransaction,
+			expectedCalled:     true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Test another user",
+			principal:          otherPrincipal,
+			requestBody:        serializedInputTransaction,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		// malformed body is rejected by the next handler
+		{
+			name:               "Test bad body",
+			principal:          otherPrincipal,
+			requestBody:        []byte(`{`),
+			expectedCalled:     true,
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			var called bool
+			var body []byte
+			handler := NewTransactionHandler(
+				mock_services.NewMockTransactionService(controller),
+				mock_services.NewMockAuthMiddleware(controller),
+				mock_services.NewMockIdempotencyMiddleware(controller),
+				mock_services.NewMockSignatureMiddleware(controller),
+				mock_services.NewMockRateLimitMiddleware(controller),
+			)
+			next := handler.authorizeTransactionUser(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				body, _ = io.ReadAll(r.Body)
+			})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/transactions/", bytes.NewBuffer(testCase.requestBody))
+			r = r.WithContext(auth.NewContext(r.Context(), testCase.principal))
+
+			next(w, r)
+
+			// Assert
+			// stored response must not be replayed to another user
+			assert.Equal(t, testCase.expectedCalled, called)
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			if called {
+				assert.Equal(t, testCase.requestBody, body)
+			}
+		})
+	}
+}
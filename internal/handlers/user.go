@@ -2,87 +2,263 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
+	"github.com/Pythonyan3/payment-service/internal/auth"
 	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+	"github.com/Pythonyan3/payment-service/internal/services"
 
 	"github.com/gorilla/mux"
 )
 
 type UserService interface {
-	GetUserTransactionsById(userId int) ([]*models.Transaction, error)
-	GetUserTransactionsByEmail(userEmail string) ([]*models.Transaction, error)
+	Create(userInput *models.UserInput) (*models.User, error)
+	GetById(userId int) (*models.User, error)
+	Update(userId int, userInput *models.UserInput) (*models.User, error)
+	Delete(userId int) error
+	GetUserTransactionsById(userId int, filter *models.TransactionFilter) (*models.TransactionPage, error)
+	GetUserTransactionsByEmail(userEmail string, filter *models.TransactionFilter) (*models.TransactionPage, error)
 }
 
 type UserHandler struct {
-	service UserService
+	service        UserService
+	authMiddleware AuthMiddleware
 }
 
-func NewUserHandler(service UserService) *UserHandler {
+func NewUserHandler(service UserService, authMiddleware AuthMiddleware) *UserHandler {
 	/*User routes handler constructor function.*/
-	return &UserHandler{service: service}
+	return &UserHandler{service: service, authMiddleware: authMiddleware}
 }
 
 func (handler *UserHandler) InitRoutes(router *mux.Router) {
 	/*Perform initialization of all required routes for user entity.*/
 	var subRouter *mux.Router = router.PathPrefix("/users").Subrouter()
-	subRouter.HandleFunc("/{userId:[0-9]+}/transactions/", handler.TransactionsListByUserId).Methods("GET")
-	subRouter.HandleFunc("/{userEmail}/transactions/", handler.TransactionsListByUserEmail).Methods("GET")
+	// users are created and deleted by admins only
+	var adminOnly func(http.HandlerFunc) http.HandlerFunc = handler.authMiddleware.RequireScope(auth.ScopeAdmin)
+
+	subRouter.HandleFunc("/", adminOnly(handler.CreateUser)).Methods("POST")
+	subRouter.HandleFunc(
+		"/{userId:[0-9]+}/", handler.authMiddleware.AuthMiddleware(handler.RetrieveUser),
+	).Methods("GET")
+	subRouter.HandleFunc(
+		"/{userId:[0-9]+}/", handler.authMiddleware.AuthMiddleware(handler.UpdateUser),
+	).Methods("PUT", "PATCH")
+	subRouter.HandleFunc("/{userId:[0-9]+}/", adminOnly(handler.DeleteUser)).Methods("DELETE")
+	subRouter.HandleFunc(
+		"/{userId:[0-9]+}/transactions/",
+		handler.authMiddleware.AuthMiddleware(handler.TransactionsListByUserId),
+	).Methods("GET")
+	subRouter.HandleFunc(
+		"/{userEmail}/transactions/",
+		handler.authMiddleware.AuthMiddleware(handler.TransactionsListByUserEmail),
+	).Methods("GET")
+}
+
+func (handler *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to create new user.*/
+	var err error
+	var userInput models.UserInput
+	var user *models.User
+
+	// parsing and validating request body data
+	if !parseRequestBody(w, r, &userInput) {
+		return
+	}
+
+	// create new user with a service
+	user, err = handler.service.Create(&userInput)
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (handler *UserHandler) RetrieveUser(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to retrieve user info.
+
+		Accept user PK in URL params.
+	*/
+	var err error
+	var userId int
+	var user *models.User
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve user PK from url variables
+	userId, err = strconv.Atoi(params["userId"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// only user itself and admin are allowed to see user's info
+	if err = services.AuthorizeUser(requestPrincipal(r), userId); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	user, err = handler.service.GetById(userId)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(user)
+}
+
+func (handler *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to update user info.
+
+		Accept user PK in URL params.
+	*/
+	var err error
+	var userId int
+	var userInput models.UserInput
+	var user *models.User
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve user PK from url variables
+	userId, err = strconv.Atoi(params["userId"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// only user itself and admin are allowed to update user's info
+	if err = services.AuthorizeUser(requestPrincipal(r), userId); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// parsing and validating request body data
+	if !parseRequestBody(w, r, &userInput) {
+		return
+	}
+
+	user, err = handler.service.Update(userId, &userInput)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(user)
+}
+
+func (handler *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to delete user.
+
+		Accept user PK in URL params.
+	*/
+	var err error
+	var userId int
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve user PK from url variables
+	userId, err = strconv.Atoi(params["userId"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	if err = handler.service.Delete(userId); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (handler *UserHandler) TransactionsListByUserId(w http.ResponseWriter, r *http.Request) {
 	/*
-		Handle request to retrieve list of user's transactions.
+		Handle request to retrieve page of user's transactions.
 
-		Accept user PK in URL params to perform filtering.
+		Accept user PK in URL params and filter params in URL query to perform filtering.
 	*/
 	// declare variables
 	var userId int
 	var err error
-	var transaction []*models.Transaction
+	var filter *models.TransactionFilter
+	var page *models.TransactionPage
 	var params map[string]string = mux.Vars(r)
 
 	// retrieve user PK from url variables
 	userId, err = strconv.Atoi(params["userId"])
 	if err != nil {
-		log.Printf("strconv.Atoi failed: %s", err.Error())
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// only user itself and admin are allowed to list user's transactions
+	if err = services.AuthorizeUser(requestPrincipal(r), userId); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// parse and validate filter params
+	filter, err = parseTransactionFilter(r.URL.Query())
+	if err == nil {
+		err = newValidator().Struct(filter)
+	}
+	if err != nil {
+		problem.WriteError(w, r, err)
 		return
 	}
 
 	// use service to retrieve user's transactions
-	transaction, err = handler.service.GetUserTransactionsById(userId)
+	page, err = handler.service.GetUserTransactionsById(userId, filter)
 
 	if err != nil {
-		log.Printf("handler.service.GetUserTransactionsById failed: %s", err.Error())
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(transaction)
+	json.NewEncoder(w).Encode(page)
 }
 
 func (handler *UserHandler) TransactionsListByUserEmail(w http.ResponseWriter, r *http.Request) {
 	/*
-		Handle request to retrieve list of user's transactions.
+		Handle request to retrieve page of user's transactions.
 
-		Accept user email address in URL params to perform filtering.
+		Accept user email address in URL params and filter params in URL query to perform filtering.
 	*/
 	// declare variables
 	var err error
-	var transaction []*models.Transaction
+	var filter *models.TransactionFilter
+	var page *models.TransactionPage
 	var params map[string]string = mux.Vars(r)
 
+	// only user itself (token with the same email) and admin are allowed to list user's transactions
+	if err = services.AuthorizeUserEmail(requestPrincipal(r), params["userEmail"]); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// parse and validate filter params
+	filter, err = parseTransactionFilter(r.URL.Query())
+	if err == nil {
+		err = newValidator().Struct(filter)
+	}
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
 	// use service to retrieve user's transactions
-	transaction, err = handler.service.GetUserTransactionsByEmail(params["userEmail"])
+	page, err = handler.service.GetUserTransactionsByEmail(params["userEmail"], filter)
 
 	if err != nil {
-		log.Printf("handler.service.GetUserTransactionsByEmail failed: %s", err.Error())
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		problem.WriteError(w, r, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(transaction)
+	json.NewEncoder(w).Encode(page)
 }
@@ -9,7 +9,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Pythonyan3/payment-service/internal/auth"
 	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
 	mock_services "github.com/Pythonyan3/payment-service/internal/services/mocks"
 
 	"github.com/golang/mock/gomock"
@@ -17,14 +19,34 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var (
+	defaultFilter   *models.TransactionFilter = &models.TransactionFilter{Limit: defaultPageLimit}
+	transactionPage *models.TransactionPage   = &models.TransactionPage{
+		Items:      transactionSlice,
+		Limit:      defaultPageLimit,
+		NextCursor: (&models.TransactionCursor{CreatedAt: transaction.CreatedAt, Id: transaction.Id}).Encode(),
+	}
+	emptyTransactionPage *models.TransactionPage = &models.TransactionPage{
+		Items: []*models.Transaction{},
+		Limit: defaultPageLimit,
+	}
+)
+
 func TestHandler_TransactionsListByUserId(t *testing.T) {
 	// Arrange
 	type mockBehaviour func(service *mock_services.MockUserService, userId int)
-	serializedTransactions, _ := json.Marshal(transactionSlice)
+	serializedPage, _ := json.Marshal(transactionPage)
+	serializedEmptyPage, _ := json.Marshal(emptyTransactionPage)
+	amountMin := int64(100)
+	statusFilter := &models.TransactionFilter{
+		Status: services.TransactionNewStatus, AmountMin: &amountMin, Limit: 10, Cursor: transactionPage.NextCursor,
+	}
 
 	testTable := []struct {
 		name                string
 		userId              int
+		query               string
+		principal           *auth.Principal
 		mockBehaviour       mockBehaviour
 		expectedStatusCode  int
 		expectedRequestBody string
@@ -33,27 +55,75 @@ func TestHandler_TransactionsListByUserId(t *testing.T) {
 			name:                "Test list of transactions (ok)",
 			userId:              transaction.UserId,
 			expectedStatusCode:  http.StatusOK,
-			expectedRequestBody: string(serializedTransactions) + "\n",
+			expectedRequestBody: string(serializedPage) + "\n",
 			mockBehaviour: func(service *mock_services.MockUserService, userId int) {
-				service.EXPECT().GetUserTransactionsById(userId).Return(transactionSlice, nil)
+				service.EXPECT().GetUserTransactionsById(userId, defaultFilter).Return(transactionPage, nil)
+			},
+		},
+		{
+			name:                "Test list of transactions (filtered)",
+			userId:              transaction.UserId,
+			query:               "?status=NEW&amount_min=100&limit=10&cursor=" + transactionPage.NextCursor,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedPage) + "\n",
+			mockBehaviour: func(service *mock_services.MockUserService, userId int) {
+				service.EXPECT().GetUserTransactionsById(userId, statusFilter).Return(transactionPage, nil)
 			},
 		},
 		{
 			name:                "Test list of transactions (empty)",
 			userId:              transaction.UserId,
 			expectedStatusCode:  http.StatusOK,
-			expectedRequestBody: "[]\n",
+			expectedRequestBody: string(serializedEmptyPage) + "\n",
 			mockBehaviour: func(service *mock_services.MockUserService, userId int) {
-				service.EXPECT().GetUserTransactionsById(userId).Return([]*models.Transaction{}, nil)
+				service.EXPECT().GetUserTransactionsById(userId, defaultFilter).Return(emptyTransactionPage, nil)
+			},
+		},
+		{
+			name:               "Test list of transactions (bad filter)",
+			userId:             transaction.UserId,
+			query:              "?limit=1000",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "limit", Tag: "lte", Message: "must be less than or equal to 100"},
+			}}, "/api/users/1/transactions/"),
+			mockBehaviour: func(service *mock_services.MockUserService, userId int) {},
+		},
+		{
+			name:                "Test list of transactions (bad cursor)",
+			userId:              transaction.UserId,
+			query:               "?cursor=bad",
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedRequestBody: errorBody(services.ErrInvalidCursor, "/api/users/1/transactions/"),
+			mockBehaviour: func(service *mock_services.MockUserService, userId int) {
+				service.EXPECT().GetUserTransactionsById(userId, gomock.Any()).Return(nil, services.ErrInvalidCursor)
+			},
+		},
+		{
+			name:                "Test list of transactions (another user)",
+			userId:              transaction.UserId,
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrUserAccessDenied, "/api/users/1/transactions/"),
+			mockBehaviour:       func(service *mock_services.MockUserService, userId int) {},
+		},
+		{
+			name:                "Test list of transactions (admin)",
+			userId:              transaction.UserId,
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedEmptyPage) + "\n",
+			mockBehaviour: func(service *mock_services.MockUserService, userId int) {
+				service.EXPECT().GetUserTransactionsById(userId, defaultFilter).Return(emptyTransactionPage, nil)
 			},
 		},
 		{
 			name:                "Test list of transactions (service error)",
 			userId:              transaction.UserId,
 			expectedStatusCode:  http.StatusInternalServerError,
-			expectedRequestBody: "Internal Server Error\n",
+			expectedRequestBody: errorBody(errors.New("some error"), "/api/users/1/transactions/"),
 			mockBehaviour: func(service *mock_services.MockUserService, userId int) {
-				service.EXPECT().GetUserTransactionsById(userId).Return(nil, errors.New("some error"))
+				service.EXPECT().GetUserTransactionsById(userId, defaultFilter).Return(nil, errors.New("some error"))
 			},
 		},
 	}
@@ -65,16 +135,18 @@ func TestHandler_TransactionsListByUserId(t *testing.T) {
 			defer controller.Finish()
 
 			service := mock_services.NewMockUserService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
 			testCase.mockBehaviour(service, testCase.userId)
 
-			handler := NewUserHandler(service)
+			handler := NewUserHandler(service, auth_service)
 			router := mux.NewRouter()
 			router.HandleFunc("/api/users/{userId:[0-9]+}/transactions/", handler.TransactionsListByUserId)
 
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(
-				"GET", fmt.Sprintf("/api/users/%d/transactions/", testCase.userId), bytes.NewBufferString(""),
+				"GET", fmt.Sprintf("/api/users/%d/transactions/%s", testCase.userId, testCase.query), bytes.NewBufferString(""),
 			)
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(testCase.principal)))
 
 			router.ServeHTTP(w, r)
 
@@ -88,11 +160,13 @@ func TestHandler_TransactionsListByUserId(t *testing.T) {
 func TestHandler_TransactionsListByUserEmail(t *testing.T) {
 	// Arrange
 	type mockBehaviour func(service *mock_services.MockUserService, userEmail string)
-	serializedTransactions, _ := json.Marshal(transactionSlice)
+	serializedPage, _ := json.Marshal(transactionPage)
+	serializedEmptyPage, _ := json.Marshal(emptyTransactionPage)
 
 	testTable := []struct {
 		name                string
 		userEmail           string
+		principal           *auth.Principal
 		mockBehaviour       mockBehaviour
 		expectedStatusCode  int
 		expectedRequestBody string
@@ -101,27 +175,35 @@ func TestHandler_TransactionsListByUserEmail(t *testing.T) {
 			name:                "Test list of transactions (ok)",
 			userEmail:           transaction.UserEmail,
 			expectedStatusCode:  http.StatusOK,
-			expectedRequestBody: string(serializedTransactions) + "\n",
+			expectedRequestBody: string(serializedPage) + "\n",
 			mockBehaviour: func(service *mock_services.MockUserService, userEmail string) {
-				service.EXPECT().GetUserTransactionsByEmail(userEmail).Return(transactionSlice, nil)
+				service.EXPECT().GetUserTransactionsByEmail(userEmail, defaultFilter).Return(transactionPage, nil)
 			},
 		},
 		{
 			name:                "Test list of transactions (empty)",
 			userEmail:           transaction.UserEmail,
 			expectedStatusCode:  http.StatusOK,
-			expectedRequestBody: "[]\n",
+			expectedRequestBody: string(serializedEmptyPage) + "\n",
 			mockBehaviour: func(service *mock_services.MockUserService, userEmail string) {
-				service.EXPECT().GetUserTransactionsByEmail(userEmail).Return([]*models.Transaction{}, nil)
+				service.EXPECT().GetUserTransactionsByEmail(userEmail, defaultFilter).Return(emptyTransactionPage, nil)
 			},
 		},
+		{
+			name:                "Test list of transactions (another user)",
+			userEmail:           transaction.UserEmail,
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrUserAccessDenied, "/api/users/email@mail.ru/transactions/"),
+			mockBehaviour:       func(service *mock_services.MockUserService, userEmail string) {},
+		},
 		{
 			name:                "Test list of transactions (service error)",
 			userEmail:           transaction.UserEmail,
 			expectedStatusCode:  http.StatusInternalServerError,
-			expectedRequestBody: "Internal Server Error\n",
+			expectedRequestBody: errorBody(errors.New("some error"), "/api/users/email@mail.ru/transactions/"),
 			mockBehaviour: func(service *mock_services.MockUserService, userEmail string) {
-				service.EXPECT().GetUserTransactionsByEmail(userEmail).Return(nil, errors.New("some error"))
+				service.EXPECT().GetUserTransactionsByEmail(userEmail, defaultFilter).Return(nil, errors.New("some error"))
 			},
 		},
 	}
@@ -133,9 +215,10 @@ func TestHandler_TransactionsListByUserEmail(t *testing.T) {
 			defer controller.Finish()
 
 			service := mock_services.NewMockUserService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
 			testCase.mockBehaviour(service, testCase.userEmail)
 
-			handler := NewUserHandler(service)
+			handler := NewUserHandler(service, auth_service)
 			router := mux.NewRouter()
 			router.HandleFunc("/api/users/{userEmail}/transactions/", handler.TransactionsListByUserEmail)
 
@@ -143,6 +226,205 @@ func TestHandler_TransactionsListByUserEmail(t *testing.T) {
 			r := httptest.NewRequest(
 				"GET", fmt.Sprintf("/api/users/%s/transactions/", testCase.userEmail), bytes.NewBufferString(""),
 			)
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(testCase.principal)))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_CreateUser(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockUserService, userInput *models.UserInput)
+	user := &models.User{Id: 1, Email: "email@mail.ru", CreatedAt: currentTime, UpdatedAt: currentTime}
+	userInput := &models.UserInput{Email: user.Email}
+	serializedUser, _ := json.Marshal(user)
+
+	testTable := []struct {
+		name                string
+		requestBody         string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test create user (ok)",
+			requestBody:         `{"email": "email@mail.ru"}`,
+			expectedStatusCode:  http.StatusCreated,
+			expectedRequestBody: string(serializedUser) + "\n",
+			mockBehaviour: func(service *mock_services.MockUserService, userInput *models.UserInput) {
+				service.EXPECT().Create(userInput).Return(user, nil)
+			},
+		},
+		{
+			name:               "Test create user (bad email)",
+			requestBody:        `{"email": "not email"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "email", Tag: "email", Message: "must be a valid email address"},
+			}}, "/api/users/"),
+			mockBehaviour: func(service *mock_services.MockUserService, userInput *models.UserInput) {},
+		},
+		{
+			name:                "Test create user (email taken)",
+			requestBody:         `{"email": "email@mail.ru"}`,
+			expectedStatusCode:  http.StatusConflict,
+			expectedRequestBody: errorBody(services.ErrUserEmailTaken, "/api/users/"),
+			mockBehaviour: func(service *mock_services.MockUserService, userInput *models.UserInput) {
+				service.EXPECT().Create(userInput).Return(nil, services.ErrUserEmailTaken)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockUserService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			testCase.mockBehaviour(service, userInput)
+
+			handler := NewUserHandler(service, auth_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/users/", handler.CreateUser)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/users/", bytes.NewBufferString(testCase.requestBody))
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(adminPrincipal)))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_UpdateUser(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockUserService, userId int, userInput *models.UserInput)
+	user := &models.User{Id: 1, Email: "new@mail.ru", Name: "Name", CreatedAt: currentTime, UpdatedAt: currentTime}
+	userInput := &models.UserInput{Email: user.Email, Name: user.Name}
+	serializedUser, _ := json.Marshal(user)
+
+	testTable := []struct {
+		name                string
+		userId              int
+		principal           *auth.Principal
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test update user (ok)",
+			userId:              1,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedUser) + "\n",
+			mockBehaviour: func(service *mock_services.MockUserService, userId int, userInput *models.UserInput) {
+				service.EXPECT().Update(userId, userInput).Return(user, nil)
+			},
+		},
+		{
+			name:                "Test update user (another user)",
+			userId:              1,
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrUserAccessDenied, "/api/users/1/"),
+			mockBehaviour:       func(service *mock_services.MockUserService, userId int, userInput *models.UserInput) {},
+		},
+		{
+			name:                "Test update user (not found)",
+			userId:              3,
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusNotFound,
+			expectedRequestBody: errorBody(services.ErrUserNotFound, "/api/users/3/"),
+			mockBehaviour: func(service *mock_services.MockUserService, userId int, userInput *models.UserInput) {
+				service.EXPECT().Update(userId, userInput).Return(nil, services.ErrUserNotFound)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockUserService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			testCase.mockBehaviour(service, testCase.userId, userInput)
+
+			handler := NewUserHandler(service, auth_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/users/{userId:[0-9]+}/", handler.UpdateUser)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(
+				"PUT",
+				fmt.Sprintf("/api/users/%d/", testCase.userId),
+				bytes.NewBufferString(`{"email": "new@mail.ru", "name": "Name"}`),
+			)
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(testCase.principal)))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_DeleteUser(t *testing.T) {
+	// Arrange
+	testTable := []struct {
+		name                string
+		serviceErr          error
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:               "Test delete user (ok)",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:                "Test delete user (in use)",
+			serviceErr:          services.ErrUserInUse,
+			expectedStatusCode:  http.StatusConflict,
+			expectedRequestBody: errorBody(services.ErrUserInUse, "/api/users/1/"),
+		},
+		{
+			name:                "Test delete user (service error)",
+			serviceErr:          errors.New("some error"),
+			expectedStatusCode:  http.StatusInternalServerError,
+			expectedRequestBody: errorBody(errors.New("some error"), "/api/users/1/"),
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockUserService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			service.EXPECT().Delete(1).Return(testCase.serviceErr)
+
+			handler := NewUserHandler(service, auth_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/users/{userId:[0-9]+}/", handler.DeleteUser)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "/api/users/1/", bytes.NewBufferString(""))
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(adminPrincipal)))
 
 			router.ServeHTTP(w, r)
 
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/money"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+type WalletService interface {
+	List(userId int) ([]*models.Wallet, error)
+	Deposit(userId int, currency string, depositInput *models.WalletDepositInput) (*models.Wallet, error)
+}
+
+type WalletHandler struct {
+	service        WalletService
+	authMiddleware AuthMiddleware
+}
+
+func NewWalletHandler(service WalletService, authMiddleware AuthMiddleware) *WalletHandler {
+	/*Wallet routes handler constructor function.*/
+	return &WalletHandler{service: service, authMiddleware: authMiddleware}
+}
+
+func (handler *WalletHandler) InitRoutes(router *mux.Router) {
+	/*Perform initialization of all required routes for user wallets.*/
+	var subRouter *mux.Router = router.PathPrefix("/users").Subrouter()
+	// funds are deposited by admins only
+	var adminOnly func(http.HandlerFunc) http.HandlerFunc = handler.authMiddleware.RequireScope(auth.ScopeAdmin)
+
+	subRouter.HandleFunc(
+		"/{userId:[0-9]+}/wallets/", handler.authMiddleware.AuthMiddleware(handler.WalletsByUserId),
+	).Methods("GET")
+	subRouter.HandleFunc("/{userId:[0-9]+}/wallets/{currency}/deposits/", adminOnly(handler.Deposit)).Methods("POST")
+}
+
+func (handler *WalletHandler) WalletsByUserId(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to retrieve list of user's wallets.
+
+		Accept user PK in URL params.
+	*/
+	var err error
+	var userId int
+	var wallets []*models.Wallet
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve user PK from url variables
+	userId, err = strconv.Atoi(params["userId"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	// only user itself and admin are allowed to see user's wallets
+	if err = services.AuthorizeUser(requestPrincipal(r), userId); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	wallets, err = handler.service.List(userId)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(wallets)
+}
+
+func (handler *WalletHandler) Deposit(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to add funds to user's wallet.
+
+		Accept user PK and wallet currency in URL params.
+	*/
+	var err error
+	var userId int
+	var depositInput models.WalletDepositInput
+	var wallet *models.Wallet
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve user PK from url variables
+	userId, err = strconv.Atoi(params["userId"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	if !money.IsCurrency(params["currency"]) {
+		problem.WriteError(w, r, newParamError("currency", "currency", "must be a supported ISO 4217 currency code"))
+		return
+	}
+
+	// parsing and validating request body data
+	if !parseRequestBody(w, r, &depositInput) {
+		return
+	}
+
+	wallet, err = handler.service.Deposit(userId, params["currency"], &depositInput)
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(wallet)
+}
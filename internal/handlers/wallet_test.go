@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+	mock_services "github.com/Pythonyan3/payment-service/internal/services/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+var wallet = &models.Wallet{Id: 1, UserId: 1, Currency: "EUR", Available: 1000, Held: 500}
+
+func TestHandler_WalletsByUserId(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockWalletService, userId int)
+	serializedWallets, _ := json.Marshal([]*models.Wallet{wallet})
+
+	testTable := []struct {
+		name                string
+		userId              int
+		principal           *auth.Principal
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test user wallets (ok)",
+			userId:              1,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedWallets) + "\n",
+			mockBehaviour: func(service *mock_services.MockWalletService, userId int) {
+				service.EXPECT().List(userId).Return([]*models.Wallet{wallet}, nil)
+			},
+		},
+		{
+			name:                "Test user wallets (admin)",
+			userId:              1,
+			principal:           adminPrincipal,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedWallets) + "\n",
+			mockBehaviour: func(service *mock_services.MockWalletService, userId int) {
+				service.EXPECT().List(userId).Return([]*models.Wallet{wallet}, nil)
+			},
+		},
+		{
+			name:                "Test user wallets (another user)",
+			userId:              1,
+			principal:           otherPrincipal,
+			expectedStatusCode:  http.StatusForbidden,
+			expectedRequestBody: errorBody(services.ErrUserAccessDenied, "/api/users/1/wallets/"),
+			mockBehaviour:       func(service *mock_services.MockWalletService, userId int) {},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockWalletService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			testCase.mockBehaviour(service, testCase.userId)
+
+			handler := NewWalletHandler(service, auth_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/users/{userId:[0-9]+}/wallets/", handler.WalletsByUserId)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(
+				"GET", fmt.Sprintf("/api/users/%d/wallets/", testCase.userId), bytes.NewBufferString(""))
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(testCase.principal)))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_Deposit(t *testing.T) {
+	// Arrange
+	type mockBehaviour func(service *mock_services.MockWalletService, currency string)
+	depositInput := &models.WalletDepositInput{Amount: 1000}
+	serializedWallet, _ := json.Marshal(wallet)
+
+	testTable := []struct {
+		name                string
+		currency            string
+		requestBody         string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:                "Test deposit (ok)",
+			currency:            "EUR",
+			requestBody:         `{"amount": 1000}`,
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(serializedWallet) + "\n",
+			mockBehaviour: func(service *mock_services.MockWalletService, currency string) {
+				service.EXPECT().Deposit(1, currency, depositInput).Return(wallet, nil)
+			},
+		},
+		{
+			name:               "Test deposit (unsupported currency)",
+			currency:           "XXY",
+			requestBody:        `{"amount": 1000}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "currency", Tag: "currency", Message: "must be a supported ISO 4217 currency code"},
+			}}, "/api/users/1/wallets/XXY/deposits/"),
+			mockBehaviour: func(service *mock_services.MockWalletService, currency string) {},
+		},
+		{
+			name:               "Test deposit (negative amount)",
+			currency:           "EUR",
+			requestBody:        `{"amount": -5}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: errorBody(&services.ValidationError{Fields: []services.FieldError{
+				{Field: "amount", Tag: "gt", Message: "must be greater than 0"},
+			}}, "/api/users/1/wallets/EUR/deposits/"),
+			mockBehaviour: func(service *mock_services.MockWalletService, currency string) {},
+		},
+		{
+			name:                "Test deposit (balance exceeded)",
+			currency:            "EUR",
+			requestBody:         `{"amount": 1000}`,
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedRequestBody: errorBody(services.ErrWalletBalanceExceeded, "/api/users/1/wallets/EUR/deposits/"),
+			mockBehaviour: func(service *mock_services.MockWalletService, currency string) {
+				service.EXPECT().Deposit(1, currency, depositInput).Return(nil, services.ErrWalletBalanceExceeded)
+			},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			service := mock_services.NewMockWalletService(controller)
+			auth_service := mock_services.NewMockAuthMiddleware(controller)
+			testCase.mockBehaviour(service, testCase.currency)
+
+			handler := NewWalletHandler(service, auth_service)
+			router := mux.NewRouter()
+			router.HandleFunc("/api/users/{userId:[0-9]+}/wallets/{currency}/deposits/", handler.Deposit)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(
+				"POST",
+				fmt.Sprintf("/api/users/1/wallets/%s/deposits/", testCase.currency),
+				bytes.NewBufferString(testCase.requestBody),
+			)
+			r = r.WithContext(auth.NewContext(r.Context(), testPrincipal(adminPrincipal)))
+
+			router.ServeHTTP(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
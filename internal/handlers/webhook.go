@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+
+	"github.com/gorilla/mux"
+)
+
+type WebhookService interface {
+	Create(endpointInput *models.WebhookEndpointInput, principal *auth.Principal) (*models.WebhookEndpoint, error)
+	List(principal *auth.Principal) ([]*models.WebhookEndpoint, error)
+	Deactivate(endpointId int, principal *auth.Principal) error
+}
+
+type WebhookHandler struct {
+	service        WebhookService
+	authMiddleware AuthMiddleware
+}
+
+func NewWebhookHandler(service WebhookService, authMiddleware AuthMiddleware) *WebhookHandler {
+	/*Webhook endpoints routes handler constructor function.*/
+	return &WebhookHandler{service: service, authMiddleware: authMiddleware}
+}
+
+func (handler *WebhookHandler) InitRoutes(router *mux.Router) {
+	/*Perform initialization of all required routes for webhook endpoint entity.*/
+	var subRouter *mux.Router = router.PathPrefix("/webhooks").Subrouter()
+	// webhook endpoints are managed by merchants (own endpoints only) and admins
+	var authenticated func(http.HandlerFunc) http.HandlerFunc = handler.authMiddleware.AuthMiddleware
+
+	subRouter.HandleFunc("/", authenticated(handler.CreateWebhookEndpoint)).Methods("POST")
+	subRouter.HandleFunc("/", authenticated(handler.WebhookEndpointsList)).Methods("GET")
+	subRouter.HandleFunc("/{pk:[0-9]+}/", authenticated(handler.DeleteWebhookEndpoint)).Methods("DELETE")
+}
+
+func (handler *WebhookHandler) CreateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to register new webhook endpoint.*/
+	var err error
+	var endpoint *models.WebhookEndpoint
+	var endpointInput models.WebhookEndpointInput
+
+	// parsing and validating request body data
+	if !parseRequestBody(w, r, &endpointInput) {
+		return
+	}
+
+	// register new endpoint with a service
+	endpoint, err = handler.service.Create(&endpointInput, requestPrincipal(r))
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(endpoint)
+}
+
+func (handler *WebhookHandler) WebhookEndpointsList(w http.ResponseWriter, r *http.Request) {
+	/*Handle request to retrieve list of active webhook endpoints.*/
+	endpoints, err := handler.service.List(requestPrincipal(r))
+
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(endpoints)
+}
+
+func (handler *WebhookHandler) DeleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	/*
+		Handle request to deactivate webhook endpoint.
+
+		Accept endpoint PK in URL params.
+	*/
+	var err error
+	var endpointId int
+	var params map[string]string = mux.Vars(r)
+
+	// retrieve endpoint PK from URL variables
+	endpointId, err = strconv.Atoi(params["pk"])
+	if err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	if err = handler.service.Deactivate(endpointId, requestPrincipal(r)); err != nil {
+		problem.WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
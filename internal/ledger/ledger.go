@@ -0,0 +1,124 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+)
+
+const (
+	// Kinds of journal entries
+	EntryAuthorize string = "authorize"
+	EntryCapture   string = "capture"
+	EntryRelease   string = "release"
+	EntryRefund    string = "refund"
+	EntryDeposit   string = "deposit"
+
+	// Authorized, but not captured yet transactions amounts
+	HoldsAccount string = "holds"
+	// Captured transactions amounts
+	RevenueAccount string = "revenue"
+	// Funds deposited to users wallets
+	DepositsAccount string = "deposits"
+)
+
+var (
+	ErrEmptyEntry      = errors.New("ledger entry must have at least two postings")
+	ErrZeroPosting     = errors.New("ledger posting amount must not be zero")
+	ErrUnbalancedEntry = errors.New("ledger entry postings are not balanced")
+)
+
+func UserAccount(userId int) string {
+	/*Return code of account of the user (payer) funds.*/
+	return fmt.Sprintf("user:%d", userId)
+}
+
+func TransactionEntry(transaction *models.Transaction) *models.LedgerEntry {
+	/*
+		Return journal entry for transaction which status was assigned, nil if status does not move funds.
+
+		NEW authorizes (holds) user funds, SUCCESS captures held funds, FAILED, CANCELED and EXPIRED release them.
+	*/
+	var user string = UserAccount(transaction.UserId)
+
+	switch transaction.Status {
+	case services.TransactionNewStatus:
+		return newEntry(EntryAuthorize, transaction, nil, transaction.Amount, user, HoldsAccount)
+	case services.TransactionSuccessStatus:
+		return newEntry(EntryCapture, transaction, nil, transaction.Amount, HoldsAccount, RevenueAccount)
+	case services.TransactionFailedStatus, services.TransactionCanceledStatus, services.TransactionExpiredStatus:
+		return newEntry(EntryRelease, transaction, nil, transaction.Amount, HoldsAccount, user)
+	default:
+		return nil
+	}
+}
+
+func RefundEntry(transaction *models.Transaction, refund *models.Refund) *models.LedgerEntry {
+	/*Return journal entry for successful refund, refunded amount is returned from revenue to the user.*/
+	return newEntry(EntryRefund, transaction, &refund.Id, refund.Amount, RevenueAccount, UserAccount(transaction.UserId))
+}
+
+func DepositEntry(wallet *models.Wallet, amount int64) *models.LedgerEntry {
+	/*Return journal entry for deposit of funds to user wallet.*/
+	var walletId int = wallet.Id
+
+	return &models.LedgerEntry{
+		Kind:     EntryDeposit,
+		WalletId: &walletId,
+		Postings: []*models.LedgerPosting{
+			{AccountCode: DepositsAccount, Currency: wallet.Currency, Amount: amount},
+			{AccountCode: UserAccount(wallet.UserId), Currency: wallet.Currency, Amount: -amount},
+		},
+	}
+}
+
+func Validate(entry *models.LedgerEntry) error {
+	/*Check entry has non-zero postings which sum is zero for every currency.*/
+	var sums map[string]int64 = map[string]int64{}
+
+	if len(entry.Postings) < 2 {
+		return ErrEmptyEntry
+	}
+
+	for _, posting := range entry.Postings {
+		if posting.Amount == 0 {
+			return ErrZeroPosting
+		}
+
+		// overflowed sum could wrap around to zero, such entry is not accepted
+		sum := sums[posting.Currency]
+		if (posting.Amount > 0 && sum > math.MaxInt64-posting.Amount) ||
+			(posting.Amount < 0 && sum < math.MinInt64-posting.Amount) {
+			return ErrUnbalancedEntry
+		}
+		sums[posting.Currency] = sum + posting.Amount
+	}
+
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedEntry
+		}
+	}
+
+	return nil
+}
+
+func newEntry(
+	kind string, transaction *models.Transaction, refundId *int, amount int64, debit string, credit string,
+) *models.LedgerEntry {
+	/*Build entry which moves amount of transaction currency from credit account to debit account.*/
+	var transactionId int = transaction.Id
+
+	return &models.LedgerEntry{
+		Kind:          kind,
+		TransactionId: &transactionId,
+		RefundId:      refundId,
+		Postings: []*models.LedgerPosting{
+			{AccountCode: debit, Currency: transaction.Currency, Amount: amount},
+			{AccountCode: credit, Currency: transaction.Currency, Amount: -amount},
+		},
+	}
+}
@@ -0,0 +1,161 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionEntry(t *testing.T) {
+	testTable := []struct {
+		name           string
+		status         string
+		expectedKind   string
+		expectedDebit  string
+		expectedCredit string
+	}{
+		{
+			name:           "Test NEW transaction authorizes amount",
+			status:         services.TransactionNewStatus,
+			expectedKind:   EntryAuthorize,
+			expectedDebit:  "user:1",
+			expectedCredit: HoldsAccount,
+		},
+		{
+			name:           "Test SUCCESS transaction captures amount",
+			status:         services.TransactionSuccessStatus,
+			expectedKind:   EntryCapture,
+			expectedDebit:  HoldsAccount,
+			expectedCredit: RevenueAccount,
+		},
+		{
+			name:           "Test FAILED transaction releases amount",
+			status:         services.TransactionFailedStatus,
+			expectedKind:   EntryRelease,
+			expectedDebit:  HoldsAccount,
+			expectedCredit: "user:1",
+		},
+		{
+			name:           "Test CANCELED transaction releases amount",
+			status:         services.TransactionCanceledStatus,
+			expectedKind:   EntryRelease,
+			expectedDebit:  HoldsAccount,
+			expectedCredit: "user:1",
+		},
+		{
+			name:           "Test EXPIRED transaction releases amount",
+			status:         services.TransactionExpiredStatus,
+			expectedKind:   EntryRelease,
+			expectedDebit:  HoldsAccount,
+			expectedCredit: "user:1",
+		},
+		{name: "Test ERROR transaction does not move funds", status: services.TransactionErrorStatus},
+		{name: "Test REFUNDED transaction does not move funds", status: services.TransactionRefundedStatus},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			transaction := &models.Transaction{Id: 10, UserId: 1, Amount: 1500, Currency: "EUR", Status: testCase.status}
+
+			entry := TransactionEntry(transaction)
+
+			if testCase.expectedKind == "" {
+				assert.Nil(t, entry)
+				return
+			}
+			assert.NoError(t, Validate(entry))
+			assert.Equal(t, testCase.expectedKind, entry.Kind)
+			assert.Equal(t, 10, *entry.TransactionId)
+			assert.Equal(t, []*models.LedgerPosting{
+				{AccountCode: testCase.expectedDebit, Currency: "EUR", Amount: 1500},
+				{AccountCode: testCase.expectedCredit, Currency: "EUR", Amount: -1500},
+			}, entry.Postings)
+		})
+	}
+}
+
+func TestRefundEntry(t *testing.T) {
+	transaction := &models.Transaction{Id: 10, UserId: 1, Amount: 1500, Currency: "EUR"}
+
+	entry := RefundEntry(transaction, &models.Refund{Id: 3, TransactionId: 10, Amount: 500})
+
+	assert.NoError(t, Validate(entry))
+	assert.Equal(t, EntryRefund, entry.Kind)
+	assert.Equal(t, 3, *entry.RefundId)
+	assert.Equal(t, []*models.LedgerPosting{
+		{AccountCode: RevenueAccount, Currency: "EUR", Amount: 500},
+		{AccountCode: "user:1", Currency: "EUR", Amount: -500},
+	}, entry.Postings)
+}
+
+func TestDepositEntry(t *testing.T) {
+	entry := DepositEntry(&models.Wallet{Id: 7, UserId: 1, Currency: "EUR"}, 2000)
+
+	assert.NoError(t, Validate(entry))
+	assert.Equal(t, EntryDeposit, entry.Kind)
+	assert.Equal(t, 7, *entry.WalletId)
+	assert.Nil(t, entry.TransactionId)
+	assert.Equal(t, []*models.LedgerPosting{
+		{AccountCode: DepositsAccount, Currency: "EUR", Amount: 2000},
+		{AccountCode: "user:1", Currency: "EUR", Amount: -2000},
+	}, entry.Postings)
+}
+
+func TestValidate(t *testing.T) {
+	testTable := []struct {
+		name        string
+		postings    []*models.LedgerPosting
+		expectedErr error
+	}{
+		{
+			name: "Test balanced multi-currency entry",
+			postings: []*models.LedgerPosting{
+				{AccountCode: "a", Currency: "EUR", Amount: 100},
+				{AccountCode: "b", Currency: "EUR", Amount: -100},
+				{AccountCode: "a", Currency: "USD", Amount: 110},
+				{AccountCode: "b", Currency: "USD", Amount: -60},
+				{AccountCode: "c", Currency: "USD", Amount: -50},
+			},
+		},
+		{
+			name: "Test entry balanced in total but not per currency",
+			postings: []*models.LedgerPosting{
+				{AccountCode: "a", Currency: "EUR", Amount: 100},
+				{AccountCode: "b", Currency: "USD", Amount: -100},
+			},
+			expectedErr: ErrUnbalancedEntry,
+		},
+		{
+			name: "Test unbalanced entry",
+			postings: []*models.LedgerPosting{
+				{AccountCode: "a", Currency: "EUR", Amount: 100},
+				{AccountCode: "b", Currency: "EUR", Amount: -99},
+			},
+			expectedErr: ErrUnbalancedEntry,
+		},
+		{
+			name: "Test entry with zero posting",
+			postings: []*models.LedgerPosting{
+				{AccountCode: "a", Currency: "EUR", Amount: 0},
+				{AccountCode: "b", Currency: "EUR", Amount: 0},
+			},
+			expectedErr: ErrZeroPosting,
+		},
+		{
+			name:        "Test entry with one posting",
+			postings:    []*models.LedgerPosting{{AccountCode: "a", Currency: "EUR", Amount: 100}},
+			expectedErr: ErrEmptyEntry,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := Validate(&models.LedgerEntry{Postings: testCase.postings})
+
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
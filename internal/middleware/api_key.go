@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+type ApiKeyService interface {
+	Authenticate(key string) (*auth.Principal, error)
+}
+
+type ApiKeyMiddleware struct {
+	service ApiKeyService
+}
+
+func NewApiKeyMiddleware(service ApiKeyService) *ApiKeyMiddleware {
+	/*ApiKeyMiddleware constructor function.*/
+	return &ApiKeyMiddleware{service: service}
+}
+
+func (m *ApiKeyMiddleware) ApiKeyMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	/*HTTP middleware wrapper function, authenticates request by X-API-Key header.*/
+	return func(w http.ResponseWriter, r *http.Request) {
+		var key string = r.Header.Get(apiKeyHeader)
+
+		if key == "" {
+			log.Println("ApiKeyMiddleware: Got empty API key header.")
+			writeUnauthorized(w, r)
+			return
+		}
+
+		principal, err := m.service.Authenticate(key)
+		if err != nil {
+			// unknown and revoked keys are reported as HTTP 401, other errors as HTTP 500
+			problem.WriteError(w, r, err)
+			return
+		}
+
+		next(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	}
+}
+
+func (m *ApiKeyMiddleware) RequireScope(scopes ...string) func(http.HandlerFunc) http.HandlerFunc {
+	/*Return HTTP middleware wrapper function which allows only API keys with at least one of the scopes.*/
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return m.ApiKeyMiddleware(requireScope(scopes, next))
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+// in-memory ApiKeyService implementation
+type apiKeyServiceStub struct {
+	principals map[string]*auth.Principal
+}
+
+func (stub *apiKeyServiceStub) Authenticate(key string) (*auth.Principal, error) {
+	principal, ok := stub.principals[key]
+	if !ok {
+		return nil, services.ErrInvalidApiKey
+	}
+	return principal, nil
+}
+
+func TestCredentialsMiddleware_RequireScope(t *testing.T) {
+	// Arrange
+	apiKeys := &apiKeyServiceStub{principals: map[string]*auth.Principal{
+		"psk_provider": {Subject: "provider-1", Scopes: []string{"payment-provider"}},
+		"psk_reporter": {Subject: "reporter", Scopes: []string{"reports"}},
+	}}
+	providerToken := signTestToken(t, &tokenClaims{
+		StandardClaims: jwt.StandardClaims{Subject: "provider-2", ExpiresAt: time.Now().Add(time.Hour).Unix()},
+		Scope:          "payment-provider",
+	})
+
+	testTable := []struct {
+		name               string
+		apiKey             string
+		token              string
+		expectedStatusCode int
+		expectedSubject    string
+	}{
+		{
+			name:               "Test API key with required scope (ok)",
+			apiKey:             "psk_provider",
+			expectedStatusCode: http.StatusOK,
+			expectedSubject:    "provider-1",
+		},
+		{
+			name:               "Test API key without required scope",
+			apiKey:             "psk_reporter",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "Test unknown API key",
+			apiKey:             "psk_unknown",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test unknown API key with valid token",
+			apiKey:             "psk_unknown",
+			token:              providerToken,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test bearer token (ok)",
+			token:              providerToken,
+			expectedStatusCode: http.StatusOK,
+			expectedSubject:    "provider-2",
+		},
+		{
+			name:               "Test without credentials",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var subject string
+
+			m := NewCredentialsMiddleware(
+				NewAuthMiddleware(testSigningKey, nil, "", ""), NewApiKeyMiddleware(apiKeys),
+			)
+			handler := m.RequireScope("payment-provider")(func(w http.ResponseWriter, r *http.Request) {
+				principal, _ := auth.FromContext(r.Context())
+				subject = principal.Subject
+			})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", "/api/transactions/1/proceed/", nil)
+			if testCase.apiKey != "" {
+				r.Header.Set(apiKeyHeader, testCase.apiKey)
+			}
+			if testCase.token != "" {
+				r.Header.Set(authorizationHeader, "Bearer "+testCase.token)
+			}
+
+			handler(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedSubject, subject)
+		})
+	}
+}
@@ -1,27 +1,57 @@
 package middleware
 
 import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
 
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+
 	"github.com/golang-jwt/jwt"
 )
 
 const authorizationHeader = "Authorization"
 
+// Source of public keys used to verify RS256/ES256 tokens
+type KeySource interface {
+	Key(kid string, alg string) (interface{}, error)
+}
+
 type AuthMiddleware struct {
 	signingKey string
+	keySource  KeySource
+	issuer     string
+	audience   string
 }
 
+// "aud" claim value, it can be a single string or a list of strings
+type tokenAudience []string
+
 type tokenClaims struct {
 	jwt.StandardClaims
+	// shadows StandardClaims.Audience which does not support list of audiences
+	Audience tokenAudience `json:"aud,omitempty"`
+	// space separated list of scopes (OAuth 2.0 style)
+	Scope      string   `json:"scope,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+	Email      string   `json:"email,omitempty"`
+	MerchantId string   `json:"merchant_id,omitempty"`
 }
 
-func NewAuthMiddleware(signingKey string) *AuthMiddleware {
-	/*AtuhMiddleware constructor function.*/
-	return &AuthMiddleware{signingKey: signingKey}
+func NewAuthMiddleware(signingKey string, keySource KeySource, issuer string, audience string) *AuthMiddleware {
+	/*
+		AtuhMiddleware constructor function.
+
+		HS256 tokens are accepted only if signingKey is not empty, RS256/ES256 tokens only if keySource is not nil.
+		Empty issuer and audience are not checked.
+	*/
+	return &AuthMiddleware{signingKey: signingKey, keySource: keySource, issuer: issuer, audience: audience}
 }
 
 func (m *AuthMiddleware) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
@@ -29,12 +59,13 @@ func (m *AuthMiddleware) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
 		var headerParts []string
+		var principal *auth.Principal
 		var header string = r.Header.Get(authorizationHeader)
 
 		// check auth header
 		if header == "" {
 			log.Println("AuthMiddleware: Got empty auth header.")
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			writeUnauthorized(w, r)
 			return
 		}
 
@@ -42,53 +73,161 @@ func (m *AuthMiddleware) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc
 
 		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
 			log.Println("AuthMiddleware: bad auth header string.")
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			writeUnauthorized(w, r)
 			return
 		}
 
 		// check empty token
 		if headerParts[1] == "" {
 			log.Println("AuthMiddleware: empty token string.")
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			writeUnauthorized(w, r)
 			return
 		}
 
 		// parse token and check is it valid
-		err = m.parseToken(headerParts[1])
+		principal, err = m.parseToken(headerParts[1])
 		if err != nil {
 			log.Printf("m.parseToken failed: %s.", err.Error())
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			writeUnauthorized(w, r)
+			return
+		}
+
+		// token is valid can run next handler with principal in request context
+		next(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	}
+}
+
+func (m *AuthMiddleware) RequireScope(scopes ...string) func(http.HandlerFunc) http.HandlerFunc {
+	/*
+		Return HTTP middleware wrapper function which authenticates request
+		and allows it only if token has at least one of the scopes.
+	*/
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return m.AuthMiddleware(requireScope(scopes, next))
+	}
+}
+
+func requireScope(scopes []string, next http.HandlerFunc) http.HandlerFunc {
+	/*Wrap handler of authenticated request, request is allowed only if principal has at least one of the scopes.*/
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.FromContext(r.Context())
+
+		if !ok || !principal.HasScope(scopes...) {
+			problem.Write(w, r, problem.New(
+				http.StatusForbidden, problem.CodeForbidden, "Credentials do not have required scope."))
 			return
 		}
 
-		// token is valid can run next handler
 		next(w, r)
 	}
 }
 
-func (m *AuthMiddleware) parseToken(accessToken string) error {
-	/*Perform parsing JWT token.*/
+func (m *AuthMiddleware) parseToken(accessToken string) (*auth.Principal, error) {
+	/*Perform parsing JWT token, check it's issuer and audience and build principal from it's claims.*/
 	var err error
 	var token *jwt.Token
 
-	token, err = jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// define parsing key function
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-
-		return []byte(m.signingKey), nil
-	})
+	token, err = jwt.ParseWithClaims(accessToken, &tokenClaims{}, m.verificationKey)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// parse token claims to struct
-	_, ok := token.Claims.(*tokenClaims)
+	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return errors.New("token claims are not of type *tokenClaims")
+		return nil, errors.New("token claims are not of type *tokenClaims")
+	}
+
+	if m.issuer != "" && claims.Issuer != m.issuer {
+		return nil, fmt.Errorf("unexpected token issuer %q", claims.Issuer)
 	}
 
-	return nil
+	if m.audience != "" && !claims.Audience.contains(m.audience) {
+		return nil, errors.New("token is not issued for the audience")
+	}
+
+	return claims.principal(), nil
+}
+
+func (m *AuthMiddleware) verificationKey(token *jwt.Token) (interface{}, error) {
+	/*Return key to verify token signature, key is selected by signing method and "kid" header.*/
+	var alg string = token.Method.Alg()
+
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if m.signingKey == "" {
+			return nil, errors.New("HMAC signed tokens are not accepted")
+		}
+
+		return []byte(m.signingKey), nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if m.keySource == nil {
+			return nil, fmt.Errorf("%s signed tokens are not accepted", alg)
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		key, err := m.keySource.Key(kid, alg)
+		if err != nil {
+			return nil, err
+		}
+
+		// key type must match signing method
+		switch key.(type) {
+		case *rsa.PublicKey:
+			if _, ok := token.Method.(*jwt.SigningMethodRSA); ok {
+				return key, nil
+			}
+		case *ecdsa.PublicKey:
+			if _, ok := token.Method.(*jwt.SigningMethodECDSA); ok {
+				return key, nil
+			}
+		}
+
+		return nil, fmt.Errorf("key %q can not be used with %s", kid, alg)
+	default:
+		return nil, errors.New("invalid signing method")
+	}
+}
+
+func (audience *tokenAudience) UnmarshalJSON(data []byte) error {
+	/*Parse "aud" claim from a single string or a list of strings.*/
+	var single string
+
+	if err := json.Unmarshal(data, &single); err == nil {
+		*audience = tokenAudience{single}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(audience))
+}
+
+func (audience tokenAudience) contains(value string) bool {
+	/*Check audience list contains the value.*/
+	for _, item := range audience {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (claims *tokenClaims) principal() *auth.Principal {
+	/*Build principal from token claims, roles and scopes are merged.*/
+	var scopes []string = append(strings.Fields(claims.Scope), claims.Roles...)
+
+	return &auth.Principal{
+		Subject:    claims.Subject,
+		Email:      claims.Email,
+		MerchantId: claims.MerchantId,
+		Scopes:     scopes,
+	}
+}
+
+func writeUnauthorized(w http.ResponseWriter, r *http.Request) {
+	/*Write HTTP 401 problem response, details of auth failure are not exposed to client.*/
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	problem.Write(w, r, problem.New(
+		http.StatusUnauthorized, problem.CodeUnauthorized, "Valid bearer token is required."))
 }
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningKey = "test-signing-key"
+
+func signTestToken(t *testing.T, claims *tokenClaims) string {
+	/*Return token signed with test signing key.*/
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSigningKey))
+	if err != nil {
+		t.Fatalf("SignedString failed: %s", err)
+	}
+
+	return token
+}
+
+func TestAuthMiddleware_RequireScope(t *testing.T) {
+	// Arrange
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	providerToken := signTestToken(t, &tokenClaims{
+		StandardClaims: jwt.StandardClaims{Subject: "provider-1", ExpiresAt: expiresAt},
+		Scope:          "refunds:write payment-provider",
+		MerchantId:     "merchant-1",
+	})
+	userToken := signTestToken(t, &tokenClaims{
+		StandardClaims: jwt.StandardClaims{Subject: "1", ExpiresAt: expiresAt},
+		Roles:          []string{"user"},
+	})
+	expiredToken := signTestToken(t, &tokenClaims{
+		StandardClaims: jwt.StandardClaims{Subject: "provider-1", ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+		Scope:          "payment-provider",
+	})
+
+	testTable := []struct {
+		name               string
+		header             string
+		expectedStatusCode int
+		expectedPrincipal  *auth.Principal
+	}{
+		{
+			name:               "Test token with required scope (ok)",
+			header:             "Bearer " + providerToken,
+			expectedStatusCode: http.StatusOK,
+			expectedPrincipal: &auth.Principal{
+				Subject:    "provider-1",
+				MerchantId: "merchant-1",
+				Scopes:     []string{"refunds:write", "payment-provider"},
+			},
+		},
+		{
+			name:               "Test token without required scope",
+			header:             "Bearer " + userToken,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "Test expired token",
+			header:             "Bearer " + expiredToken,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test token signed with another key",
+			header:             "Bearer " + providerToken[:len(providerToken)-2] + "xx",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test without auth header",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var principal *auth.Principal
+
+			m := NewAuthMiddleware(testSigningKey, nil, "", "")
+			handler := m.RequireScope("payment-provider")(func(w http.ResponseWriter, r *http.Request) {
+				principal, _ = auth.FromContext(r.Context())
+			})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", "/api/transactions/1/proceed/", nil)
+			if testCase.header != "" {
+				r.Header.Set(authorizationHeader, testCase.header)
+			}
+
+			handler(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedPrincipal, principal)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import "net/http"
+
+// Middleware which accepts both credential types: API key (if X-API-Key header is passed) and bearer token
+type CredentialsMiddleware struct {
+	tokenMiddleware  *AuthMiddleware
+	apiKeyMiddleware *ApiKeyMiddleware
+}
+
+func NewCredentialsMiddleware(tokenMiddleware *AuthMiddleware, apiKeyMiddleware *ApiKeyMiddleware) *CredentialsMiddleware {
+	/*CredentialsMiddleware constructor function.*/
+	return &CredentialsMiddleware{tokenMiddleware: tokenMiddleware, apiKeyMiddleware: apiKeyMiddleware}
+}
+
+func (m *CredentialsMiddleware) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	/*HTTP middleware wrapper function, authenticates request by API key or bearer token.*/
+	var apiKeyHandler http.HandlerFunc = m.apiKeyMiddleware.ApiKeyMiddleware(next)
+	var tokenHandler http.HandlerFunc = m.tokenMiddleware.AuthMiddleware(next)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(apiKeyHeader) != "" {
+			apiKeyHandler(w, r)
+			return
+		}
+
+		tokenHandler(w, r)
+	}
+}
+
+func (m *CredentialsMiddleware) RequireScope(scopes ...string) func(http.HandlerFunc) http.HandlerFunc {
+	/*Return HTTP middleware wrapper function which allows only credentials with at least one of the scopes.*/
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return m.AuthMiddleware(requireScope(scopes, next))
+	}
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength  = 255
+)
+
+type IdempotencyService interface {
+	Begin(subject string, key string, requestBody []byte) (*models.IdempotencyKey, error)
+	Complete(subject string, key string, status int, headers map[string][]string, body []byte) error
+	Release(subject string, key string) error
+}
+
+type IdempotencyMiddleware struct {
+	service IdempotencyService
+}
+
+// http.ResponseWriter wrapper which keeps copy of response status code, headers and body,
+// headers set by outer middlewares (e.g. rate limit state) are not recorded
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	header      http.Header
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func NewIdempotencyMiddleware(service IdempotencyService) *IdempotencyMiddleware {
+	/*IdempotencyMiddleware constructor function.*/
+	return &IdempotencyMiddleware{service: service}
+}
+
+func (m *IdempotencyMiddleware) IdempotencyMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	/*
+		HTTP middleware wrapper function.
+
+		Keys are scoped by authenticated subject, so stored response is replayed only to the client which made
+		the original request. Middleware must be used after authentication and authorization of the request.
+	*/
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var body []byte
+		var subject string
+		var recorder *responseRecorder
+		var idempotencyKey *models.IdempotencyKey
+		var key string = r.Header.Get(idempotencyKeyHeader)
+
+		// requests without idempotency key are processed as usual
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		if len(key) > idempotencyKeyMaxLength {
+			problem.Write(w, r, problem.New(
+				http.StatusBadRequest, problem.CodeInvalidRequest, "Idempotency-Key header is too long."))
+			return
+		}
+
+		// read request body to fingerprint it and restore it for the next handler
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			problem.Write(w, r, problem.New(
+				http.StatusBadRequest, problem.CodeInvalidRequest, "Request body can not be read."))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if principal, ok := auth.FromContext(r.Context()); ok {
+			subject = principal.Subject
+		}
+
+		idempotencyKey, err = m.service.Begin(subject, key, body)
+
+		if err != nil {
+			// key mismatch and in progress request errors are returned as HTTP 409
+			problem.WriteError(w, r, err)
+			return
+		}
+
+		// replay stored response of the original request
+		if idempotencyKey != nil {
+			for name, values := range idempotencyKey.ResponseHeaders {
+				w.Header()[name] = values
+			}
+			w.Header().Set(idempotentReplayedHeader, "true")
+			w.WriteHeader(*idempotencyKey.ResponseStatus)
+			w.Write(idempotencyKey.ResponseBody)
+			return
+		}
+
+		recorder = &responseRecorder{ResponseWriter: w, status: http.StatusOK, header: make(http.Header)}
+		next(recorder, r)
+		// flush headers of the response without body
+		if !recorder.wroteHeader {
+			recorder.WriteHeader(recorder.status)
+		}
+
+		// server errors are not stored, so the client is able to retry request with the same key
+		if recorder.status >= http.StatusInternalServerError {
+			if err = m.service.Release(subject, key); err != nil {
+				log.Printf("m.service.Release failed: %s", err.Error())
+			}
+			return
+		}
+
+		// content type sniffed by http server is not set to recorded headers, so it is detected the same way
+		if recorder.header.Get("Content-Type") == "" && recorder.body.Len() > 0 {
+			recorder.header.Set("Content-Type", http.DetectContentType(recorder.body.Bytes()))
+		}
+
+		// key which response is not stored is released, otherwise retries are rejected as in progress until it expires
+		if err = m.service.Complete(subject, key, recorder.status, recorder.header, recorder.body.Bytes()); err != nil {
+			log.Printf("m.service.Complete failed: %s", err.Error())
+			if err = m.service.Release(subject, key); err != nil {
+				log.Printf("m.service.Release failed: %s", err.Error())
+			}
+		}
+	}
+}
+
+func (recorder *responseRecorder) Header() http.Header {
+	/*Return headers of the response set by the next handler.*/
+	return recorder.header
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	/*Save response status code and write it with recorded headers to the wrapped writer.*/
+	if recorder.wroteHeader {
+		return
+	}
+	recorder.wroteHeader = true
+	recorder.status = status
+
+	for name, values := range recorder.header {
+		recorder.ResponseWriter.Header()[name] = values
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	/*Save response body chunk and write it to the wrapped writer.*/
+	if !recorder.wroteHeader {
+		recorder.WriteHeader(http.StatusOK)
+	}
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// in-memory IdempotencyService implementation, keys are stored by subject and key
+type idempotencyServiceStub struct {
+	keys        map[string]*models.IdempotencyKey
+	completeErr error
+}
+
+func (stub *idempotencyServiceStub) Begin(subject string, key string, requestBody []byte) (*models.IdempotencyKey, error) {
+	idempotencyKey, ok := stub.keys[subject+":"+key]
+	if !ok {
+		stub.keys[subject+":"+key] = &models.IdempotencyKey{Subject: subject, Key: key, RequestHash: string(requestBody)}
+		return nil, nil
+	}
+	if idempotencyKey.RequestHash != string(requestBody) {
+		return nil, services.ErrIdempotencyKeyMismatch
+	}
+	if idempotencyKey.ResponseStatus == nil {
+		return nil, services.ErrIdempotencyKeyInProgress
+	}
+	return idempotencyKey, nil
+}
+
+func (stub *idempotencyServiceStub) Complete(
+	subject string, key string, status int, headers map[string][]string, body []byte,
+) error {
+	if stub.completeErr != nil {
+		return stub.completeErr
+	}
+	stub.keys[subject+":"+key].ResponseStatus = &status
+	stub.keys[subject+":"+key].ResponseHeaders = headers
+	stub.keys[subject+":"+key].ResponseBody = body
+	return nil
+}
+
+func (stub *idempotencyServiceStub) Release(subject string, key string) error {
+	delete(stub.keys, subject+":"+key)
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	// Arrange
+	var calls int
+	var responseStatus int = http.StatusCreated
+	service := &idempotencyServiceStub{keys: map[string]*models.IdempotencyKey{}}
+	middleware := NewIdempotencyMiddleware(service)
+	handler := middleware.IdempotencyMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(responseStatus)
+		w.Write([]byte(`{"id":1}`))
+	})
+
+	testTable := []struct {
+		name                string
+		key                 string
+		subject             string
+		requestBody         string
+		responseStatus      int
+		expectedCalls       int
+		expectedStatusCode  int
+		expectedRequestBody string
+		expectedReplayed    string
+	}{
+		{
+			name:                "Test first request (ok)",
+			key:                 "key-1",
+			subject:             "1",
+			requestBody:         `{"amount":100}`,
+			responseStatus:      http.StatusCreated,
+			expectedCalls:       1,
+			expectedStatusCode:  http.StatusCreated,
+			expectedRequestBody: `{"id":1}`,
+		},
+		{
+			name:                "Test replay with the same body",
+			key:                 "key-1",
+			subject:             "1",
+			requestBody:         `{"amount":100}`,
+			responseStatus:      http.StatusCreated,
+			expectedCalls:       1,
+			expectedStatusCode:  http.StatusCreated,
+			expectedRequestBody: `{"id":1}`,
+			expectedReplayed:    "true",
+		},
+		{
+			name:                "Test replay with another body",
+			key:                 "key-1",
+			subject:             "1",
+			requestBody:         `{"amount":200}`,
+			responseStatus:      http.StatusCreated,
+			expectedCalls:       1,
+			expectedStatusCode:  http.StatusConflict,
+			expectedRequestBody: problemBody(services.ErrIdempotencyKeyMismatch, "/api/transactions/"),
+		},
+		{
+			name:                "Test same key of another subject",
+			key:                 "key-1",
+			subject:             "2",
+			requestBody:         `{"amount":100}`,
+			responseStatus:      http.StatusCreated,
+			expectedCalls:       2,
+			expectedStatusCode:  http.StatusCreated,
+			expectedRequestBody: `{"id":1}`,
+		},
+		{
+			name:                "Test request without key",
+			subject:             "1",
+			requestBody:         `{"amount":100}`,
+			responseStatus:      http.StatusCreated,
+			expectedCalls:       3,
+			expectedStatusCode:  http.StatusCreated,
+			expectedRequestBody: `{"id":1}`,
+		},
+		{
+			name:                "Test server error is not stored",
+			key:                 "key-2",
+			subject:             "1",
+			requestBody:         `{"amount":100}`,
+			responseStatus:      http.StatusInternalServerError,
+			expectedCalls:       4,
+			expectedStatusCode:  http.StatusInternalServerError,
+			expectedRequestBody: `{"id":1}`,
+		},
+		{
+			name:                "Test retry after server error",
+			key:                 "key-2",
+			subject:             "1",
+			requestBody:         `{"amount":100}`,
+			responseStatus:      http.StatusCreated,
+			expectedCalls:       5,
+			expectedStatusCode:  http.StatusCreated,
+			expectedRequestBody: `{"id":1}`,
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			responseStatus = testCase.responseStatus
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/transactions/", bytes.NewBufferString(testCase.requestBody))
+			r = r.WithContext(auth.NewContext(r.Context(), &auth.Principal{Subject: testCase.subject}))
+			if testCase.key != "" {
+				r.Header.Set(idempotencyKeyHeader, testCase.key)
+			}
+
+			handler(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedCalls, calls)
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+			assert.Equal(t, testCase.expectedReplayed, w.Header().Get(idempotentReplayedHeader))
+			if testCase.expectedStatusCode == http.StatusCreated {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_OuterHeaders(t *testing.T) {
+	// Arrange
+	service := &idempotencyServiceStub{keys: map[string]*models.IdempotencyKey{}}
+	handler := NewIdempotencyMiddleware(service).IdempotencyMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	})
+
+	w := httptest.NewRecorder()
+	w.Header().Set("X-RateLimit-Remaining", "5")
+	r := httptest.NewRequest("POST", "/api/transactions/", bytes.NewBufferString(`{"amount":100}`))
+	r.Header.Set(idempotencyKeyHeader, "key-1")
+
+	// Act
+	handler(w, r)
+
+	// Assert
+	// headers of outer middlewares are not stored, sniffed content type is
+	stored := service.keys[":key-1"]
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "5", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Empty(t, stored.ResponseHeaders["X-Ratelimit-Remaining"])
+	assert.Equal(t, []string{"text/plain; charset=utf-8"}, stored.ResponseHeaders["Content-Type"])
+}
+
+func TestIdempotencyMiddleware_CompleteFailed(t *testing.T) {
+	// Arrange
+	var calls int
+	service := &idempotencyServiceStub{keys: map[string]*models.IdempotencyKey{}, completeErr: errors.New("db error")}
+	handler := NewIdempotencyMiddleware(service).IdempotencyMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	// Act
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/transactions/", bytes.NewBufferString(`{"amount":100}`))
+		r.Header.Set(idempotencyKeyHeader, "key-1")
+
+		handler(w, r)
+
+		// Assert
+		// key is released, so retry is processed instead of being rejected as in progress
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, service.keys)
+}
+
+func problemBody(err error, instance string) string {
+	/*Return expected problem response body for the error.*/
+	var body *problem.Problem = problem.FromError(err)
+	body.Instance = instance
+
+	data, _ := json.Marshal(body)
+
+	return string(data) + "\n"
+}
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Max size of JWKS document, protects from huge responses of misconfigured URL
+const jwksMaxSize = 1 << 20
+
+// JSON Web Key Set document (RFC 7517)
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JSON Web Key, only public RSA and EC keys fields are declared
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA public key params
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC public key params
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Public key of key set with algorithm it is allowed to be used with (empty for any)
+type publicKey struct {
+	key interface{}
+	alg string
+}
+
+// Set of public keys used to verify asymmetrically signed tokens, keys are loaded from JWKS file or URL
+type KeySet struct {
+	source string
+	client *http.Client
+	mu     sync.RWMutex
+	keys   map[string]publicKey
+}
+
+func NewKeySet(source string, client *http.Client) *KeySet {
+	/*KeySet constructor function, source is JWKS file path or http(s) URL.*/
+	return &KeySet{source: source, client: client, keys: map[string]publicKey{}}
+}
+
+func (keySet *KeySet) Run(ctx context.Context, interval time.Duration) {
+	/*Refresh keys periodically until context is canceled, keys are kept unchanged if refresh failed.*/
+	var ticker *time.Ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := keySet.Refresh(ctx); err != nil {
+				log.Printf("keySet.Refresh failed: %s", err.Error())
+			}
+		}
+	}
+}
+
+func (keySet *KeySet) Refresh(ctx context.Context) error {
+	/*Load JWKS document from source and replace current keys with it's keys.*/
+	var err error
+	var data []byte
+	var document jwks
+	var keys map[string]publicKey = map[string]publicKey{}
+
+	if data, err = keySet.load(ctx); err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %w", err)
+	}
+
+	for _, key := range document.Keys {
+		// keys for encryption are not used to verify signatures
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		parsedKey, err := key.publicKey()
+		if err != nil {
+			log.Printf("KeySet: skipping key %q: %s", key.Kid, err.Error())
+			continue
+		}
+		keys[key.Kid] = publicKey{key: parsedKey, alg: key.Alg}
+	}
+
+	if len(keys) == 0 {
+		return errors.New("JWKS does not contain any supported key")
+	}
+
+	keySet.mu.Lock()
+	keySet.keys = keys
+	keySet.mu.Unlock()
+
+	return nil
+}
+
+func (keySet *KeySet) Key(kid string, alg string) (interface{}, error) {
+	/*Return public key by id, key must be allowed to be used with the algorithm.*/
+	keySet.mu.RLock()
+	key, ok := keySet.keys[kid]
+	keySet.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	if key.alg != "" && key.alg != alg {
+		return nil, fmt.Errorf("key %q is not allowed to be used with %s", kid, alg)
+	}
+
+	return key.key, nil
+}
+
+func (keySet *KeySet) load(ctx context.Context) ([]byte, error) {
+	/*Read JWKS document from file or download it from URL.*/
+	if !strings.HasPrefix(keySet.source, "http://") && !strings.HasPrefix(keySet.source, "https://") {
+		return os.ReadFile(keySet.source)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, keySet.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := keySet.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected JWKS response status %d", response.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(response.Body, jwksMaxSize))
+}
+
+func (key *jwk) publicKey() (interface{}, error) {
+	/*Build RSA or ECDSA public key from JWK params.*/
+	switch key.Kty {
+	case "RSA":
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("e: invalid exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch key.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", key.Crv)
+		}
+
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", key.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	/*Decode base64url encoded big-endian unsigned integer.*/
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty value")
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
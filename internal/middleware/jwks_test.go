@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testIssuer   = "https://auth.example.com/"
+	testAudience = "payment-service"
+)
+
+// httptest server which serves JWKS document, served keys can be replaced to emulate rotation
+type jwksServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys []jwk
+}
+
+func newJWKSServer(keys ...jwk) *jwksServer {
+	server := &jwksServer{keys: keys}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		json.NewEncoder(w).Encode(jwks{Keys: server.keys})
+	}))
+	return server
+}
+
+func (server *jwksServer) setKeys(keys ...jwk) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.keys = keys
+}
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "RSA", Kid: kid, Use: "sig", Alg: "RS256",
+		N: encodeBigInt(key.N), E: encodeBigInt(big.NewInt(int64(key.E))),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "EC", Kid: kid, Use: "sig", Alg: "ES256",
+		Crv: "P-256", X: encodeBigInt(key.X), Y: encodeBigInt(key.Y),
+	}
+}
+
+func signAsymmetricToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims *tokenClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString failed: %s", err)
+	}
+
+	return signed
+}
+
+func TestAuthMiddleware_JWKS(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	server := newJWKSServer(rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey))
+	defer server.Close()
+
+	keySet := NewKeySet(server.URL, server.Client())
+	assert.NoError(t, keySet.Refresh(context.Background()))
+
+	validClaims := func() *tokenClaims {
+		return &tokenClaims{
+			StandardClaims: jwt.StandardClaims{
+				Subject: "provider-1", Issuer: testIssuer, ExpiresAt: time.Now().Add(time.Hour).Unix(),
+			},
+			Audience: tokenAudience{"another-service", testAudience},
+			Scope:    "payment-provider",
+		}
+	}
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "https://evil.example.com/"
+	wrongAudience := validClaims()
+	wrongAudience.Audience = tokenAudience{"another-service"}
+
+	testTable := []struct {
+		name               string
+		token              string
+		expectedStatusCode int
+	}{
+		{
+			name:               "Test RS256 token (ok)",
+			token:              signAsymmetricToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims()),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Test ES256 token (ok)",
+			token:              signAsymmetricToken(t, jwt.SigningMethodES256, "ec-1", ecKey, validClaims()),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Test unknown kid",
+			token:              signAsymmetricToken(t, jwt.SigningMethodRS256, "rsa-2", rsaKey, validClaims()),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test key used with another algorithm",
+			token:              signAsymmetricToken(t, jwt.SigningMethodRS512, "rsa-1", rsaKey, validClaims()),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test wrong issuer",
+			token:              signAsymmetricToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, wrongIssuer),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test wrong audience",
+			token:              signAsymmetricToken(t, jwt.SigningMethodES256, "ec-1", ecKey, wrongAudience),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test HMAC token when signing key is not configured",
+			token:              signTestToken(t, validClaims()),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			m := NewAuthMiddleware("", keySet, testIssuer, testAudience)
+			handler := m.RequireScope("payment-provider")(func(w http.ResponseWriter, r *http.Request) {})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", "/api/transactions/1/proceed/", nil)
+			r.Header.Set(authorizationHeader, "Bearer "+testCase.token)
+
+			handler(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	// Arrange
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(rsaJWK("old", oldKey))
+	defer server.Close()
+
+	keySet := NewKeySet(server.URL, server.Client())
+	assert.NoError(t, keySet.Refresh(context.Background()))
+
+	// Act
+	_, newKeyErrBefore := keySet.Key("new", "RS256")
+
+	server.setKeys(rsaJWK("new", newKey))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		keySet.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		_, err := keySet.Key("new", "RS256")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	// Assert
+	_, oldKeyErr := keySet.Key("old", "RS256")
+	assert.Error(t, newKeyErrBefore)
+	assert.Error(t, oldKeyErr)
+}
+
+func TestKeySet_RefreshFromFile(t *testing.T) {
+	// Arrange
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	data, _ := json.Marshal(jwks{Keys: []jwk{
+		ecJWK("ec-1", ecKey),
+		{Kty: "oct", Kid: "symmetric"},
+		{Kty: "RSA", Kid: "encryption", Use: "enc"},
+	}})
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+
+	keySet := NewKeySet(path, nil)
+
+	// Act
+	err := keySet.Refresh(context.Background())
+	key, keyErr := keySet.Key("ec-1", "ES256")
+	_, symmetricErr := keySet.Key("symmetric", "HS256")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, keyErr)
+	assert.Equal(t, &ecKey.PublicKey, key)
+	assert.Error(t, symmetricErr)
+}
+
+func TestKeySet_RefreshFailureKeepsKeys(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(rsaJWK("rsa-1", rsaKey))
+	defer server.Close()
+
+	keySet := NewKeySet(server.URL, server.Client())
+	assert.NoError(t, keySet.Refresh(context.Background()))
+
+	// Act
+	server.setKeys()
+	err := keySet.Refresh(context.Background())
+	_, keyErr := keySet.Key("rsa-1", "RS256")
+
+	// Assert
+	assert.Error(t, err)
+	assert.NoError(t, keyErr)
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Token bucket rate: bucket holds up to Limit tokens and is refilled by Limit tokens per Period
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Bucket key and it's rate checked for the request
+type LimitKey struct {
+	Key  string
+	Rate Rate
+}
+
+// State of the key bucket after request was counted
+type RateLimitStatus struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// time until the next request is allowed, zero for allowed request
+	RetryAfter time.Duration
+	// time until bucket is full again
+	Reset time.Duration
+}
+
+// Storage of token buckets, in-memory limiter works within one replica only,
+// shared store (e.g. Postgres) implementation is required to limit requests across replicas.
+// Request is counted atomically: token is taken from every key bucket only if all of them allow the request,
+// statuses are returned in order of keys.
+type Limiter interface {
+	Allow(ctx context.Context, keys []LimitKey) ([]*RateLimitStatus, error)
+}
+
+// Token bucket of the key
+type tokenBucket struct {
+	rate    Rate
+	tokens  float64
+	updated time.Time
+}
+
+// In-memory token buckets limiter
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+func (rate Rate) Enabled() bool {
+	/*Return true if the rate limits requests, zero rate disables limiting.*/
+	return rate.Limit > 0 && rate.Period > 0
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	/*MemoryLimiter constructor function.*/
+	return &MemoryLimiter{buckets: make(map[string]*tokenBucket), now: time.Now}
+}
+
+func (limiter *MemoryLimiter) Allow(ctx context.Context, keys []LimitKey) ([]*RateLimitStatus, error) {
+	/*Take token from every key bucket, request is not allowed (and no tokens are taken) if any bucket is empty.*/
+	var statuses []*RateLimitStatus = make([]*RateLimitStatus, len(keys))
+	var buckets []*tokenBucket = make([]*tokenBucket, len(keys))
+	var allowed bool = true
+	var now time.Time = limiter.now()
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	// check all buckets before any token is taken
+	for i, key := range keys {
+		bucket, ok := limiter.buckets[key.Key]
+		if !ok || bucket.rate != key.Rate {
+			bucket = &tokenBucket{rate: key.Rate, tokens: float64(key.Rate.Limit), updated: now}
+			limiter.buckets[key.Key] = bucket
+		}
+		bucket.refill(now)
+
+		buckets[i] = bucket
+		allowed = allowed && bucket.tokens >= 1
+	}
+
+	for i, bucket := range buckets {
+		var status *RateLimitStatus = &RateLimitStatus{Limit: bucket.rate.Limit, Allowed: allowed}
+
+		if allowed {
+			bucket.tokens--
+		} else if bucket.tokens < 1 {
+			status.RetryAfter = bucket.duration(1 - bucket.tokens)
+		}
+		status.Remaining = int(math.Floor(bucket.tokens))
+		status.Reset = bucket.duration(float64(bucket.rate.Limit) - bucket.tokens)
+
+		statuses[i] = status
+	}
+
+	return statuses, nil
+}
+
+func (limiter *MemoryLimiter) Run(ctx context.Context, interval time.Duration) {
+	/*Remove full (idle) buckets periodically until context is canceled, so memory is not exhausted by stale keys.*/
+	var ticker *time.Ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			limiter.prune()
+		}
+	}
+}
+
+func (limiter *MemoryLimiter) prune() {
+	/*Remove buckets which are full, such bucket is equal to the new one.*/
+	var now time.Time = limiter.now()
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	for key, bucket := range limiter.buckets {
+		if bucket.refill(now); bucket.tokens >= float64(bucket.rate.Limit) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
+
+func (bucket *tokenBucket) refill(now time.Time) {
+	/*Add tokens accumulated since the last update, bucket never holds more than limit tokens.*/
+	var elapsed time.Duration = now.Sub(bucket.updated)
+
+	if elapsed > 0 {
+		bucket.tokens = math.Min(
+			float64(bucket.rate.Limit),
+			bucket.tokens+float64(elapsed)*float64(bucket.rate.Limit)/float64(bucket.rate.Period),
+		)
+		bucket.updated = now
+	}
+}
+
+func (bucket *tokenBucket) duration(tokens float64) time.Duration {
+	/*Return time required to accumulate the tokens.*/
+	return time.Duration(math.Ceil(tokens * float64(bucket.rate.Period) / float64(bucket.rate.Limit)))
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func allow(limiter *MemoryLimiter, key string, rate Rate) (*RateLimitStatus, error) {
+	/*Count request of single key bucket.*/
+	statuses, err := limiter.Allow(context.Background(), []LimitKey{{Key: key, Rate: rate}})
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses[0], nil
+}
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	// Arrange
+	now := time.Unix(1700000000, 0)
+	rate := Rate{Limit: 3, Period: 3 * time.Second}
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+
+	// Act & Assert
+	// full bucket allows burst of limit requests
+	for remaining := 2; remaining >= 0; remaining-- {
+		status, err := allow(limiter, "user:1", rate)
+		assert.NoError(t, err)
+		assert.Equal(t, &RateLimitStatus{
+			Allowed:   true,
+			Limit:     3,
+			Remaining: remaining,
+			Reset:     time.Duration(3-remaining) * time.Second,
+		}, status)
+	}
+
+	status, err := allow(limiter, "user:1", rate)
+	assert.NoError(t, err)
+	assert.False(t, status.Allowed)
+	assert.Equal(t, 0, status.Remaining)
+	assert.Equal(t, time.Second, status.RetryAfter)
+
+	// another key has it's own bucket
+	status, _ = allow(limiter, "user:2", rate)
+	assert.True(t, status.Allowed)
+
+	// one token is refilled per second
+	now = now.Add(1500 * time.Millisecond)
+	status, _ = allow(limiter, "user:1", rate)
+	assert.True(t, status.Allowed)
+	assert.Equal(t, 0, status.Remaining)
+	status, _ = allow(limiter, "user:1", rate)
+	assert.False(t, status.Allowed)
+	assert.Equal(t, 500*time.Millisecond, status.RetryAfter)
+
+	// bucket never holds more than limit tokens
+	now = now.Add(time.Hour)
+	status, _ = allow(limiter, "user:1", rate)
+	assert.Equal(t, 2, status.Remaining)
+}
+
+func TestMemoryLimiter_Prune(t *testing.T) {
+	// Arrange
+	now := time.Unix(1700000000, 0)
+	rate := Rate{Limit: 2, Period: time.Second}
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+
+	allow(limiter, "user:1", rate)
+	allow(limiter, "user:2", rate)
+	allow(limiter, "user:2", rate)
+
+	// Act
+	now = now.Add(600 * time.Millisecond)
+	limiter.prune()
+
+	// Assert
+	assert.Len(t, limiter.buckets, 1)
+	assert.Contains(t, limiter.buckets, "user:2")
+}
+
+func TestMemoryLimiter_AllowSeveralKeys(t *testing.T) {
+	// Arrange
+	now := time.Unix(1700000000, 0)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	keys := []LimitKey{
+		{Key: "subject:1", Rate: Rate{Limit: 3, Period: time.Minute}},
+		{Key: "user:1", Rate: Rate{Limit: 1, Period: time.Minute}},
+	}
+
+	// Act
+	first, err := limiter.Allow(context.Background(), keys)
+	assert.NoError(t, err)
+	second, err := limiter.Allow(context.Background(), keys)
+	assert.NoError(t, err)
+
+	// Assert
+	assert.True(t, first[0].Allowed)
+	assert.True(t, first[1].Allowed)
+
+	// request denied by user bucket does not take token of subject bucket
+	assert.False(t, second[0].Allowed)
+	assert.False(t, second[1].Allowed)
+	assert.Equal(t, 2, second[0].Remaining)
+	assert.Equal(t, time.Duration(0), second[0].RetryAfter)
+	assert.Equal(t, time.Minute, second[1].RetryAfter)
+
+	status, _ := allow(limiter, "subject:1", keys[0].Rate)
+	assert.True(t, status.Allowed)
+	assert.Equal(t, 1, status.Remaining)
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+)
+
+const (
+	// Headers of rate limited responses
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+
+	// Max size of request body read to find user_id
+	rateLimitMaxBodySize = 1 << 20
+)
+
+// Rates of request keys, zero rate disables limiting by the key
+type RateLimits struct {
+	// authenticated principal subject (user or client)
+	Subject Rate
+	// client IP address
+	IP Rate
+	// user_id of request body, e.g. payer of created transaction
+	User Rate
+}
+
+type RateLimitMiddleware struct {
+	limiter Limiter
+	limits  RateLimits
+}
+
+func NewRateLimitMiddleware(limiter Limiter, limits RateLimits) *RateLimitMiddleware {
+	/*RateLimitMiddleware constructor function.*/
+	return &RateLimitMiddleware{limiter: limiter, limits: limits}
+}
+
+func (m *RateLimitMiddleware) RateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	/*
+		HTTP middleware wrapper function, limits requests by subject, IP and user_id with token buckets.
+
+		Must be wrapped by authentication middleware to limit requests by subject
+		and by middleware authorizing user_id of request body, so only clients allowed
+		to act on behalf of the user (user itself, admin) charge it's bucket.
+		State of the most restrictive bucket is reported in X-RateLimit-* headers,
+		requests are allowed if limiter fails, so payments are not stopped by limiter store outage.
+	*/
+	return func(w http.ResponseWriter, r *http.Request) {
+		var keys []LimitKey
+		var statuses []*RateLimitStatus
+		var reported *RateLimitStatus
+		var err error
+
+		keys, ok := m.requestKeys(w, r)
+		if !ok {
+			return
+		}
+
+		if len(keys) > 0 {
+			statuses, err = m.limiter.Allow(r.Context(), keys)
+			if err != nil {
+				log.Printf("m.limiter.Allow failed: %s", err.Error())
+			}
+		}
+
+		for _, status := range statuses {
+			if reported == nil || restrictive(status, reported) {
+				reported = status
+			}
+		}
+
+		if reported != nil {
+			w.Header().Set(RateLimitLimitHeader, strconv.Itoa(reported.Limit))
+			w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(reported.Remaining))
+			w.Header().Set(RateLimitResetHeader, seconds(reported.Reset))
+		}
+
+		if reported != nil && !reported.Allowed {
+			w.Header().Set(RetryAfterHeader, seconds(reported.RetryAfter))
+			problem.Write(w, r, problem.New(
+				http.StatusTooManyRequests, problem.CodeRateLimited, "Too many requests, try again later."))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (m *RateLimitMiddleware) requestKeys(w http.ResponseWriter, r *http.Request) ([]LimitKey, bool) {
+	/*
+		Return bucket keys of the request, request body is read to find user_id and restored for the next handler.
+
+		user_id bucket is charged by every client creating transactions of the user,
+		access to the user is checked before (other clients can not drain it by naming the user).
+	*/
+	var keys []LimitKey
+	var body struct {
+		UserId int `json:"user_id"`
+	}
+
+	principal, authenticated := auth.FromContext(r.Context())
+	if authenticated && m.limits.Subject.Enabled() {
+		keys = append(keys, LimitKey{Key: "subject:" + principal.Subject, Rate: m.limits.Subject})
+	}
+
+	if m.limits.IP.Enabled() {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		keys = append(keys, LimitKey{Key: "ip:" + host, Rate: m.limits.IP})
+	}
+
+	if authenticated && m.limits.User.Enabled() && r.Body != nil {
+		data, err := io.ReadAll(io.LimitReader(r.Body, rateLimitMaxBodySize))
+		if err != nil {
+			problem.Write(w, r, problem.New(
+				http.StatusBadRequest, problem.CodeInvalidRequest, "Request body can not be read."))
+			return nil, false
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+
+		// invalid body is rejected by the handler
+		if json.Unmarshal(data, &body) == nil && body.UserId > 0 {
+			keys = append(keys, LimitKey{Key: "user:" + strconv.Itoa(body.UserId), Rate: m.limits.User})
+		}
+	}
+
+	return keys, true
+}
+
+func restrictive(status *RateLimitStatus, than *RateLimitStatus) bool {
+	/*Return true if status is more restrictive: request is denied by it or it has less remaining requests.*/
+	if status.Allowed != than.Allowed {
+		return !status.Allowed
+	}
+	if !status.Allowed {
+		return status.RetryAfter > than.RetryAfter
+	}
+
+	return status.Remaining < than.Remaining
+}
+
+func seconds(duration time.Duration) string {
+	/*Format duration as whole number of seconds rounded up.*/
+	return strconv.FormatInt(int64(math.Ceil(duration.Seconds())), 10)
+}
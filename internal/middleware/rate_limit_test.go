@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Limiter implementation which always fails
+type failingLimiter struct{}
+
+func (limiter failingLimiter) Allow(ctx context.Context, keys []LimitKey) ([]*RateLimitStatus, error) {
+	return nil, errors.New("some error")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	// Arrange
+	type request struct {
+		subject    string
+		remoteAddr string
+		body       string
+	}
+	limits := RateLimits{
+		Subject: Rate{Limit: 3, Period: time.Minute},
+		IP:      Rate{Limit: 5, Period: time.Minute},
+		User:    Rate{Limit: 2, Period: time.Minute},
+	}
+
+	testTable := []struct {
+		name               string
+		limits             RateLimits
+		requests           []request
+		expectedStatusCode int
+		expectedHeaders    map[string]string
+	}{
+		{
+			name:               "Test allowed request",
+			limits:             limits,
+			requests:           []request{{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 1}`}},
+			expectedStatusCode: http.StatusOK,
+			// user bucket is the most restrictive one
+			expectedHeaders: map[string]string{RateLimitLimitHeader: "2", RateLimitRemainingHeader: "1", RateLimitResetHeader: "30"},
+		},
+		{
+			name:   "Test user limit",
+			limits: limits,
+			requests: []request{
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 1}`},
+				{subject: "1", remoteAddr: "10.0.0.2:1000", body: `{"user_id": 1}`},
+				{subject: "1", remoteAddr: "10.0.0.3:1000", body: `{"user_id": 1}`},
+			},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedHeaders:    map[string]string{RateLimitLimitHeader: "2", RateLimitRemainingHeader: "0", RetryAfterHeader: "30"},
+		},
+		{
+			// user bucket is shared by all clients creating transactions of the user (e.g. admin)
+			name:   "Test user limit of several subjects",
+			limits: limits,
+			requests: []request{
+				{subject: "admin", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 1}`},
+				{subject: "1", remoteAddr: "10.0.0.2:1000", body: `{"user_id": 1}`},
+				{subject: "admin", remoteAddr: "10.0.0.3:1000", body: `{"user_id": 1}`},
+			},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedHeaders:    map[string]string{RateLimitLimitHeader: "2", RateLimitRemainingHeader: "0", RetryAfterHeader: "30"},
+		},
+		{
+			// denied requests do not take tokens of subject bucket, so it is not exhausted by user limit
+			name: "Test denied request is not counted",
+			limits: RateLimits{
+				Subject: Rate{Limit: 3, Period: time.Minute},
+				User:    Rate{Limit: 1, Period: time.Minute},
+			},
+			requests: []request{
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 1}`},
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 1}`},
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 1}`},
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{}`},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{RateLimitLimitHeader: "3", RateLimitRemainingHeader: "1"},
+		},
+		{
+			name:   "Test subject limit",
+			limits: limits,
+			requests: []request{
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 1}`},
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 2}`},
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 3}`},
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 4}`},
+			},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedHeaders:    map[string]string{RateLimitLimitHeader: "3", RetryAfterHeader: "20"},
+		},
+		{
+			name:   "Test IP limit",
+			limits: RateLimits{IP: Rate{Limit: 1, Period: time.Minute}},
+			requests: []request{
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{}`},
+				{subject: "2", remoteAddr: "10.0.0.1:2000", body: `{}`},
+			},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedHeaders:    map[string]string{RateLimitLimitHeader: "1", RetryAfterHeader: "60"},
+		},
+		{
+			name:   "Test disabled limits",
+			limits: RateLimits{},
+			requests: []request{
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 1}`},
+				{subject: "1", remoteAddr: "10.0.0.1:1000", body: `{"user_id": 1}`},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{RateLimitLimitHeader: ""},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var w *httptest.ResponseRecorder
+			var body []byte
+			now := time.Unix(1700000000, 0)
+			limiter := NewMemoryLimiter()
+			limiter.now = func() time.Time { return now }
+			m := NewRateLimitMiddleware(limiter, testCase.limits)
+			next := func(w http.ResponseWriter, r *http.Request) {
+				// request body is restored for the handler
+				body, _ = io.ReadAll(r.Body)
+			}
+
+			// Act
+			for _, request := range testCase.requests {
+				body = nil
+				w = httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/transactions/", bytes.NewBufferString(request.body))
+				r.RemoteAddr = request.remoteAddr
+				r = r.WithContext(auth.NewContext(r.Context(), &auth.Principal{Subject: request.subject}))
+				m.RateLimitMiddleware(next)(w, r)
+			}
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			for header, value := range testCase.expectedHeaders {
+				assert.Equal(t, value, w.Header().Get(header), header)
+			}
+			if testCase.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, testCase.requests[len(testCase.requests)-1].body, string(body))
+			} else {
+				assert.Nil(t, body)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware_LimiterError(t *testing.T) {
+	// Arrange
+	var called bool
+	m := NewRateLimitMiddleware(failingLimiter{}, RateLimits{IP: Rate{Limit: 1, Period: time.Minute}})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/transactions/", bytes.NewBufferString(`{}`))
+
+	// Act
+	m.RateLimitMiddleware(func(w http.ResponseWriter, r *http.Request) { called = true })(w, r)
+
+	// Assert
+	// requests are allowed if limiter is not available
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+}
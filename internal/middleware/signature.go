@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/problem"
+)
+
+const (
+	// Headers of signed request
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureHeader          = "X-Signature"
+
+	signaturePrefix = "sha256="
+	// Max size of signed request body
+	signatureMaxBodySize = 1 << 20
+)
+
+// Source of per-provider secrets used to sign requests
+type SigningSecrets interface {
+	SigningSecret(provider string) (string, bool)
+}
+
+// Signing secrets by provider name, e.g. loaded from config
+type StaticSigningSecrets map[string]string
+
+type SignatureMiddleware struct {
+	secrets   SigningSecrets
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+func NewSignatureMiddleware(secrets SigningSecrets, tolerance time.Duration) *SignatureMiddleware {
+	/*SignatureMiddleware constructor function, requests with timestamp older than tolerance are rejected.*/
+	return &SignatureMiddleware{secrets: secrets, tolerance: tolerance, now: time.Now}
+}
+
+func (m *SignatureMiddleware) SignatureMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	/*
+		HTTP middleware wrapper function, verifies HMAC signature of request body.
+
+		Must be wrapped by authentication middleware, secret is selected by principal subject (provider).
+	*/
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var body []byte
+		var timestamp int64
+
+		principal, ok := auth.FromContext(r.Context())
+		if !ok {
+			writeInvalidSignature(w, r, "Request is not authenticated.")
+			return
+		}
+
+		secret, ok := m.secrets.SigningSecret(principal.Provider())
+		if !ok {
+			log.Printf("SignatureMiddleware: no signing secret of provider %q.", principal.Provider())
+			writeInvalidSignature(w, r, "Provider is not allowed to sign requests.")
+			return
+		}
+
+		// stale timestamps are rejected to prevent replay of captured requests
+		timestamp, err = strconv.ParseInt(r.Header.Get(SignatureTimestampHeader), 10, 64)
+		if err != nil {
+			writeInvalidSignature(w, r, SignatureTimestampHeader+" header is not valid.")
+			return
+		}
+		if age := m.now().Sub(time.Unix(timestamp, 0)); age > m.tolerance || age < -m.tolerance {
+			writeInvalidSignature(w, r, SignatureTimestampHeader+" is outside of allowed tolerance.")
+			return
+		}
+
+		// read request body to verify signature and restore it for the next handler,
+		// one byte over the limit is read to reject larger bodies instead of verifying truncated one
+		body, err = io.ReadAll(io.LimitReader(r.Body, signatureMaxBodySize+1))
+		if err != nil {
+			problem.Write(w, r, problem.New(
+				http.StatusBadRequest, problem.CodeInvalidRequest, "Request body can not be read."))
+			return
+		}
+		if len(body) > signatureMaxBodySize {
+			problem.Write(w, r, problem.New(
+				http.StatusRequestEntityTooLarge, problem.CodeBodyTooLarge, "Signed request body is too large."))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !verifySignature(r.Header.Get(SignatureHeader), secret, timestamp, body) {
+			writeInvalidSignature(w, r, SignatureHeader+" does not match request body.")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (secrets StaticSigningSecrets) SigningSecret(provider string) (string, bool) {
+	/*Return signing secret of provider.*/
+	secret, ok := secrets[provider]
+
+	return secret, ok && secret != ""
+}
+
+func SignRequest(secret string, timestamp int64, body []byte) string {
+	/*Return hex encoded HMAC-SHA256 signature of "{timestamp}.{body}" string.*/
+	var mac = hmac.New(sha256.New, []byte(secret))
+
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifySignature(header string, secret string, timestamp int64, body []byte) bool {
+	/*Compare signature from header with expected one in constant time.*/
+	if !strings.HasPrefix(header, signaturePrefix) {
+		return false
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(header, signaturePrefix))
+	if err != nil {
+		return false
+	}
+
+	expected, _ := hex.DecodeString(SignRequest(secret, timestamp, body))
+
+	return hmac.Equal(signature, expected)
+}
+
+func writeInvalidSignature(w http.ResponseWriter, r *http.Request, detail string) {
+	/*Write HTTP 401 problem response of request with missing or invalid signature.*/
+	problem.Write(w, r, problem.New(http.StatusUnauthorized, problem.CodeInvalidSignature, detail))
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	// Arrange
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"status":"SUCCESS"}`)
+	secrets := StaticSigningSecrets{"provider-1": "secret-1", "provider-2": "secret-2"}
+	provider := &auth.Principal{Subject: "provider-1"}
+	// body is one byte over max size, signature of truncated body must not be accepted
+	largeBody := bytes.Repeat([]byte("a"), signatureMaxBodySize+1)
+
+	testTable := []struct {
+		name               string
+		principal          *auth.Principal
+		timestamp          string
+		signature          string
+		body               []byte
+		expectedStatusCode int
+	}{
+		{
+			name:               "Test valid signature (ok)",
+			principal:          provider,
+			timestamp:          strconv.FormatInt(now.Unix(), 10),
+			signature:          signaturePrefix + SignRequest("secret-1", now.Unix(), body),
+			body:               body,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Test valid signature of API key provider",
+			principal:          &auth.Principal{Subject: auth.ProviderSubjectPrefix + "provider-1"},
+			timestamp:          strconv.FormatInt(now.Unix(), 10),
+			signature:          signaturePrefix + SignRequest("secret-1", now.Unix(), body),
+			body:               body,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Test signature of another body",
+			principal:          provider,
+			timestamp:          strconv.FormatInt(now.Unix(), 10),
+			signature:          signaturePrefix + SignRequest("secret-1", now.Unix(), body),
+			body:               []byte(`{"status":"FAILED"}`),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test signature with secret of another provider",
+			principal:          provider,
+			timestamp:          strconv.FormatInt(now.Unix(), 10),
+			signature:          signaturePrefix + SignRequest("secret-2", now.Unix(), body),
+			body:               body,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test stale timestamp",
+			principal:          provider,
+			timestamp:          strconv.FormatInt(now.Add(-6*time.Minute).Unix(), 10),
+			signature:          signaturePrefix + SignRequest("secret-1", now.Add(-6*time.Minute).Unix(), body),
+			body:               body,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test timestamp from the future",
+			principal:          provider,
+			timestamp:          strconv.FormatInt(now.Add(6*time.Minute).Unix(), 10),
+			signature:          signaturePrefix + SignRequest("secret-1", now.Add(6*time.Minute).Unix(), body),
+			body:               body,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test missing signature",
+			principal:          provider,
+			timestamp:          strconv.FormatInt(now.Unix(), 10),
+			body:               body,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Test body over max size",
+			principal:          provider,
+			timestamp:          strconv.FormatInt(now.Unix(), 10),
+			signature:          signaturePrefix + SignRequest("secret-1", now.Unix(), largeBody[:signatureMaxBodySize]),
+			body:               largeBody,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:               "Test body of max size",
+			principal:          provider,
+			timestamp:          strconv.FormatInt(now.Unix(), 10),
+			signature:          signaturePrefix + SignRequest("secret-1", now.Unix(), largeBody[:signatureMaxBodySize]),
+			body:               largeBody[:signatureMaxBodySize],
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Test provider without secret",
+			principal:          &auth.Principal{Subject: "provider-3"},
+			timestamp:          strconv.FormatInt(now.Unix(), 10),
+			signature:          signaturePrefix + SignRequest("", now.Unix(), body),
+			body:               body,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var receivedBody []byte
+
+			m := NewSignatureMiddleware(secrets, 5*time.Minute)
+			m.now = func() time.Time { return now }
+			handler := m.SignatureMiddleware(func(w http.ResponseWriter, r *http.Request) {
+				receivedBody, _ = io.ReadAll(r.Body)
+			})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", "/api/transactions/1/proceed/", bytes.NewReader(testCase.body))
+			r.Header.Set(SignatureTimestampHeader, testCase.timestamp)
+			r.Header.Set(SignatureHeader, testCase.signature)
+			r = r.WithContext(auth.NewContext(r.Context(), testCase.principal))
+
+			handler(w, r)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			if testCase.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, testCase.body, receivedBody)
+			}
+		})
+	}
+}
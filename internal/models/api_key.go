@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// base ApiKey entity struct, credential of payment provider (or another service)
+type ApiKey struct {
+	Id       int            `json:"id" db:"id"`
+	Provider string         `json:"provider" db:"provider"`
+	Prefix   string         `json:"prefix" db:"prefix"`
+	KeyHash  string         `json:"-" db:"key_hash"`
+	Scopes   pq.StringArray `json:"scopes" db:"scopes"`
+	// plain key, filled only in creation response
+	Key        string     `json:"key,omitempty" db:"-"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+}
+
+// ApiKey entity struct used for creating new key in API
+// use validation tags for validation request data
+type ApiKeyInput struct {
+	Provider string   `json:"provider" validate:"required,max=255"`
+	Scopes   []string `json:"scopes" validate:"required,min=1,dive,required,max=64"`
+}
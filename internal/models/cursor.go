@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+func (cursor *TransactionCursor) Encode() string {
+	/*Return opaque string representation of the cursor.*/
+	return encodeCursor(cursor)
+}
+
+func DecodeTransactionCursor(value string) (*TransactionCursor, error) {
+	/*Parse cursor from opaque string representation.*/
+	var cursor TransactionCursor
+
+	if err := decodeCursor(value, &cursor); err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+func (cursor *TransactionSearchCursor) Encode() string {
+	/*Return opaque string representation of the cursor.*/
+	return encodeCursor(cursor)
+}
+
+func DecodeTransactionSearchCursor(value string) (*TransactionSearchCursor, error) {
+	/*Parse cursor from opaque string representation.*/
+	var cursor TransactionSearchCursor
+
+	if err := decodeCursor(value, &cursor); err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+func encodeCursor(cursor interface{}) string {
+	/*Return base64 encoded JSON representation of the cursor.*/
+	data, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string, cursor interface{}) error {
+	/*Parse base64 encoded JSON representation to the cursor.*/
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, cursor)
+}
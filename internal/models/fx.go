@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// FX rate entity struct, rate is amount of quote currency for one unit of base currency
+// rate is effective from EffectiveDate until the next rate of the same currencies pair
+type FxRate struct {
+	Id            int    `json:"id" db:"id"`
+	BaseCurrency  string `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string `json:"quote_currency" db:"quote_currency"`
+	// decimal string, e.g. "0.9123"
+	Rate          string    `json:"rate" db:"rate"`
+	EffectiveDate time.Time `json:"effective_date" db:"effective_date"`
+	// provider the rate was loaded from
+	Source    string    `json:"source" db:"source"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Audit record of transaction amount conversion with the rate used
+type FxConversion struct {
+	Id              int       `json:"id" db:"id"`
+	TransactionId   int       `json:"transaction_id" db:"transaction_id"`
+	RateId          int       `json:"rate_id" db:"rate_id"`
+	FromCurrency    string    `json:"from_currency" db:"from_currency"`
+	ToCurrency      string    `json:"to_currency" db:"to_currency"`
+	Rate            string    `json:"rate" db:"rate"`
+	Amount          int64     `json:"amount" db:"amount"`
+	ConvertedAmount int64     `json:"converted_amount" db:"converted_amount"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// FX rate effective at the date of transaction(s) creation, fields are nil if rate is not found
+// Rate is already inverted when only the reverse currencies pair rate exists
+type EffectiveFxRate struct {
+	RateId   *int       `db:"rate_id"`
+	Rate     *string    `db:"rate"`
+	RateDate *time.Time `db:"rate_date"`
+}
+
+// Transaction with FX rate to the report currency
+type TransactionFxRate struct {
+	Transaction
+	EffectiveFxRate
+}
+
+// Sum of transactions amounts of one currency converted with the same FX rate
+type TransactionFxRateTotal struct {
+	Currency string `db:"currency"`
+	Amount   int64  `db:"amount"`
+	Count    int    `db:"count"`
+	EffectiveFxRate
+}
+
+// Transaction converted to the report currency with the rate used
+type ConvertedTransaction struct {
+	Transaction              *Transaction `json:"transaction"`
+	ConvertedAmount          int64        `json:"converted_amount"`
+	ConvertedAmountFormatted string       `json:"converted_amount_formatted"`
+	Rate                     string       `json:"rate"`
+	// nil when transaction currency equals to the report one
+	RateId   *int   `json:"rate_id"`
+	RateDate string `json:"rate_date,omitempty"`
+}
+
+// Total of all transactions matched the filter converted to the report currency
+type ConvertedTotal struct {
+	Count           int    `json:"count"`
+	Amount          int64  `json:"amount"`
+	AmountFormatted string `json:"amount_formatted"`
+}
+
+// Page of user transactions converted to the report currency with total of all matched transactions
+type ConvertedTransactionsReport struct {
+	Currency   string                  `json:"currency"`
+	Items      []*ConvertedTransaction `json:"items"`
+	Total      ConvertedTotal          `json:"total"`
+	Limit      int                     `json:"limit"`
+	NextCursor string                  `json:"next_cursor"`
+}
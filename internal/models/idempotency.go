@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// base IdempotencyKey entity struct
+// stores request fingerprint and the response returned for it, key is unique per subject (client)
+type IdempotencyKey struct {
+	Subject         string              `db:"subject"`
+	Key             string              `db:"key"`
+	RequestHash     string              `db:"request_hash"`
+	ResponseStatus  *int                `db:"response_status"`
+	ResponseHeaders map[string][]string `db:"-"`
+	ResponseBody    []byte              `db:"response_body"`
+	CreatedAt       time.Time           `db:"created_at"`
+	ExpiresAt       time.Time           `db:"expires_at"`
+}
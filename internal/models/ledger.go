@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Ledger account, one account per code and currency
+type LedgerAccount struct {
+	Id        int       `json:"id" db:"id"`
+	Code      string    `json:"code" db:"code"`
+	Currency  string    `json:"currency" db:"currency"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Ledger account with sums of it's postings, balance is debit minus credit
+type LedgerAccountBalance struct {
+	LedgerAccount
+	Debit   int64 `json:"debit" db:"debit"`
+	Credit  int64 `json:"credit" db:"credit"`
+	Balance int64 `json:"balance" db:"balance"`
+}
+
+// Journal entry, postings of entry are balanced per currency
+type LedgerEntry struct {
+	Id            int              `json:"id" db:"id"`
+	Kind          string           `json:"kind" db:"kind"`
+	TransactionId *int             `json:"transaction_id" db:"transaction_id"`
+	RefundId      *int             `json:"refund_id" db:"refund_id"`
+	WalletId      *int             `json:"wallet_id" db:"wallet_id"`
+	CreatedAt     time.Time        `json:"created_at" db:"created_at"`
+	Postings      []*LedgerPosting `json:"postings" db:"-"`
+}
+
+// Posting of journal entry to account
+type LedgerPosting struct {
+	Id          int    `json:"id" db:"id"`
+	EntryId     int    `json:"entry_id" db:"entry_id"`
+	AccountId   int    `json:"account_id" db:"account_id"`
+	AccountCode string `json:"account_code" db:"account_code"`
+	Currency    string `json:"currency" db:"currency"`
+	// debit amounts are positive, credit amounts are negative
+	Amount int64 `json:"amount" db:"amount"`
+}
+
+// Account entries history params used for paginating list of entries
+// use validation tags for validation request data
+type LedgerEntryFilter struct {
+	Limit  int    `json:"limit" validate:"gte=1,lte=100"`
+	Cursor string `json:"cursor" validate:"omitempty"`
+	// decoded Cursor value, filled by service, entries are ordered by (created_at, id) as transactions
+	After *TransactionCursor `json:"-" validate:"-"`
+}
+
+// Page of account entries with opaque cursor to retrieve the next page
+type LedgerEntryPage struct {
+	Items      []*LedgerEntry `json:"items"`
+	Limit      int            `json:"limit"`
+	NextCursor string         `json:"next_cursor"`
+}
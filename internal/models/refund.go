@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// base Refund entity struct, refund of (part of) SUCCESS transaction amount
+type Refund struct {
+	Id            int       `json:"id" db:"id"`
+	TransactionId int       `json:"transaction_id" db:"transaction_id"`
+	Amount        int64     `json:"amount" db:"amount"`
+	Status        string    `json:"status" db:"status"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Refund entity struct used for creating new refund in API
+// use validation tags for validation request data
+type RefundInput struct {
+	Amount int64 `json:"amount" validate:"required,gt=0"`
+}
+
+// Refund status struct used for updating refund status in API
+// use validation tags for validation request data, allowed statuses are checked by state machine
+type RefundStatusInput struct {
+	Status string `json:"status" validate:"required,uppercase"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Audit record of risk rules evaluated on transaction creation
+type RiskDecision struct {
+	Id            int    `json:"id" db:"id"`
+	TransactionId int    `json:"transaction_id" db:"transaction_id"`
+	Outcome       string `json:"outcome" db:"outcome"`
+	// matched rules, empty if transaction is allowed
+	Reasons   []RiskReason `json:"reasons" db:"-"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+}
+
+// Risk rule matched by transaction
+type RiskReason struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Transactions statistics params, transactions are selected by creation time within [From, To)
+// and grouped to buckets of Interval length, use validation tags for validation request data
+type TransactionStatsFilter struct {
+	From     time.Time `json:"from" validate:"required"`
+	To       time.Time `json:"to" validate:"required,gtfield=From"`
+	Interval string    `json:"interval" validate:"oneof=hour day month"`
+	// transactions of the user only, all transactions if nil
+	UserId *int `json:"-" validate:"-"`
+}
+
+// Aggregated transactions group retrieved from db, nil bucket, status and currency
+// mean the row aggregates all values of the column
+type TransactionStatsRow struct {
+	Bucket   *time.Time `db:"bucket"`
+	Status   *string    `db:"status"`
+	Currency *string    `db:"currency"`
+	Count    int64      `db:"count"`
+	Amount   int64      `db:"amount"`
+	// number of transactions with successful outcome and number of transactions with any outcome
+	Successful int64 `db:"successful"`
+	Processed  int64 `db:"processed"`
+	// average time between transaction creation and it's outcome
+	AvgProcessingSeconds *float64 `db:"avg_processing_seconds"`
+}
+
+// Count and sum of transactions amounts of one status and currency
+type TransactionStatsTotal struct {
+	Status          string `json:"status"`
+	Currency        string `json:"currency"`
+	Count           int64  `json:"count"`
+	Amount          int64  `json:"amount"`
+	AmountFormatted string `json:"amount_formatted"`
+}
+
+// Statistics of group of transactions, success rate and average processing time
+// are nil if there are no processed transactions
+type TransactionStatsSummary struct {
+	Count                int64                    `json:"count"`
+	SuccessRate          *float64                 `json:"success_rate"`
+	AvgProcessingSeconds *float64                 `json:"avg_processing_seconds"`
+	Totals               []*TransactionStatsTotal `json:"totals"`
+}
+
+// Statistics of transactions created within the bucket
+type TransactionStatsBucket struct {
+	Start time.Time `json:"start"`
+	TransactionStatsSummary
+}
+
+// Statistics of transactions over the time window, buckets without transactions are omitted
+type TransactionStats struct {
+	From     time.Time                 `json:"from"`
+	To       time.Time                 `json:"to"`
+	Interval string                    `json:"interval"`
+	Buckets  []*TransactionStatsBucket `json:"buckets"`
+	Total    TransactionStatsSummary   `json:"total"`
+}
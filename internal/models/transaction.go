@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/money"
+)
 
 // base Transaction entity struct
 type Transaction struct {
@@ -12,19 +17,111 @@ type Transaction struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 	Status    string    `json:"status" db:"status"`
+	// time when NEW transaction got it's outcome, nil while transaction is NEW
+	ProcessedAt *time.Time `json:"processed_at" db:"processed_at"`
+	// time when NEW transaction is expired, nil for transactions created with another status
+	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+	// payment provider processing transaction and it's payment reference,
+	// nil for transactions which were not sent to provider
+	Provider          *string `json:"provider" db:"provider"`
+	ProviderReference *string `json:"provider_reference" db:"provider_reference"`
+	// merchant of the client which created transaction, events are sent to webhook endpoints of the merchant
+	MerchantId *string `json:"merchant_id,omitempty" db:"merchant_id"`
+	// row version used for optimistic locking
+	Version int `json:"-" db:"version"`
+}
+
+func (transaction Transaction) MarshalJSON() ([]byte, error) {
+	/*Serialize transaction with amount formatted as decimal string in major units of currency.*/
+	type transactionFields Transaction
+
+	return json.Marshal(struct {
+		transactionFields
+		AmountFormatted string `json:"amount_formatted"`
+	}{
+		transactionFields: transactionFields(transaction),
+		AmountFormatted:   money.Format(transaction.Amount, transaction.Currency),
+	})
 }
 
 // Transaction entity struct used for creating new transaction in API
 // use validation tags for validation request data
 type TransactionInput struct {
-	UserId    int    `json:"user_id" validate:"required,gt=0"`
-	UserEmail string `json:"user_email" validate:"required,email"`
-	Amount    int64  `json:"amount" validate:"required"`
-	Currency  string `json:"currency" validate:"required,len=3,uppercase"`
+	// email of transaction is taken from user record
+	UserId int `json:"user_id" validate:"required,gt=0"`
+	// amount in minor units of currency (e.g. cents)
+	Amount int64 `json:"amount" validate:"required_without=AmountDecimal,omitempty,gt=0"`
+	// alternative to Amount, amount in major units (e.g. "15.50")
+	AmountDecimal string `json:"amount_decimal,omitempty" validate:"excluded_with=Amount,omitempty,max=32"`
+	Currency      string `json:"currency" validate:"required,currency"`
+	// time to live of NEW transaction in seconds (from 1 minute to 30 days), default TTL is used if it is not set
+	TTLSeconds int `json:"ttl_seconds,omitempty" validate:"omitempty,min=60,max=2592000"`
+	// merchant of authenticated client, used to select payment provider
+	MerchantId string `json:"-" validate:"-"`
 }
 
 // Transaction status struct used for updating transaction status in API
-// use validation tags for validation request data
+// use validation tags for validation request data, allowed statuses are checked by state machine
 type TransactionStatusInput struct {
-	Status string `json:"status" validate:"required,uppercase,oneof=SUCCESS FAILED"`
+	Status string `json:"status" validate:"required,uppercase"`
+}
+
+// Transaction list filter params used for filtering and paginating list of transactions
+// use validation tags for validation request data
+type TransactionFilter struct {
+	Status      string     `json:"status" validate:"omitempty,uppercase"`
+	Currency    string     `json:"currency" validate:"omitempty,currency"`
+	AmountMin   *int64     `json:"amount_min" validate:"omitempty,gte=0"`
+	AmountMax   *int64     `json:"amount_max" validate:"omitempty,gte=0"`
+	CreatedFrom *time.Time `json:"created_from" validate:"omitempty"`
+	CreatedTo   *time.Time `json:"created_to" validate:"omitempty"`
+	Limit       int        `json:"limit" validate:"gte=1,lte=100"`
+	Cursor      string     `json:"cursor" validate:"omitempty"`
+	// decoded Cursor value, filled by service
+	After *TransactionCursor `json:"-" validate:"-"`
+}
+
+// Transactions search params used for filtering, sorting and paginating all transactions
+// use validation tags for validation request data
+type TransactionSearchFilter struct {
+	Statuses    []string   `json:"status" validate:"omitempty,max=10,dive,uppercase"`
+	Currency    string     `json:"currency" validate:"omitempty,currency"`
+	UserId      *int       `json:"user_id" validate:"omitempty,gt=0"`
+	Email       string     `json:"email" validate:"omitempty,max=254"`
+	AmountMin   *int64     `json:"amount_min" validate:"omitempty,gte=0"`
+	AmountMax   *int64     `json:"amount_max" validate:"omitempty,gte=0"`
+	CreatedFrom *time.Time `json:"created_from" validate:"omitempty"`
+	CreatedTo   *time.Time `json:"created_to" validate:"omitempty"`
+	UpdatedFrom *time.Time `json:"updated_from" validate:"omitempty"`
+	UpdatedTo   *time.Time `json:"updated_to" validate:"omitempty"`
+	Sort        string     `json:"sort" validate:"oneof=created_at updated_at amount"`
+	Order       string     `json:"order" validate:"oneof=asc desc"`
+	Limit       int        `json:"limit" validate:"gte=1,lte=100"`
+	Cursor      string     `json:"cursor" validate:"omitempty"`
+	// decoded Cursor value, filled by service
+	After *TransactionSearchCursor `json:"-" validate:"-"`
+}
+
+// Position of the last transaction on the page, used for keyset pagination
+type TransactionCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	Id        int       `json:"id"`
+}
+
+// Position of the last transaction on the search page, used for keyset pagination by any sort field,
+// sort params are kept to reject cursor used with another sorting
+type TransactionSearchCursor struct {
+	Sort      string    `json:"sort"`
+	Order     string    `json:"order"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Amount    int64     `json:"amount"`
+	Id        int       `json:"id"`
+}
+
+// Page of transactions list with opaque cursor to retrieve the next page
+type TransactionPage struct {
+	Items      []*Transaction `json:"items"`
+	Limit      int            `json:"limit"`
+	NextCursor string         `json:"next_cursor"`
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// User (payer) entity
+type User struct {
+	Id        int       `json:"id" db:"id"`
+	Email     string    `json:"email" db:"email"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// User struct used for creating and updating user in API
+// use validation tags for validation request data
+type UserInput struct {
+	Email string `json:"email" validate:"required,email,max=254"`
+	Name  string `json:"name" validate:"omitempty,max=128"`
+}
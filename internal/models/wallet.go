@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// User wallet of one currency, amounts are in minor units of currency
+type Wallet struct {
+	Id       int    `json:"id" db:"id"`
+	UserId   int    `json:"user_id" db:"user_id"`
+	Currency string `json:"currency" db:"currency"`
+	// funds available for new transactions
+	Available int64 `json:"available" db:"available"`
+	// funds held by NEW transactions until they are captured or released
+	Held      int64     `json:"held" db:"held"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Wallet deposit struct used for adding funds to wallet in API
+// use validation tags for validation request data
+type WalletDepositInput struct {
+	Amount int64 `json:"amount" validate:"required,gt=0"`
+}
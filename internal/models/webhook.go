@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// base WebhookEndpoint entity struct, merchant's URL which receives transaction events
+type WebhookEndpoint struct {
+	Id  int    `json:"id" db:"id"`
+	URL string `json:"url" db:"url"`
+	// owner of the endpoint, endpoint registered by admin has no merchant and receives events of all merchants
+	MerchantId *string   `json:"merchant_id" db:"merchant_id"`
+	Secret     string    `json:"secret,omitempty" db:"secret"`
+	IsActive   bool      `json:"is_active" db:"is_active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// WebhookEndpoint entity struct used for registering new endpoint in API
+// use validation tags for validation request data
+type WebhookEndpointInput struct {
+	URL string `json:"url" validate:"required,url,startswith=http"`
+}
+
+// Delivery of webhook event to the endpoint
+// filled with endpoint and event data required to perform delivery
+type WebhookDelivery struct {
+	Id            int       `db:"id"`
+	EventId       int       `db:"event_id"`
+	EndpointId    int       `db:"endpoint_id"`
+	Status        string    `db:"status"`
+	Attempts      int       `db:"attempts"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	LastError     string    `db:"last_error"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+
+	EndpointURL    string    `db:"endpoint_url"`
+	EndpointSecret string    `db:"endpoint_secret"`
+	EventType      string    `db:"event_type"`
+	EventPayload   []byte    `db:"event_payload"`
+	EventCreatedAt time.Time `db:"event_created_at"`
+}
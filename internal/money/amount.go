@@ -0,0 +1,126 @@
+package money
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Exponent used to format amounts of unknown currencies (e.g. stored before validation was added)
+const defaultExponent = 2
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrNotPositive     = errors.New("amount must be positive")
+	ErrInvalidDecimal  = errors.New("amount is not a valid decimal number")
+	ErrPrecision       = errors.New("amount has more decimal places than currency allows")
+	ErrOverflow        = errors.New("amount is too large")
+)
+
+// Positive amount of money in minor units of currency (e.g. cents)
+type Amount struct {
+	Minor    int64
+	Currency Currency
+}
+
+func NewAmount(minor int64, code string) (Amount, error) {
+	/*Amount constructor function, amount must be positive and currency must be known.*/
+	currency, ok := LookupCurrency(code)
+
+	if !ok {
+		return Amount{}, ErrUnknownCurrency
+	}
+
+	if minor <= 0 {
+		return Amount{}, ErrNotPositive
+	}
+
+	return Amount{Minor: minor, Currency: currency}, nil
+}
+
+func ParseAmount(decimal string, code string) (Amount, error) {
+	/*
+		Parse decimal string in major units (e.g. "15.50") to amount.
+
+		Number of decimal places must not exceed currency exponent (trailing zeros are ignored).
+	*/
+	currency, ok := LookupCurrency(code)
+
+	if !ok {
+		return Amount{}, ErrUnknownCurrency
+	}
+
+	integer, fraction, _ := strings.Cut(decimal, ".")
+
+	if !isDigits(integer) || (strings.Contains(decimal, ".") && !isDigits(fraction)) {
+		return Amount{}, ErrInvalidDecimal
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > currency.Exponent {
+		return Amount{}, ErrPrecision
+	}
+
+	// shift decimal point to get amount in minor units
+	minor, err := strconv.ParseInt(integer+fraction+strings.Repeat("0", currency.Exponent-len(fraction)), 10, 64)
+	if err != nil {
+		return Amount{}, ErrOverflow
+	}
+
+	return NewAmount(minor, code)
+}
+
+func (amount Amount) String() string {
+	/*Return amount formatted as decimal string in major units, e.g. "15.50".*/
+	return formatMinor(amount.Minor, amount.Currency.Exponent)
+}
+
+func Format(minor int64, code string) string {
+	/*Format amount in minor units of currency as decimal string in major units.*/
+	var exponent int = defaultExponent
+
+	if currency, ok := LookupCurrency(code); ok {
+		exponent = currency.Exponent
+	}
+
+	return formatMinor(minor, exponent)
+}
+
+func formatMinor(minor int64, exponent int) string {
+	/*Insert decimal point to amount in minor units.*/
+	var sign string
+	var digits string
+
+	if minor < 0 {
+		sign = "-"
+		// unsigned conversion handles math.MinInt64 correctly
+		digits = strconv.FormatUint(uint64(-(minor+1))+1, 10)
+	} else {
+		digits = strconv.FormatInt(minor, 10)
+	}
+
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func isDigits(value string) bool {
+	/*Check value is not empty and contains only ASCII digits.*/
+	if value == "" {
+		return false
+	}
+
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return true
+}
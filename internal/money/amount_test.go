@@ -0,0 +1,101 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAmount(t *testing.T) {
+	testTable := []struct {
+		name          string
+		decimal       string
+		currency      string
+		expectedMinor int64
+		expectedErr   error
+	}{
+		{name: "Test parse amount with cents", decimal: "15.50", currency: "EUR", expectedMinor: 1550},
+		{name: "Test parse amount without fraction", decimal: "15", currency: "EUR", expectedMinor: 1500},
+		{name: "Test parse amount with trailing zeros", decimal: "15.5000", currency: "EUR", expectedMinor: 1550},
+		{name: "Test parse amount of zero exponent currency", decimal: "1500", currency: "JPY", expectedMinor: 1500},
+		{name: "Test parse amount of three digits exponent currency", decimal: "1.005", currency: "KWD", expectedMinor: 1005},
+		{name: "Test parse amount with extra precision", decimal: "1.005", currency: "EUR", expectedErr: ErrPrecision},
+		{name: "Test parse fractional amount of zero exponent currency", decimal: "1.5", currency: "JPY", expectedErr: ErrPrecision},
+		{name: "Test parse zero amount", decimal: "0.00", currency: "EUR", expectedErr: ErrNotPositive},
+		{name: "Test parse negative amount", decimal: "-1.00", currency: "EUR", expectedErr: ErrInvalidDecimal},
+		{name: "Test parse amount with exponent", decimal: "1e3", currency: "EUR", expectedErr: ErrInvalidDecimal},
+		{name: "Test parse amount without integer part", decimal: ".50", currency: "EUR", expectedErr: ErrInvalidDecimal},
+		{name: "Test parse amount with empty fraction", decimal: "1.", currency: "EUR", expectedErr: ErrInvalidDecimal},
+		{name: "Test parse too large amount", decimal: "92233720368547758.08", currency: "EUR", expectedErr: ErrOverflow},
+		{name: "Test parse amount of unknown currency", decimal: "1.00", currency: "XXX", expectedErr: ErrUnknownCurrency},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			amount, err := ParseAmount(testCase.decimal, testCase.currency)
+
+			if testCase.expectedErr != nil {
+				assert.True(t, errors.Is(err, testCase.expectedErr), err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedMinor, amount.Minor)
+			assert.Equal(t, testCase.currency, amount.Currency.Code)
+		})
+	}
+}
+
+func TestNewAmount(t *testing.T) {
+	testTable := []struct {
+		name        string
+		minor       int64
+		currency    string
+		expectedErr error
+	}{
+		{name: "Test positive amount", minor: 1, currency: "USD"},
+		{name: "Test zero amount", minor: 0, currency: "USD", expectedErr: ErrNotPositive},
+		{name: "Test negative amount", minor: -100, currency: "USD", expectedErr: ErrNotPositive},
+		{name: "Test lowercase currency", minor: 100, currency: "usd", expectedErr: ErrUnknownCurrency},
+		{name: "Test testing currency", minor: 100, currency: "XXX", expectedErr: ErrUnknownCurrency},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewAmount(testCase.minor, testCase.currency)
+
+			if testCase.expectedErr != nil {
+				assert.True(t, errors.Is(err, testCase.expectedErr), err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	testTable := []struct {
+		name     string
+		minor    int64
+		currency string
+		expected string
+	}{
+		{name: "Test format amount with cents", minor: 1550, currency: "EUR", expected: "15.50"},
+		{name: "Test format amount less than one", minor: 5, currency: "EUR", expected: "0.05"},
+		{name: "Test format amount of zero exponent currency", minor: 1500, currency: "JPY", expected: "1500"},
+		{name: "Test format amount of three digits exponent currency", minor: 1005, currency: "BHD", expected: "1.005"},
+		{name: "Test format amount of unknown currency", minor: 1550, currency: "ABC", expected: "15.50"},
+		{name: "Test format negative amount", minor: -5, currency: "EUR", expected: "-0.05"},
+		{name: "Test format min int64 amount", minor: math.MinInt64, currency: "EUR", expected: "-92233720368547758.08"},
+		{name: "Test format max int64 amount", minor: math.MaxInt64, currency: "EUR", expected: "92233720368547758.07"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, Format(testCase.minor, testCase.currency))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidRate = errors.New("rate must be a positive decimal number")
+
+func ParseRate(value string) (*big.Rat, error) {
+	/*Parse FX rate decimal string (e.g. "0.9123"), rate must be positive.*/
+	integer, fraction, found := strings.Cut(value, ".")
+
+	// only plain decimals are accepted, big.Rat also parses fractions and exponents
+	if !isDigits(integer) || (found && !isDigits(fraction)) {
+		return nil, ErrInvalidRate
+	}
+
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+
+	return rate, nil
+}
+
+func Convert(minor int64, from Currency, to Currency, rate *big.Rat) (int64, error) {
+	/*
+		Convert amount in minor units of one currency to minor units of another one.
+
+		Rate is amount of target currency for one unit of source currency,
+		result is rounded half away from zero.
+	*/
+	var numerator *big.Int = new(big.Int).Mul(big.NewInt(minor), rate.Num())
+	var denominator *big.Int = new(big.Int).Set(rate.Denom())
+
+	// scale amount to minor units of target currency
+	if to.Exponent > from.Exponent {
+		numerator.Mul(numerator, pow10(to.Exponent-from.Exponent))
+	} else if to.Exponent < from.Exponent {
+		denominator.Mul(denominator, pow10(from.Exponent-to.Exponent))
+	}
+
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+
+	// round half away from zero: |remainder| * 2 >= denominator
+	if remainder.Abs(remainder).Lsh(remainder, 1).Cmp(denominator) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(numerator.Sign())))
+	}
+
+	if !quotient.IsInt64() {
+		return 0, ErrOverflow
+	}
+
+	return quotient.Int64(), nil
+}
+
+func pow10(exponent int) *big.Int {
+	/*Return 10 raised to the power of exponent.*/
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
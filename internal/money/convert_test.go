@@ -0,0 +1,59 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	testTable := []struct {
+		name        string
+		minor       int64
+		from        string
+		to          string
+		rate        string
+		expected    int64
+		expectedErr error
+	}{
+		{name: "Test convert with the same exponent", minor: 1000, from: "EUR", to: "USD", rate: "1.1", expected: 1100},
+		{name: "Test convert rounds half away from zero", minor: 1, from: "EUR", to: "USD", rate: "1.5", expected: 2},
+		{name: "Test convert rounds down", minor: 1, from: "EUR", to: "USD", rate: "1.49", expected: 1},
+		{name: "Test convert negative amount", minor: -1, from: "EUR", to: "USD", rate: "1.5", expected: -2},
+		{name: "Test convert to zero exponent currency", minor: 1050, from: "USD", to: "JPY", rate: "150.25", expected: 1578},
+		{name: "Test convert from zero exponent currency", minor: 1500, from: "JPY", to: "USD", rate: "0.0066", expected: 990},
+		{name: "Test convert to three digits exponent currency", minor: 100, from: "USD", to: "KWD", rate: "0.3075", expected: 308},
+		{name: "Test convert overflow", minor: math.MaxInt64, from: "EUR", to: "JPY", rate: "160", expectedErr: ErrOverflow},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			from, _ := LookupCurrency(testCase.from)
+			to, _ := LookupCurrency(testCase.to)
+			rate, err := ParseRate(testCase.rate)
+			assert.NoError(t, err)
+
+			converted, err := Convert(testCase.minor, from, to, rate)
+
+			if testCase.expectedErr != nil {
+				assert.True(t, errors.Is(err, testCase.expectedErr), err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, converted)
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, value := range []string{"0", "0.000", "-1.5", "1/3", "1e3", ".5", "1.", ""} {
+		t.Run("Test parse invalid rate "+value, func(t *testing.T) {
+			_, err := ParseRate(value)
+
+			assert.True(t, errors.Is(err, ErrInvalidRate), err)
+		})
+	}
+}
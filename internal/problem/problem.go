@@ -0,0 +1,208 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// Media type of error responses (RFC 7807)
+	ContentType = "application/problem+json"
+
+	// Codes of errors which are not produced by services
+	CodeInvalidRequest   = "invalid_request"
+	CodeValidation       = "validation_error"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeInvalidSignature = "invalid_signature"
+	CodeRateLimited      = "rate_limited"
+	CodeBodyTooLarge     = "request_body_too_large"
+	CodeInternal         = "internal_error"
+
+	// Codes of status transition errors
+	CodeTerminalStatus      = "terminal_status"
+	CodeInvalidTransition   = "invalid_status_transition"
+	CodeTransitionForbidden = "status_transition_forbidden"
+
+	// Prefix of problem type URI, code is appended to it
+	typePrefix = "/problems/"
+)
+
+// Error response body in RFC 7807 "problem details" format with machine-readable code
+type Problem struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Code     string                `json:"code"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Errors   []services.FieldError `json:"errors,omitempty"`
+}
+
+func New(status int, code string, detail string) *Problem {
+	/*Problem constructor function.*/
+	return &Problem{
+		Type:   typePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+func Write(w http.ResponseWriter, r *http.Request, problem *Problem) {
+	/*Write problem to response with it's status code.*/
+	problem.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	/*Map error to problem and write it to response, unknown errors are logged and hidden from client.*/
+	Write(w, r, FromError(err))
+
+	if !isKnownError(err) {
+		log.Printf("%s %s failed: %s", r.Method, r.URL.Path, err.Error())
+	}
+}
+
+func FromError(err error) *Problem {
+	/*Map service, validation and transition errors to problem with corresponding status code.*/
+	var serviceError *services.Error
+	var validationError *services.ValidationError
+	var transitionError *services.TransitionError
+	var validationErrors validator.ValidationErrors
+	var problem *Problem
+
+	switch {
+	case errors.As(err, &validationErrors):
+		problem = New(http.StatusBadRequest, CodeValidation, "Request data is not valid.")
+		problem.Errors = FieldErrors(validationErrors)
+	case errors.As(err, &validationError):
+		problem = New(http.StatusBadRequest, CodeValidation, "Request data is not valid.")
+		problem.Errors = validationError.Fields
+	case errors.As(err, &transitionError):
+		code := CodeInvalidTransition
+		switch transitionError.Kind {
+		case services.ErrTerminalStatus:
+			code = CodeTerminalStatus
+		case services.ErrForbidden:
+			code = CodeTransitionForbidden
+		}
+		problem = New(kindStatus(transitionError.Kind), code, transitionError.Error())
+	case errors.As(err, &serviceError):
+		problem = New(kindStatus(serviceError.Kind), serviceError.Code, serviceError.Message)
+	default:
+		problem = New(http.StatusInternalServerError, CodeInternal, "")
+	}
+
+	return problem
+}
+
+func FieldErrors(validationErrors validator.ValidationErrors) []services.FieldError {
+	/*Convert validator errors to list of field errors.*/
+	var fields []services.FieldError = make([]services.FieldError, 0, len(validationErrors))
+
+	for _, fieldError := range validationErrors {
+		fields = append(fields, services.FieldError{
+			Field:   fieldError.Field(),
+			Tag:     fieldError.Tag(),
+			Message: fieldErrorMessage(fieldError),
+		})
+	}
+
+	return fields
+}
+
+func kindStatus(kind error) int {
+	/*Return HTTP status code corresponding to the kind of service error.*/
+	switch kind {
+	case services.ErrNotFound:
+		return http.StatusNotFound
+	case services.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case services.ErrForbidden:
+		return http.StatusForbidden
+	case services.ErrConflict:
+		return http.StatusConflict
+	case services.ErrTerminalStatus, services.ErrValidation:
+		return http.StatusBadRequest
+	case services.ErrPaymentRequired:
+		return http.StatusPaymentRequired
+	case services.ErrUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func isKnownError(err error) bool {
+	/*Check error is produced intentionally and should not be logged.*/
+	return FromError(err).Status < http.StatusInternalServerError
+}
+
+func fieldErrorMessage(fieldError validator.FieldError) string {
+	/*Return human readable message of field validation error.*/
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "currency":
+		return "must be a supported ISO 4217 currency code"
+	case "required_without":
+		return "is required when " + fieldError.Param() + " is not passed"
+	case "excluded_with":
+		return "must not be passed together with " + fieldError.Param()
+	case "max":
+		return limitMessage("at most", fieldError)
+	case "min":
+		return limitMessage("at least", fieldError)
+	case "uppercase":
+		return "must be in upper case"
+	case "len":
+		return "must have length " + fieldError.Param()
+	case "gt":
+		return "must be greater than " + fieldError.Param()
+	case "gte":
+		return "must be greater than or equal to " + fieldError.Param()
+	case "lt":
+		return "must be less than " + fieldError.Param()
+	case "lte":
+		return "must be less than or equal to " + fieldError.Param()
+	case "gtfield":
+		return "must be greater than " + fieldError.Param() + " field"
+	case "oneof":
+		return "must be one of: " + fieldError.Param()
+	case "startswith":
+		return "must start with " + fieldError.Param()
+	default:
+		return "failed on '" + fieldError.Tag() + "' validation"
+	}
+}
+
+func limitMessage(bound string, fieldError validator.FieldError) string {
+	/*Return message of min/max validation error, limit means length of strings and size of collections.*/
+	switch fieldError.Kind() {
+	case reflect.String:
+		return "must be " + bound + " " + fieldError.Param() + " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "must contain " + bound + " " + fieldError.Param() + " items"
+	default:
+		return "must be " + bound + " " + fieldError.Param()
+	}
+}
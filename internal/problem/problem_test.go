@@ -0,0 +1,60 @@
+package problem
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromError_TransitionError(t *testing.T) {
+	// Arrange
+	testTable := []struct {
+		name           string
+		kind           error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "Test terminal status",
+			kind:           services.ErrTerminalStatus,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeTerminalStatus,
+		},
+		{
+			name:           "Test invalid transition",
+			kind:           services.ErrValidation,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidTransition,
+		},
+		{
+			name:           "Test forbidden role",
+			kind:           services.ErrForbidden,
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   CodeTransitionForbidden,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var err error = &services.TransitionError{
+				From: services.TransactionNewStatus,
+				To:   services.TransactionSuccessStatus,
+				Role: services.RoleUser,
+				Kind: testCase.kind,
+			}
+
+			// Act
+			// wrapped error is mapped the same way
+			problem := FromError(fmt.Errorf("service failed: %w", err))
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatus, problem.Status)
+			assert.Equal(t, testCase.expectedCode, problem.Code)
+			assert.Equal(t, err.Error(), problem.Detail)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const (
+	// Amounts (in minor units) ending with these digits trigger fake provider special behaviour
+	FakeDeclineSuffix     int64 = 51
+	FakeUnavailableSuffix int64 = 91
+	// Decline code of payments declined by fake provider
+	FakeDeclineCode string = "insufficient_funds"
+)
+
+// Deterministic in-memory payment provider used for tests and local development.
+//
+// Every payment is authorized except for amounts ending with FakeDeclineSuffix (declined)
+// and FakeUnavailableSuffix (ErrUnavailable is returned), references are sequential.
+type FakeProvider struct {
+	mu       sync.Mutex
+	name     string
+	sequence int
+	payments map[string]*Result
+}
+
+func NewFakeProvider(name string) *FakeProvider {
+	/*Fake provider constructor function.*/
+	return &FakeProvider{name: name, payments: make(map[string]*Result)}
+}
+
+func (provider *FakeProvider) Name() string {
+	/*Return provider name.*/
+	return provider.name
+}
+
+func (provider *FakeProvider) Authorize(ctx context.Context, payment *Payment) (*Result, error) {
+	/*Authorize payment or decline it according to it's amount.*/
+	var result *Result
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if payment.Amount%100 == FakeUnavailableSuffix {
+		return nil, ErrUnavailable
+	}
+
+	provider.sequence++
+	result = &Result{
+		Reference: fmt.Sprintf("%s_%d", provider.name, provider.sequence),
+		Status:    StatusAuthorized,
+		Amount:    payment.Amount,
+	}
+	if payment.Amount%100 == FakeDeclineSuffix {
+		result.Status = StatusDeclined
+		result.DeclineCode = FakeDeclineCode
+	}
+	provider.payments[result.Reference] = result
+
+	return result.copy(), nil
+}
+
+func (provider *FakeProvider) Capture(ctx context.Context, reference string, amount int64) (*Result, error) {
+	/*Capture authorized payment, repeated capture returns current payment state.*/
+	return provider.update(reference, func(payment *Result) error {
+		switch {
+		case payment.Status == StatusCaptured:
+			return nil
+		case payment.Status != StatusAuthorized || amount > payment.Amount:
+			return ErrInvalidState
+		}
+
+		payment.Status = StatusCaptured
+		payment.Amount = amount
+		return nil
+	})
+}
+
+func (provider *FakeProvider) Void(ctx context.Context, reference string) (*Result, error) {
+	/*Void authorized payment, repeated void returns current payment state.*/
+	return provider.update(reference, func(payment *Result) error {
+		switch payment.Status {
+		case StatusVoided:
+			return nil
+		case StatusAuthorized:
+			payment.Status = StatusVoided
+			return nil
+		default:
+			return ErrInvalidState
+		}
+	})
+}
+
+func (provider *FakeProvider) Refund(ctx context.Context, reference string, amount int64) (*Result, error) {
+	/*Refund part of captured payment, payment becomes REFUNDED when the whole amount is refunded.*/
+	return provider.update(reference, func(payment *Result) error {
+		if payment.Status != StatusCaptured || payment.Refunded+amount > payment.Amount {
+			return ErrInvalidState
+		}
+
+		payment.Refunded += amount
+		if payment.Refunded == payment.Amount {
+			payment.Status = StatusRefunded
+		}
+		return nil
+	})
+}
+
+func (provider *FakeProvider) QueryStatus(ctx context.Context, reference string) (*Result, error) {
+	/*Return current payment state.*/
+	return provider.update(reference, func(payment *Result) error { return nil })
+}
+
+func (provider *FakeProvider) update(reference string, apply func(payment *Result) error) (*Result, error) {
+	/*Apply change to the payment under the lock and return copy of it's state.*/
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	payment, ok := provider.payments[reference]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	if err := apply(payment); err != nil {
+		return nil, err
+	}
+
+	return payment.copy(), nil
+}
+
+func (result *Result) copy() *Result {
+	/*Return copy of the result, so callers can not change stored payment.*/
+	var copied Result = *result
+
+	return &copied
+}
@@ -0,0 +1,101 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeProvider_Authorize(t *testing.T) {
+	// Arrange
+	testTable := []struct {
+		name           string
+		amount         int64
+		expectedStatus string
+		expectedCode   string
+		expectedError  error
+	}{
+		{name: "Authorized", amount: 1500, expectedStatus: StatusAuthorized},
+		{name: "Declined", amount: 1551, expectedStatus: StatusDeclined, expectedCode: FakeDeclineCode},
+		{name: "Unavailable", amount: 1591, expectedError: ErrUnavailable},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var provider *FakeProvider = NewFakeProvider("fake")
+
+			// Act
+			result, err := provider.Authorize(context.Background(), &Payment{Amount: testCase.amount, Currency: "EUR"})
+
+			// Assert
+			if testCase.expectedError != nil {
+				assert.True(t, errors.Is(err, testCase.expectedError), err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "fake_1", result.Reference)
+			assert.Equal(t, testCase.expectedStatus, result.Status)
+			assert.Equal(t, testCase.expectedCode, result.DeclineCode)
+			assert.Equal(t, testCase.amount, result.Amount)
+		})
+	}
+}
+
+func TestFakeProvider_Operations(t *testing.T) {
+	// Arrange
+	var ctx context.Context = context.Background()
+	var provider *FakeProvider = NewFakeProvider("fake")
+
+	captured, _ := provider.Authorize(ctx, &Payment{Amount: 1000})
+	voided, _ := provider.Authorize(ctx, &Payment{Amount: 1000})
+	declined, _ := provider.Authorize(ctx, &Payment{Amount: 1051})
+
+	// Act & Assert
+	result, err := provider.Capture(ctx, captured.Reference, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCaptured, result.Status)
+
+	// repeated capture is idempotent
+	result, err = provider.Capture(ctx, captured.Reference, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCaptured, result.Status)
+
+	_, err = provider.Void(ctx, captured.Reference)
+	assert.True(t, errors.Is(err, ErrInvalidState), err)
+
+	result, err = provider.Void(ctx, voided.Reference)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusVoided, result.Status)
+
+	result, err = provider.Void(ctx, voided.Reference)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusVoided, result.Status)
+
+	_, err = provider.Capture(ctx, voided.Reference, 1000)
+	assert.True(t, errors.Is(err, ErrInvalidState), err)
+
+	_, err = provider.Capture(ctx, declined.Reference, 1051)
+	assert.True(t, errors.Is(err, ErrInvalidState), err)
+
+	result, err = provider.Refund(ctx, captured.Reference, 400)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCaptured, result.Status)
+	assert.Equal(t, int64(400), result.Refunded)
+
+	_, err = provider.Refund(ctx, captured.Reference, 700)
+	assert.True(t, errors.Is(err, ErrInvalidState), err)
+
+	result, err = provider.Refund(ctx, captured.Reference, 600)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRefunded, result.Status)
+
+	result, err = provider.QueryStatus(ctx, captured.Reference)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRefunded, result.Status)
+	assert.Equal(t, int64(1000), result.Refunded)
+
+	_, err = provider.QueryStatus(ctx, "fake_100")
+	assert.True(t, errors.Is(err, ErrPaymentNotFound), err)
+}
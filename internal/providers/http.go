@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Payment provider which calls sandbox API (see SandboxHandler) over HTTP
+type HTTPProvider struct {
+	name    string
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPProvider(name string, baseURL string, client *http.Client) *HTTPProvider {
+	/*HTTP provider constructor function, client should have timeout set.*/
+	return &HTTPProvider{name: name, baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+func (provider *HTTPProvider) Name() string {
+	/*Return provider name.*/
+	return provider.name
+}
+
+func (provider *HTTPProvider) Authorize(ctx context.Context, payment *Payment) (*Result, error) {
+	/*Send payment authorization request.*/
+	return provider.do(ctx, "POST", "/payments/", payment)
+}
+
+func (provider *HTTPProvider) Capture(ctx context.Context, reference string, amount int64) (*Result, error) {
+	/*Send payment capture request.*/
+	return provider.do(ctx, "POST", paymentPath(reference, "capture"), &sandboxAmount{Amount: amount})
+}
+
+func (provider *HTTPProvider) Void(ctx context.Context, reference string) (*Result, error) {
+	/*Send payment void request.*/
+	return provider.do(ctx, "POST", paymentPath(reference, "void"), nil)
+}
+
+func (provider *HTTPProvider) Refund(ctx context.Context, reference string, amount int64) (*Result, error) {
+	/*Send payment refund request.*/
+	return provider.do(ctx, "POST", paymentPath(reference, "refund"), &sandboxAmount{Amount: amount})
+}
+
+func (provider *HTTPProvider) QueryStatus(ctx context.Context, reference string) (*Result, error) {
+	/*Send payment status request.*/
+	return provider.do(ctx, "GET", paymentPath(reference, ""), nil)
+}
+
+func (provider *HTTPProvider) do(ctx context.Context, method string, path string, body interface{}) (*Result, error) {
+	/*
+		Send JSON request to the API and parse payment result.
+
+		Network errors and 5xx responses are reported as ErrUnavailable,
+		404 and 409 responses as ErrPaymentNotFound and ErrInvalidState.
+	*/
+	var result Result
+	var data []byte = []byte("{}")
+	var err error
+
+	if body != nil {
+		if data, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, provider.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := provider.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return nil, ErrPaymentNotFound
+	case response.StatusCode == http.StatusConflict:
+		return nil, ErrInvalidState
+	case response.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("%w: %s responded with %d", ErrUnavailable, provider.name, response.StatusCode)
+	case response.StatusCode >= http.StatusBadRequest:
+		return nil, fmt.Errorf("%s rejected %s %s with %d", provider.name, method, path, response.StatusCode)
+	}
+
+	if err = json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%s response parsing failed: %w", provider.name, err)
+	}
+
+	return &result, nil
+}
+
+func paymentPath(reference string, operation string) string {
+	/*Return API path of the payment operation (payment itself if operation is empty).*/
+	var path string = "/payments/" + url.PathEscape(reference) + "/"
+
+	if operation != "" {
+		path += operation + "/"
+	}
+
+	return path
+}
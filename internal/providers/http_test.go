@@ -0,0 +1,69 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPProvider(t *testing.T) {
+	// Arrange
+	var ctx context.Context = context.Background()
+	var server *httptest.Server = httptest.NewServer(NewSandboxHandler(NewFakeProvider("sandbox")))
+	defer server.Close()
+
+	var provider *HTTPProvider = NewHTTPProvider("sandbox", server.URL+"/", server.Client())
+
+	// Act & Assert
+	authorized, err := provider.Authorize(ctx, &Payment{Amount: 1000, Currency: "EUR", UserId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "sandbox_1", authorized.Reference)
+	assert.Equal(t, StatusAuthorized, authorized.Status)
+
+	declined, err := provider.Authorize(ctx, &Payment{Amount: 1051, Currency: "EUR"})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDeclined, declined.Status)
+	assert.Equal(t, FakeDeclineCode, declined.DeclineCode)
+
+	_, err = provider.Authorize(ctx, &Payment{Amount: 1091, Currency: "EUR"})
+	assert.True(t, errors.Is(err, ErrUnavailable), err)
+
+	result, err := provider.Capture(ctx, authorized.Reference, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCaptured, result.Status)
+
+	result, err = provider.Refund(ctx, authorized.Reference, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRefunded, result.Status)
+
+	_, err = provider.Void(ctx, authorized.Reference)
+	assert.True(t, errors.Is(err, ErrInvalidState), err)
+
+	result, err = provider.QueryStatus(ctx, authorized.Reference)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRefunded, result.Status)
+
+	_, err = provider.QueryStatus(ctx, "sandbox_100")
+	assert.True(t, errors.Is(err, ErrPaymentNotFound), err)
+}
+
+func TestHTTPProvider_Unavailable(t *testing.T) {
+	// Arrange
+	var server *httptest.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	var provider *HTTPProvider = NewHTTPProvider("sandbox", server.URL, server.Client())
+
+	// Act
+	_, responseErr := provider.QueryStatus(context.Background(), "sandbox_1")
+	server.Close()
+	_, networkErr := provider.QueryStatus(context.Background(), "sandbox_1")
+
+	// Assert
+	assert.True(t, errors.Is(responseErr, ErrUnavailable), responseErr)
+	assert.True(t, errors.Is(networkErr, ErrUnavailable), networkErr)
+}
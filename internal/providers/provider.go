@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// Payment statuses reported by providers
+	StatusAuthorized string = "AUTHORIZED"
+	StatusDeclined   string = "DECLINED"
+	StatusCaptured   string = "CAPTURED"
+	StatusVoided     string = "VOIDED"
+	StatusRefunded   string = "REFUNDED"
+)
+
+var (
+	// Error returned when provider does not know payment with the reference
+	ErrPaymentNotFound = errors.New("payment not found")
+	// Error returned when payment status does not allow the operation (e.g. capture of voided payment)
+	ErrInvalidState = errors.New("payment status does not allow the operation")
+	// Error returned when provider can not process the request now, operation can be retried
+	ErrUnavailable = errors.New("payment provider is unavailable")
+)
+
+// Adapter of external payment provider, amounts are in minor units of payment currency
+type PaymentProvider interface {
+	Name() string
+	// reserve payment amount, declined payment is returned with DECLINED status (not error)
+	Authorize(ctx context.Context, payment *Payment) (*Result, error)
+	// charge authorized amount
+	Capture(ctx context.Context, reference string, amount int64) (*Result, error)
+	// release authorized amount
+	Void(ctx context.Context, reference string) (*Result, error)
+	// return (part of) captured amount
+	Refund(ctx context.Context, reference string, amount int64) (*Result, error)
+	QueryStatus(ctx context.Context, reference string) (*Result, error)
+}
+
+// Payment data sent to provider for authorization
+type Payment struct {
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	UserId    int    `json:"user_id"`
+	UserEmail string `json:"user_email"`
+}
+
+// Payment state reported by provider after the operation
+type Result struct {
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Amount    int64  `json:"amount"`
+	// total refunded amount of captured payment
+	Refunded int64 `json:"refunded"`
+	// machine-readable reason of declined payment
+	DeclineCode string `json:"decline_code,omitempty"`
+}
@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Set of payment providers with routing rules, payment provider is selected by merchant,
+// then by currency and default provider is used if there are no matching rules
+type Registry struct {
+	mu              sync.RWMutex
+	providers       map[string]PaymentProvider
+	defaultProvider string
+	currencyRoutes  map[string]string
+	merchantRoutes  map[string]string
+}
+
+func NewRegistry(defaultProvider PaymentProvider, providers ...PaymentProvider) *Registry {
+	/*Providers registry constructor function, default provider is registered as well.*/
+	var registry *Registry = &Registry{
+		providers:       make(map[string]PaymentProvider),
+		defaultProvider: defaultProvider.Name(),
+		currencyRoutes:  make(map[string]string),
+		merchantRoutes:  make(map[string]string),
+	}
+
+	registry.Register(defaultProvider)
+	for _, provider := range providers {
+		registry.Register(provider)
+	}
+
+	return registry
+}
+
+func (registry *Registry) Register(provider PaymentProvider) {
+	/*Add provider to the registry, provider with the same name is replaced.*/
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.providers[provider.Name()] = provider
+}
+
+func (registry *Registry) RouteCurrency(currency string, name string) error {
+	/*Use registered provider with the name for payments in the currency.*/
+	return registry.route(registry.currencyRoutes, currency, name)
+}
+
+func (registry *Registry) RouteMerchant(merchantId string, name string) error {
+	/*Use registered provider with the name for payments of the merchant (takes precedence over currency).*/
+	return registry.route(registry.merchantRoutes, merchantId, name)
+}
+
+func (registry *Registry) Select(merchantId string, currency string) PaymentProvider {
+	/*Return provider for payment of the merchant (empty if unknown) in the currency.*/
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	if name, ok := registry.merchantRoutes[merchantId]; ok && merchantId != "" {
+		return registry.providers[name]
+	}
+	if name, ok := registry.currencyRoutes[currency]; ok {
+		return registry.providers[name]
+	}
+
+	return registry.providers[registry.defaultProvider]
+}
+
+func (registry *Registry) Get(name string) (PaymentProvider, error) {
+	/*Return registered provider by name, used to continue processing of existing payments.*/
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	provider, ok := registry.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("payment provider %q is not registered", name)
+	}
+
+	return provider, nil
+}
+
+func (registry *Registry) route(routes map[string]string, key string, name string) error {
+	/*Add routing rule to registered provider.*/
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, ok := registry.providers[name]; !ok {
+		return fmt.Errorf("payment provider %q is not registered", name)
+	}
+	routes[key] = name
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Select(t *testing.T) {
+	// Arrange
+	var registry *Registry = NewRegistry(NewFakeProvider("fake"), NewFakeProvider("usd"), NewFakeProvider("merchant"))
+
+	assert.NoError(t, registry.RouteCurrency("USD", "usd"))
+	assert.NoError(t, registry.RouteMerchant("42", "merchant"))
+	assert.Error(t, registry.RouteCurrency("GBP", "unknown"))
+	assert.Error(t, registry.RouteMerchant("43", "unknown"))
+
+	testTable := []struct {
+		name         string
+		merchantId   string
+		currency     string
+		expectedName string
+	}{
+		{name: "Default", currency: "EUR", expectedName: "fake"},
+		{name: "Currency", currency: "USD", expectedName: "usd"},
+		{name: "Merchant", merchantId: "42", currency: "EUR", expectedName: "merchant"},
+		{name: "Merchant over currency", merchantId: "42", currency: "USD", expectedName: "merchant"},
+		{name: "Unknown merchant", merchantId: "43", currency: "USD", expectedName: "usd"},
+		{name: "Unroutable currency", currency: "GBP", expectedName: "fake"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Act
+			var provider PaymentProvider = registry.Select(testCase.merchantId, testCase.currency)
+
+			// Assert
+			assert.Equal(t, testCase.expectedName, provider.Name())
+		})
+	}
+}
+
+func TestRegistry_Get(t *testing.T) {
+	// Arrange
+	var registry *Registry = NewRegistry(NewFakeProvider("fake"))
+
+	// Act
+	provider, err := registry.Get("fake")
+	_, unknownErr := registry.Get("unknown")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", provider.Name())
+	assert.Error(t, unknownErr)
+}
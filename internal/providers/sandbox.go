@@ -0,0 +1,129 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Error codes of sandbox API responses
+const (
+	sandboxCodeBadRequest   string = "bad_request"
+	sandboxCodeNotFound     string = "payment_not_found"
+	sandboxCodeInvalidState string = "invalid_state"
+	sandboxCodeUnavailable  string = "unavailable"
+)
+
+// Request body of sandbox capture and refund operations
+type sandboxAmount struct {
+	Amount int64 `json:"amount"`
+}
+
+// Error response body of sandbox API
+type sandboxError struct {
+	Code string `json:"code"`
+}
+
+// Local HTTP API of payment provider, exposes wrapped provider (usually fake one) operations:
+//
+//	POST /payments/ - authorize payment
+//	GET /payments/{reference}/ - query payment status
+//	POST /payments/{reference}/capture/, /void/, /refund/ - payment operations
+type SandboxHandler struct {
+	provider PaymentProvider
+	router   *mux.Router
+}
+
+func NewSandboxHandler(provider PaymentProvider) *SandboxHandler {
+	/*Sandbox API handler constructor function.*/
+	var handler *SandboxHandler = &SandboxHandler{provider: provider, router: mux.NewRouter()}
+	var payments *mux.Router = handler.router.PathPrefix("/payments").Subrouter()
+
+	payments.HandleFunc("/", handler.authorize).Methods("POST")
+	payments.HandleFunc("/{reference}/", handler.queryStatus).Methods("GET")
+	payments.HandleFunc("/{reference}/capture/", handler.capture).Methods("POST")
+	payments.HandleFunc("/{reference}/void/", handler.void).Methods("POST")
+	payments.HandleFunc("/{reference}/refund/", handler.refund).Methods("POST")
+
+	return handler
+}
+
+func (handler *SandboxHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	/*Dispatch request to sandbox API operation.*/
+	handler.router.ServeHTTP(w, r)
+}
+
+func (handler *SandboxHandler) authorize(w http.ResponseWriter, r *http.Request) {
+	/*Handle payment authorization request.*/
+	var payment Payment
+
+	if err := json.NewDecoder(r.Body).Decode(&payment); err != nil || payment.Amount <= 0 {
+		writeSandboxError(w, http.StatusBadRequest, sandboxCodeBadRequest)
+		return
+	}
+
+	result, err := handler.provider.Authorize(r.Context(), &payment)
+	writeSandboxResult(w, result, err)
+}
+
+func (handler *SandboxHandler) queryStatus(w http.ResponseWriter, r *http.Request) {
+	/*Handle payment status request.*/
+	result, err := handler.provider.QueryStatus(r.Context(), mux.Vars(r)["reference"])
+	writeSandboxResult(w, result, err)
+}
+
+func (handler *SandboxHandler) capture(w http.ResponseWriter, r *http.Request) {
+	/*Handle payment capture request.*/
+	var body sandboxAmount
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Amount <= 0 {
+		writeSandboxError(w, http.StatusBadRequest, sandboxCodeBadRequest)
+		return
+	}
+
+	result, err := handler.provider.Capture(r.Context(), mux.Vars(r)["reference"], body.Amount)
+	writeSandboxResult(w, result, err)
+}
+
+func (handler *SandboxHandler) void(w http.ResponseWriter, r *http.Request) {
+	/*Handle payment void request.*/
+	result, err := handler.provider.Void(r.Context(), mux.Vars(r)["reference"])
+	writeSandboxResult(w, result, err)
+}
+
+func (handler *SandboxHandler) refund(w http.ResponseWriter, r *http.Request) {
+	/*Handle payment refund request.*/
+	var body sandboxAmount
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Amount <= 0 {
+		writeSandboxError(w, http.StatusBadRequest, sandboxCodeBadRequest)
+		return
+	}
+
+	result, err := handler.provider.Refund(r.Context(), mux.Vars(r)["reference"], body.Amount)
+	writeSandboxResult(w, result, err)
+}
+
+func writeSandboxResult(w http.ResponseWriter, result *Result, err error) {
+	/*Write operation result or map provider error to response status.*/
+	switch {
+	case err == nil:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	case errors.Is(err, ErrPaymentNotFound):
+		writeSandboxError(w, http.StatusNotFound, sandboxCodeNotFound)
+	case errors.Is(err, ErrInvalidState):
+		writeSandboxError(w, http.StatusConflict, sandboxCodeInvalidState)
+	default:
+		writeSandboxError(w, http.StatusServiceUnavailable, sandboxCodeUnavailable)
+	}
+}
+
+func writeSandboxError(w http.ResponseWriter, status int, code string) {
+	/*Write sandbox error response.*/
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(sandboxError{Code: code})
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+)
+
+var apiKeyTableName = "api_key"
+
+type ApiKeyPostgresRepository struct {
+	db *database.PostgresDB
+}
+
+func NewApiKeyPostgresRepository(db *database.PostgresDB) *ApiKeyPostgresRepository {
+	/*API key postgres repository constructor function.*/
+	return &ApiKeyPostgresRepository{db: db}
+}
+
+func (repo *ApiKeyPostgresRepository) CreateApiKey(apiKey *models.ApiKey) (*models.ApiKey, error) {
+	/*Insert new API key data to DB and return API key struct filled with new key data.*/
+
+	// build query string
+	query := fmt.Sprintf(
+		"INSERT INTO %s (provider, prefix, key_hash, scopes) values ($1, $2, $3, $4) RETURNING *", apiKeyTableName)
+
+	// evalate insert query and parse new row data to API key struct
+	err := repo.db.QueryRowx(query, apiKey.Provider, apiKey.Prefix, apiKey.KeyHash, apiKey.Scopes).StructScan(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (repo *ApiKeyPostgresRepository) GetApiKeys() ([]*models.ApiKey, error) {
+	/*Return slice of all API key structs retrieved from db.*/
+	var apiKeys []*models.ApiKey = make([]*models.ApiKey, 0)
+
+	// build query string
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY id;", apiKeyTableName)
+
+	// evaluate query and parse data to slice of API key structs
+	if err := repo.db.Select(&apiKeys, query); err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (repo *ApiKeyPostgresRepository) GetActiveApiKeyByHash(keyHash string) (*models.ApiKey, error) {
+	/*Retrieve not revoked API key by hash of the key.*/
+	var apiKey models.ApiKey = models.ApiKey{}
+
+	// build query string
+	query := fmt.Sprintf("SELECT * FROM %s WHERE key_hash = $1 AND revoked_at IS NULL;", apiKeyTableName)
+
+	// evaluate query and parse data to API key struct
+	if err := repo.db.Get(&apiKey, query, keyHash); err != nil {
+		return nil, notFoundError(err, services.ErrInvalidApiKey)
+	}
+
+	return &apiKey, nil
+}
+
+func (repo *ApiKeyPostgresRepository) TouchApiKey(apiKeyId int) error {
+	/*
+		Update API key last usage time.
+
+		Time is updated at most once a minute to avoid write on every request.
+	*/
+
+	// build query string
+	query := fmt.Sprintf(
+		`UPDATE %s SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');`,
+		apiKeyTableName,
+	)
+
+	_, err := repo.db.Exec(query, apiKeyId)
+
+	return err
+}
+
+func (repo *ApiKeyPostgresRepository) RevokeApiKey(apiKeyId int) (*models.ApiKey, error) {
+	/*Revoke API key, revoked keys are kept to show them in keys list.*/
+	var apiKey models.ApiKey = models.ApiKey{}
+
+	// build query string
+	query := fmt.Sprintf(
+		"UPDATE %s SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL RETURNING *;", apiKeyTableName)
+
+	// evaluate query and parse data to API key struct
+	if err := repo.db.Get(&apiKey, query, apiKeyId); err != nil {
+		return nil, notFoundError(err, services.ErrApiKeyNotFound)
+	}
+
+	return &apiKey, nil
+}
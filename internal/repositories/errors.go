@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+func notFoundError(err error, notFound error) error {
+	/*Replace "no rows" error of database driver with passed service error.*/
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+
+	return err
+}
+
+func constraintError(err error, constraint string, violated error) error {
+	/*Replace error of database driver caused by violation of the named constraint (or unique index) with passed service error.*/
+	var pqErr *pq.Error
+
+	if errors.As(err, &pqErr) && pqErr.Constraint == constraint {
+		return violated
+	}
+
+	return err
+}
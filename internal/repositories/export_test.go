@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionPostgresRepository_ExportTransactions(t *testing.T) {
+	// Arrange
+	const total = exportBatchSize + 3
+	var amounts []int64
+
+	db := newTestPostgresDB(t)
+	defer db.Close()
+
+	user := newTestUser(t, db)
+	repo := NewTransactionPostgresRepository(db)
+
+	// errored transactions do not need wallet funds
+	for i := total; i > 0; i-- {
+		_, err := repo.CreateTransaction(&models.Transaction{
+			UserId:    user.Id,
+			UserEmail: user.Email,
+			Amount:    int64(i),
+			Currency:  "EUR",
+			Status:    services.TransactionErrorStatus,
+		}, nil)
+		require.NoError(t, err)
+	}
+	filter := &models.TransactionSearchFilter{UserId: &user.Id, Sort: "amount", Order: "asc"}
+
+	// Act
+	err := repo.ExportTransactions(context.Background(), filter, func(transaction *models.Transaction) error {
+		amounts = append(amounts, transaction.Amount)
+		return nil
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, amounts, total)
+	for i, amount := range amounts {
+		assert.Equal(t, int64(i+1), amount)
+	}
+
+	t.Run("Test export is stopped when context is done", func(t *testing.T) {
+		var exported int
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := repo.ExportTransactions(ctx, filter, func(transaction *models.Transaction) error {
+			// client gone away after the first row
+			exported++
+			cancel()
+			return nil
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, exported, total)
+	})
+}
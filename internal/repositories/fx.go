@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/models"
+)
+
+var (
+	fxRateTableName       = "fx_rate"
+	fxConversionTableName = "fx_conversion"
+)
+
+type FxPostgresRepository struct {
+	db *database.PostgresDB
+}
+
+func NewFxPostgresRepository(db *database.PostgresDB) *FxPostgresRepository {
+	/*FX postgres repository constructor function.*/
+	return &FxPostgresRepository{db: db}
+}
+
+func (repo *FxPostgresRepository) SaveFxRates(rates []*models.FxRate) (int, error) {
+	/*
+		Insert FX rates to DB within one db transaction, return amount of inserted or changed rates.
+
+		Existing rate of the same currencies pair and date is replaced by the new one.
+	*/
+	var saved int
+
+	// start new db transaction
+	dbTransaction, err := repo.db.Beginx()
+
+	if err != nil {
+		return 0, err
+	}
+
+	// build query string
+	query := fmt.Sprintf(
+		`INSERT INTO %[1]s (base_currency, quote_currency, rate, effective_date, source) values ($1, $2, $3, $4, $5)
+		ON CONFLICT (base_currency, quote_currency, effective_date) DO UPDATE
+		SET rate = EXCLUDED.rate, source = EXCLUDED.source, updated_at = now()::timestamptz
+		WHERE %[1]s.rate <> EXCLUDED.rate OR %[1]s.source <> EXCLUDED.source;`,
+		fxRateTableName)
+
+	for _, rate := range rates {
+		result, err := dbTransaction.Exec(
+			query, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.EffectiveDate, rate.Source)
+		if err != nil {
+			// roll back db transaction, rates are saved all together or not at all
+			dbTransaction.Rollback()
+			return 0, err
+		}
+
+		affected, _ := result.RowsAffected()
+		saved += int(affected)
+	}
+
+	return saved, dbTransaction.Commit()
+}
+
+func (repo *FxPostgresRepository) GetUserTransactionsFxRates(
+	userId int, currency string, filter *models.TransactionFilter,
+) ([]*models.TransactionFxRate, error) {
+	/*Return page of user transactions with rates to the currency effective at transactions creation dates.*/
+	var transactions []*models.TransactionFxRate = make([]*models.TransactionFxRate, 0)
+
+	// build query string
+	pageQuery, args := userTransactionsQuery("user_id", userId, filter)
+	args = append(args, currency)
+	query := fmt.Sprintf(
+		`SELECT page.*, rate.rate_id, rate.rate, rate.rate_date FROM (%s) page %s
+		ORDER BY page.created_at DESC, page.id DESC;`,
+		pageQuery, effectiveFxRateJoin(len(args)))
+
+	// evaluate query and parse data to slice of transaction structs
+	if err := repo.db.Select(&transactions, query, args...); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (repo *FxPostgresRepository) GetUserTransactionsFxTotals(
+	userId int, currency string, filter *models.TransactionFilter,
+) ([]*models.TransactionFxRateTotal, error) {
+	/*Return sums of user transactions amounts grouped by currency and rate to the currency.*/
+	var totals []*models.TransactionFxRateTotal = make([]*models.TransactionFxRateTotal, 0)
+
+	// build query string
+	pageQuery, args := userTransactionsQuery("user_id", userId, filter)
+	args = append(args, currency)
+	query := fmt.Sprintf(
+		`SELECT page.currency, sum(page.amount) AS amount, count(*) AS count, rate.rate_id, rate.rate, rate.rate_date
+		FROM (%s) page %s
+		GROUP BY page.currency, rate.rate_id, rate.rate, rate.rate_date;`,
+		pageQuery, effectiveFxRateJoin(len(args)))
+
+	// evaluate query and parse data to slice of total structs
+	if err := repo.db.Select(&totals, query, args...); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func (repo *FxPostgresRepository) CreateFxConversions(conversions []*models.FxConversion) error {
+	/*Record conversions for audit within one db transaction, already recorded conversions are skipped.*/
+
+	// start new db transaction
+	dbTransaction, err := repo.db.Beginx()
+
+	if err != nil {
+		return err
+	}
+
+	// build query string
+	query := fmt.Sprintf(
+		`INSERT INTO %s (transaction_id, rate_id, from_currency, to_currency, rate, amount, converted_amount)
+		values ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (transaction_id, rate_id, to_currency, rate) DO NOTHING;`,
+		fxConversionTableName)
+
+	for _, conversion := range conversions {
+		_, err = dbTransaction.Exec(
+			query,
+			conversion.TransactionId,
+			conversion.RateId,
+			conversion.FromCurrency,
+			conversion.ToCurrency,
+			conversion.Rate,
+			conversion.Amount,
+			conversion.ConvertedAmount,
+		)
+		if err != nil {
+			dbTransaction.Rollback()
+			return err
+		}
+	}
+
+	return dbTransaction.Commit()
+}
+
+func effectiveFxRateJoin(currencyArg int) string {
+	/*
+		Build join of the latest rate from transaction currency to the currency passed as positional argument,
+		effective at transaction creation date (UTC).
+
+		Reverse pair rate is inverted if direct one does not exist, nothing is joined for the same currency.
+	*/
+	return fmt.Sprintf(
+		`LEFT JOIN LATERAL (
+			SELECT fx.id AS rate_id,
+				CASE WHEN fx.base_currency = page.currency THEN fx.rate ELSE 1 / fx.rate END AS rate,
+				fx.effective_date AS rate_date
+			FROM %[1]s fx
+			WHERE fx.effective_date <= (page.created_at AT TIME ZONE 'UTC')::date AND (
+				(fx.base_currency = page.currency AND fx.quote_currency = $%[2]d) OR
+				(fx.base_currency = $%[2]d AND fx.quote_currency = page.currency)
+			)
+			ORDER BY fx.effective_date DESC, fx.base_currency = page.currency DESC
+			LIMIT 1
+		) rate ON page.currency <> $%[2]d`,
+		fxRateTableName, currencyArg)
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/models"
+)
+
+var idempotencyKeyTableName = "idempotency_key"
+
+type IdempotencyKeyPostgresRepository struct {
+	db *database.PostgresDB
+}
+
+func NewIdempotencyKeyPostgresRepository(db *database.PostgresDB) *IdempotencyKeyPostgresRepository {
+	/*Idempotency key postgres repository constructor function.*/
+	return &IdempotencyKeyPostgresRepository{db: db}
+}
+
+func (repo *IdempotencyKeyPostgresRepository) ReserveIdempotencyKey(idempotencyKey *models.IdempotencyKey) (bool, error) {
+	/*
+		Insert new idempotency key record, expired record with the same subject and key is replaced.
+
+		Return false if the key is already reserved by another (not expired) request of the subject.
+	*/
+	var reservedKey string
+
+	// build query string
+	query := fmt.Sprintf(
+		`INSERT INTO %[1]s (subject, key, request_hash, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subject, key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			response_status = NULL,
+			response_headers = NULL,
+			response_body = NULL,
+			created_at = now()::timestamptz,
+			expires_at = EXCLUDED.expires_at
+		WHERE %[1]s.expires_at <= now()::timestamptz
+		RETURNING key;`,
+		idempotencyKeyTableName)
+
+	// evaluate insert query, no rows returned means conflict with not expired key
+	err := repo.db.Get(
+		&reservedKey,
+		query,
+		idempotencyKey.Subject,
+		idempotencyKey.Key,
+		idempotencyKey.RequestHash,
+		idempotencyKey.ExpiresAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo *IdempotencyKeyPostgresRepository) GetIdempotencyKey(subject string, key string) (*models.IdempotencyKey, error) {
+	/*Return idempotency key struct retrieved from db by subject and key.*/
+	var idempotencyKey models.IdempotencyKey = models.IdempotencyKey{}
+	var headers []byte
+
+	// build query string
+	query := fmt.Sprintf(
+		`SELECT subject, key, request_hash, response_status, response_headers, response_body, created_at, expires_at
+		FROM %s WHERE subject = $1 AND key = $2`,
+		idempotencyKeyTableName)
+
+	// evaluate query and parse data to idempotency key struct
+	err := repo.db.QueryRowx(query, subject, key).Scan(
+		&idempotencyKey.Subject,
+		&idempotencyKey.Key,
+		&idempotencyKey.RequestHash,
+		&idempotencyKey.ResponseStatus,
+		&headers,
+		&idempotencyKey.ResponseBody,
+		&idempotencyKey.CreatedAt,
+		&idempotencyKey.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if headers != nil {
+		if err = json.Unmarshal(headers, &idempotencyKey.ResponseHeaders); err != nil {
+			return nil, err
+		}
+	}
+
+	return &idempotencyKey, nil
+}
+
+func (repo *IdempotencyKeyPostgresRepository) SaveIdempotencyKeyResponse(
+	subject string, key string, status int, headers map[string][]string, body []byte,
+) error {
+	/*Store response status code, headers and body for the reserved idempotency key.*/
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+
+	// build query string
+	query := fmt.Sprintf(
+		`UPDATE %s SET response_status = $1, response_headers = $2, response_body = $3
+		WHERE subject = $4 AND key = $5;`,
+		idempotencyKeyTableName)
+
+	_, err = repo.db.Exec(query, status, string(encodedHeaders), body, subject, key)
+
+	return err
+}
+
+func (repo *IdempotencyKeyPostgresRepository) DeleteIdempotencyKey(subject string, key string) error {
+	/*Remove idempotency key record, so the request with this key can be performed again.*/
+
+	// build query string
+	query := fmt.Sprintf("DELETE FROM %s WHERE subject = $1 AND key = $2;", idempotencyKeyTableName)
+
+	_, err := repo.db.Exec(query, subject, key)
+
+	return err
+}
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/ledger"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ledgerAccountTableName = "ledger_account"
+	ledgerEntryTableName   = "ledger_entry"
+	ledgerPostingTableName = "ledger_posting"
+)
+
+type LedgerPostgresRepository struct {
+	db *database.PostgresDB
+}
+
+func NewLedgerPostgresRepository(db *database.PostgresDB) *LedgerPostgresRepository {
+	/*Ledger postgres repository constructor function.*/
+	return &LedgerPostgresRepository{db: db}
+}
+
+func (repo *LedgerPostgresRepository) GetLedgerAccountBalances() ([]*models.LedgerAccountBalance, error) {
+	/*Return slice of all ledger accounts with their balances.*/
+	var balances []*models.LedgerAccountBalance = make([]*models.LedgerAccountBalance, 0)
+
+	// build query string
+	query := fmt.Sprintf("%s GROUP BY account.id ORDER BY account.code, account.currency;", ledgerBalanceQuery())
+
+	// evaluate query and parse data to slice of balance structs
+	if err := repo.db.Select(&balances, query); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+func (repo *LedgerPostgresRepository) GetLedgerAccountBalance(accountId int) (*models.LedgerAccountBalance, error) {
+	/*Return ledger account with it's balance retrieved from db by PK.*/
+	var balance models.LedgerAccountBalance = models.LedgerAccountBalance{}
+
+	// build query string
+	query := fmt.Sprintf("%s WHERE account.id = $1 GROUP BY account.id;", ledgerBalanceQuery())
+
+	// evaluate query and parse data to balance struct
+	if err := repo.db.Get(&balance, query, accountId); err != nil {
+		return nil, notFoundError(err, services.ErrLedgerAccountNotFound)
+	}
+
+	return &balance, nil
+}
+
+func (repo *LedgerPostgresRepository) GetLedgerAccountEntries(
+	accountId int, filter *models.LedgerEntryFilter,
+) ([]*models.LedgerEntry, error) {
+	/*Return page of journal entries with postings to the account, every entry is filled with all it's postings.*/
+	var entries []*models.LedgerEntry = make([]*models.LedgerEntry, 0)
+	var postings []*models.LedgerPosting = make([]*models.LedgerPosting, 0)
+	var entriesById map[int]*models.LedgerEntry
+	var entryIds []int
+	var args []interface{} = []interface{}{accountId, filter.Limit}
+	var after string
+
+	// keyset pagination, retrieve entries placed after the cursor
+	if filter.After != nil {
+		args = append(args, filter.After.CreatedAt, filter.After.Id)
+		after = "AND (entry.created_at, entry.id) < ($3, $4)"
+	}
+
+	// build query string
+	query := fmt.Sprintf(
+		`SELECT entry.* FROM %s entry
+		WHERE EXISTS (SELECT 1 FROM %s posting WHERE posting.entry_id = entry.id AND posting.account_id = $1) %s
+		ORDER BY entry.created_at DESC, entry.id DESC LIMIT $2;`,
+		ledgerEntryTableName, ledgerPostingTableName, after)
+
+	// evaluate query and parse data to slice of entry structs
+	if err := repo.db.Select(&entries, query, args...); err != nil {
+		return nil, err
+	}
+
+	entriesById = make(map[int]*models.LedgerEntry, len(entries))
+	for _, entry := range entries {
+		entry.Postings = make([]*models.LedgerPosting, 0, 2)
+		entriesById[entry.Id] = entry
+		entryIds = append(entryIds, entry.Id)
+	}
+
+	// build query string
+	query = fmt.Sprintf(
+		`SELECT posting.*, account.code AS account_code, account.currency FROM %s posting
+		JOIN %s account ON account.id = posting.account_id
+		WHERE posting.entry_id = ANY($1) ORDER BY posting.id;`,
+		ledgerPostingTableName, ledgerAccountTableName)
+
+	// evaluate query and parse data to slice of posting structs
+	if err := repo.db.Select(&postings, query, pq.Array(entryIds)); err != nil {
+		return nil, err
+	}
+
+	for _, posting := range postings {
+		entriesById[posting.EntryId].Postings = append(entriesById[posting.EntryId].Postings, posting)
+	}
+
+	return entries, nil
+}
+
+func insertLedgerEntry(dbTransaction *sqlx.Tx, entry *models.LedgerEntry) error {
+	/*
+		Insert balanced journal entry with it's postings within db transaction.
+
+		Accounts are created on the first posting to them.
+	*/
+	if err := ledger.Validate(entry); err != nil {
+		return err
+	}
+
+	// build query string
+	query := fmt.Sprintf(
+		"INSERT INTO %s (kind, transaction_id, refund_id, wallet_id) values ($1, $2, $3, $4) RETURNING *",
+		ledgerEntryTableName)
+
+	// evalate insert query and parse new row data to entry struct
+	row := dbTransaction.QueryRowx(query, entry.Kind, entry.TransactionId, entry.RefundId, entry.WalletId)
+	err := row.StructScan(entry)
+	if err != nil {
+		return err
+	}
+
+	// build query string
+	query = fmt.Sprintf(
+		"INSERT INTO %s (entry_id, account_id, amount) values ($1, $2, $3) RETURNING id", ledgerPostingTableName)
+
+	for _, posting := range entry.Postings {
+		if posting.AccountId, err = ledgerAccountId(dbTransaction, posting.AccountCode, posting.Currency); err != nil {
+			return err
+		}
+		posting.EntryId = entry.Id
+
+		if err = dbTransaction.Get(&posting.Id, query, posting.EntryId, posting.AccountId, posting.Amount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ledgerAccountId(dbTransaction *sqlx.Tx, code string, currency string) (int, error) {
+	/*Return id of account with code and currency, account is created if it does not exist.*/
+	var accountId int
+
+	// build query string, account row is not locked, so postings to the same account are not serialized
+	query := fmt.Sprintf(
+		`WITH created AS (
+			INSERT INTO %[1]s (code, currency) values ($1, $2) ON CONFLICT (code, currency) DO NOTHING RETURNING id
+		)
+		SELECT id FROM created UNION ALL SELECT id FROM %[1]s WHERE code = $1 AND currency = $2 LIMIT 1;`,
+		ledgerAccountTableName)
+
+	err := dbTransaction.Get(&accountId, query, code, currency)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		// account was created by concurrent db transaction after the query snapshot was taken
+		err = dbTransaction.Get(&accountId, query, code, currency)
+	}
+
+	return accountId, err
+}
+
+func ledgerBalanceQuery() string {
+	/*Build query selecting accounts with sums of their postings, query must be completed with GROUP BY clause.*/
+	return fmt.Sprintf(
+		`SELECT account.*,
+			COALESCE(SUM(posting.amount) FILTER (WHERE posting.amount > 0), 0) AS debit,
+			COALESCE(-SUM(posting.amount) FILTER (WHERE posting.amount < 0), 0) AS credit,
+			COALESCE(SUM(posting.amount), 0) AS balance
+		FROM %s account
+		LEFT JOIN %s posting ON posting.account_id = account.id`,
+		ledgerAccountTableName, ledgerPostingTableName)
+}
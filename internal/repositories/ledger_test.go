@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/ledger"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerPostgresRepository_TransactionEntries(t *testing.T) {
+	// Arrange
+	db := newTestPostgresDB(t)
+	defer db.Close()
+
+	repo := NewTransactionPostgresRepository(db)
+	user := newTestUser(t, db)
+
+	// fund user wallet, so transaction amount can be held
+	_, err := NewWalletPostgresRepository(db).DepositWallet(user.Id, "EUR", 1500)
+	require.NoError(t, err)
+
+	transaction, err := repo.CreateTransaction(&models.Transaction{
+		UserId:    user.Id,
+		UserEmail: user.Email,
+		Amount:    1500,
+		Currency:  "EUR",
+		Status:    services.TransactionNewStatus,
+	}, nil)
+	require.NoError(t, err)
+
+	// Act
+	_, err = repo.UpdateTransactionStatus(transaction, services.TransactionSuccessStatus, nil)
+	require.NoError(t, err)
+
+	// Assert
+	var postings []struct {
+		Kind   string `db:"kind"`
+		Code   string `db:"code"`
+		Amount int64  `db:"amount"`
+	}
+	query := fmt.Sprintf(
+		`SELECT entry.kind, account.code, posting.amount FROM %s entry
+		JOIN %s posting ON posting.entry_id = entry.id
+		JOIN %s account ON account.id = posting.account_id
+		WHERE entry.transaction_id = $1 ORDER BY posting.id;`,
+		ledgerEntryTableName, ledgerPostingTableName, ledgerAccountTableName)
+	require.NoError(t, db.Select(&postings, query, transaction.Id))
+
+	require.Len(t, postings, 4)
+	assert.Equal(t, ledger.EntryAuthorize, postings[0].Kind)
+	assert.Equal(t, ledger.UserAccount(user.Id), postings[0].Code)
+	assert.Equal(t, int64(1500), postings[0].Amount)
+	assert.Equal(t, ledger.HoldsAccount, postings[1].Code)
+	assert.Equal(t, int64(-1500), postings[1].Amount)
+	assert.Equal(t, ledger.EntryCapture, postings[2].Kind)
+	assert.Equal(t, ledger.HoldsAccount, postings[2].Code)
+	assert.Equal(t, ledger.RevenueAccount, postings[3].Code)
+	assert.Equal(t, int64(-1500), postings[3].Amount)
+}
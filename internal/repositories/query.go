@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Builder of parameterised SELECT query, every passed value is bound as positional argument.
+// Table, column and condition strings are trusted SQL fragments and must never contain request data.
+type selectQuery struct {
+	columns    string
+	table      string
+	conditions []string
+	groupBy    string
+	orderBy    []string
+	args       []interface{}
+	limit      int
+}
+
+func newSelectQuery(table string) *selectQuery {
+	/*Select query builder constructor function, all columns of the table are selected by default.*/
+	return &selectQuery{columns: "*", table: table}
+}
+
+func (query *selectQuery) Columns(columns string, values ...interface{}) *selectQuery {
+	/*Replace list of selected columns, "?" in columns are bound to passed values as in Where.*/
+	query.columns = query.bindAll(columns, values)
+	return query
+}
+
+func (query *selectQuery) Where(condition string, values ...interface{}) *selectQuery {
+	/*
+		Add condition joined with others by AND.
+
+		Every "?" in condition is replaced by placeholder of the next positional argument bound to passed value.
+	*/
+	query.conditions = append(query.conditions, query.bindAll(condition, values))
+	return query
+}
+
+func (query *selectQuery) WhereIn(column string, values []interface{}) *selectQuery {
+	/*Add condition checking column value is one of passed values, nothing is added for empty values.*/
+	if len(values) == 0 {
+		return query
+	}
+
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = query.bind(value)
+	}
+	query.conditions = append(query.conditions, column+" IN ("+strings.Join(placeholders, ", ")+")")
+
+	return query
+}
+
+func (query *selectQuery) GroupBy(expressions string) *selectQuery {
+	/*Set GROUP BY clause.*/
+	query.groupBy = expressions
+	return query
+}
+
+func (query *selectQuery) OrderBy(column string, descending bool) *selectQuery {
+	/*Add ordering by column, rows are ordered by columns in order of adding.*/
+	if descending {
+		column += " DESC"
+	}
+	query.orderBy = append(query.orderBy, column)
+
+	return query
+}
+
+func (query *selectQuery) Limit(limit int) *selectQuery {
+	/*Set max number of selected rows, zero limit means no limit.*/
+	query.limit = limit
+	return query
+}
+
+func (query *selectQuery) Build() (string, []interface{}) {
+	/*Return query string without trailing semicolon (so it can be used as subquery) and it's arguments.*/
+	var builder strings.Builder
+	var args []interface{} = append([]interface{}{}, query.args...)
+
+	builder.WriteString("SELECT " + query.columns + " FROM " + query.table)
+
+	if len(query.conditions) > 0 {
+		builder.WriteString(" WHERE " + strings.Join(query.conditions, " AND "))
+	}
+	if query.groupBy != "" {
+		builder.WriteString(" GROUP BY " + query.groupBy)
+	}
+	if len(query.orderBy) > 0 {
+		builder.WriteString(" ORDER BY " + strings.Join(query.orderBy, ", "))
+	}
+	if query.limit > 0 {
+		args = append(args, query.limit)
+		builder.WriteString(" LIMIT $" + strconv.Itoa(len(args)))
+	}
+
+	return builder.String(), args
+}
+
+func (query *selectQuery) bindAll(fragment string, values []interface{}) string {
+	/*Replace every "?" in SQL fragment by placeholder of positional argument bound to the next passed value.*/
+	var builder strings.Builder
+	var parts []string = strings.Split(fragment, "?")
+
+	if len(parts)-1 != len(values) {
+		panic("selectQuery: number of placeholders does not match number of values in " + fragment)
+	}
+
+	builder.WriteString(parts[0])
+	for i, value := range values {
+		builder.WriteString(query.bind(value))
+		builder.WriteString(parts[i+1])
+	}
+
+	return builder.String()
+}
+
+func (query *selectQuery) bind(value interface{}) string {
+	/*Add positional argument and return it's placeholder.*/
+	query.args = append(query.args, value)
+	return "$" + strconv.Itoa(len(query.args))
+}
+
+func escapeLike(value string) string {
+	/*Escape LIKE pattern special characters, so value is matched literally.*/
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectQuery_Build(t *testing.T) {
+	query, args := newSelectQuery("transaction").
+		Where("user_id = ?", 1).
+		WhereIn("status", []interface{}{"NEW", "FAILED"}).
+		WhereIn("currency", nil).
+		Where("(created_at, id) < (?, ?)", "2022-06-12", 10).
+		OrderBy("created_at", true).
+		OrderBy("id", true).
+		Limit(50).
+		Build()
+
+	assert.Equal(t,
+		"SELECT * FROM transaction WHERE user_id = $1 AND status IN ($2, $3) AND (created_at, id) < ($4, $5)"+
+			" ORDER BY created_at DESC, id DESC LIMIT $6",
+		query)
+	assert.Equal(t, []interface{}{1, "NEW", "FAILED", "2022-06-12", 10, 50}, args)
+}
+
+func TestSelectQuery_WherePlaceholdersMismatch(t *testing.T) {
+	assert.Panics(t, func() { newSelectQuery("transaction").Where("user_id = ? AND id = ?", 1) })
+}
+
+func TestTransactionSearchQuery(t *testing.T) {
+	// Arrange
+	userId := 1
+	amountMin := int64(100)
+	createdAt := time.Date(2022, 6, 12, 0, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		filter        *models.TransactionSearchFilter
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "Test search without params",
+			filter:        &models.TransactionSearchFilter{Sort: "created_at", Order: "desc", Limit: 51},
+			expectedQuery: "SELECT * FROM transaction ORDER BY created_at DESC, id DESC LIMIT $1",
+			expectedArgs:  []interface{}{51},
+		},
+		{
+			name: "Test search with filters",
+			filter: &models.TransactionSearchFilter{
+				Statuses:  []string{"NEW", "SUCCESS"},
+				UserId:    &userId,
+				Email:     "50%_off",
+				AmountMin: &amountMin,
+				Sort:      "amount",
+				Order:     "asc",
+				Limit:     11,
+			},
+			expectedQuery: "SELECT * FROM transaction WHERE status IN ($1, $2) AND user_id = $3 AND user_email ILIKE $4" +
+				" AND amount >= $5 ORDER BY amount, id LIMIT $6",
+			expectedArgs: []interface{}{"NEW", "SUCCESS", 1, `%50\%\_off%`, int64(100), 11},
+		},
+		{
+			name: "Test search after cursor",
+			filter: &models.TransactionSearchFilter{
+				Sort:  "updated_at",
+				Order: "desc",
+				Limit: 11,
+				After: &models.TransactionSearchCursor{UpdatedAt: createdAt, Id: 7},
+			},
+			expectedQuery: "SELECT * FROM transaction WHERE (updated_at, id) < ($1, $2)" +
+				" ORDER BY updated_at DESC, id DESC LIMIT $3",
+			expectedArgs: []interface{}{createdAt, 7, 11},
+		},
+		{
+			name:          "Test search with unknown sort",
+			filter:        &models.TransactionSearchFilter{Sort: "id; DROP TABLE transaction", Order: "asc", Limit: 11},
+			expectedQuery: "SELECT * FROM transaction ORDER BY created_at, id LIMIT $1",
+			expectedArgs:  []interface{}{11},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			query, args := transactionSearchQuery(testCase.filter)
+
+			// Assert
+			assert.Equal(t, testCase.expectedQuery, query)
+			assert.Equal(t, testCase.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/ledger"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var refundTableName = "refund"
+
+type RefundPostgresRepository struct {
+	db *database.PostgresDB
+}
+
+func NewRefundPostgresRepository(db *database.PostgresDB) *RefundPostgresRepository {
+	/*Refund postgres repository constructor function.*/
+	return &RefundPostgresRepository{db: db}
+}
+
+func (repo *RefundPostgresRepository) CreateRefund(refund *models.Refund) (*models.Refund, error) {
+	/*
+		Insert new refund data to DB and return refund struct filled with new refund data.
+
+		Refunded transaction row is locked to check it's status and amount available for refund.
+	*/
+	var transaction *models.Transaction
+	var refundedAmount int64
+
+	// start new db transaction
+	dbTransaction, err := repo.db.Beginx()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// lock refunded transaction, so concurrent refunds are checked one by one
+	transaction, err = selectTransactionForUpdate(dbTransaction, refund.TransactionId)
+
+	if err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	if !containsStatus(services.RefundableTransactionStatuses, transaction.Status) {
+		dbTransaction.Rollback()
+		return nil, services.ErrRefundNotAllowed
+	}
+
+	// pending refunds reserve transaction amount as well as successful ones
+	refundedAmount, err = sumTransactionRefunds(
+		dbTransaction, transaction.Id, services.RefundPendingStatus, services.RefundSuccessStatus)
+
+	if err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	if refundedAmount+refund.Amount > transaction.Amount {
+		dbTransaction.Rollback()
+		return nil, services.ErrRefundAmountExceeded
+	}
+
+	// build query string
+	query := fmt.Sprintf(
+		"INSERT INTO %s (transaction_id, amount, status) values ($1, $2, $3) RETURNING *", refundTableName)
+
+	// evalate insert query and parse new row data to refund struct
+	row := dbTransaction.QueryRowx(query, refund.TransactionId, refund.Amount, refund.Status)
+	err = row.StructScan(refund)
+
+	if err != nil {
+		// roll back db transaction if parsing data to struct was failed
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	// return refund struct filled with data and commit db transaction
+	return refund, dbTransaction.Commit()
+}
+
+func (repo *RefundPostgresRepository) UpdateRefundStatus(refund *models.Refund, status string) (*models.Refund, error) {
+	/*
+		Update refund status return refund struct filled with new refund data.
+
+		Successful refund updates refunded transaction status to PARTIALLY_REFUNDED or REFUNDED
+		and returns refunded amount to the user wallet.
+	*/
+	var transaction *models.Transaction
+	var wallet *models.Wallet
+	var refundedAmount int64
+	var transactionStatus string
+
+	// start new db transaction
+	dbTransaction, err := repo.db.Beginx()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// lock refunded transaction to update it's status consistently
+	transaction, err = selectTransactionForUpdate(dbTransaction, refund.TransactionId)
+
+	if err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	// build query string, refund is updated only if it's status was not changed concurrently
+	query := fmt.Sprintf(
+		`UPDATE %s SET status = $1, updated_at = now()::timestamptz
+		WHERE id = $2 AND status = $3 RETURNING *;`,
+		refundTableName)
+
+	// evalate update query and parse new row data to refund struct
+	row := dbTransaction.QueryRowx(query, status, refund.Id, refund.Status)
+	err = row.StructScan(refund)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		// refund status was changed since refund was retrieved
+		dbTransaction.Rollback()
+		return nil, services.ErrConcurrentUpdate
+	}
+
+	if err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	if status != services.RefundSuccessStatus {
+		return refund, dbTransaction.Commit()
+	}
+
+	// return refunded amount to the user in the ledger
+	if err = insertLedgerEntry(dbTransaction, ledger.RefundEntry(transaction, refund)); err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	// return refunded amount to available funds of user wallet
+	wallet, err = selectWalletForUpdate(dbTransaction, transaction.UserId, transaction.Currency)
+	if err == nil {
+		err = changeWalletBalance(dbTransaction, wallet, refund.Amount, 0)
+	}
+
+	if err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	// calculate refunded transaction status according to sum of successful refunds
+	refundedAmount, err = sumTransactionRefunds(dbTransaction, transaction.Id, services.RefundSuccessStatus)
+
+	if err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	transactionStatus = services.TransactionPartiallyRefundedStatus
+	if refundedAmount >= transaction.Amount {
+		transactionStatus = services.TransactionRefundedStatus
+	}
+
+	if transactionStatus != transaction.Status {
+		err = services.TransactionStateMachine.Transition(transaction.Status, transactionStatus, services.RoleSystem)
+		if err == nil {
+			err = setTransactionStatus(dbTransaction, transaction, transactionStatus)
+		}
+
+		if err != nil {
+			dbTransaction.Rollback()
+			return nil, err
+		}
+	}
+
+	// return refund struct filled with data and commit db transaction
+	return refund, dbTransaction.Commit()
+}
+
+func (repo *RefundPostgresRepository) GetRefundById(refundId int) (*models.Refund, error) {
+	/*Return refund struct retrieved from db by PK.*/
+	var refund models.Refund = models.Refund{}
+
+	// build query string
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1", refundTableName)
+
+	// evaluate query and parse data to refund struct
+	if err := repo.db.Get(&refund, query, refundId); err != nil {
+		return nil, notFoundError(err, services.ErrRefundNotFound)
+	}
+
+	return &refund, nil
+}
+
+func (repo *RefundPostgresRepository) GetTransactionRefunds(transactionId int) ([]*models.Refund, error) {
+	/*Return slice of refund structs retrieved from db filtered by transaction id.*/
+	var refunds []*models.Refund = make([]*models.Refund, 0)
+
+	// build query string
+	query := fmt.Sprintf("SELECT * FROM %s WHERE transaction_id = $1 ORDER BY created_at DESC;", refundTableName)
+
+	// evaluate query and parse data to slice of refund structs
+	if err := repo.db.Select(&refunds, query, transactionId); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func sumTransactionRefunds(dbTransaction *sqlx.Tx, transactionId int, statuses ...string) (int64, error) {
+	/*Return sum of transaction refunds amounts with one of passed statuses.*/
+	var amount int64
+
+	// build query string
+	query := fmt.Sprintf(
+		"SELECT COALESCE(SUM(amount), 0) FROM %s WHERE transaction_id = $1 AND status = ANY($2);", refundTableName)
+
+	err := dbTransaction.Get(&amount, query, transactionId, pq.Array(statuses))
+
+	return amount, err
+}
+
+func containsStatus(statuses []string, status string) bool {
+	/*Check status is present in list of statuses.*/
+	for _, item := range statuses {
+		if item == status {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var riskDecisionTableName = "risk_decision"
+
+// Transaction history queries of risk rules
+type RiskPostgresRepository struct {
+	db *database.PostgresDB
+}
+
+func NewRiskPostgresRepository(db *database.PostgresDB) *RiskPostgresRepository {
+	/*Risk postgres repository constructor function.*/
+	return &RiskPostgresRepository{db: db}
+}
+
+func (repo *RiskPostgresRepository) CountUserTransactions(userId int, since time.Time) (int, error) {
+	/*Return number of user transactions created since the time.*/
+	var count int
+
+	// build query string
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE user_id = $1 AND created_at >= $2", transactionTableName)
+
+	return count, repo.db.Get(&count, query, userId, since)
+}
+
+func (repo *RiskPostgresRepository) CountEmailTransactions(email string, since time.Time) (int, error) {
+	/*Return number of transactions with the email created since the time.*/
+	var count int
+
+	// build query string
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE user_email = $1 AND created_at >= $2", transactionTableName)
+
+	return count, repo.db.Get(&count, query, email, since)
+}
+
+func (repo *RiskPostgresRepository) SumUserAmount(userId int, currency string, since time.Time) (int64, error) {
+	/*Return total amount of user transactions in the currency created since the time, unsuccessful ones are skipped.*/
+	var total int64
+
+	// build query string
+	query := fmt.Sprintf(
+		`SELECT coalesce(sum(amount), 0) FROM %s
+		WHERE user_id = $1 AND currency = $2 AND created_at >= $3 AND status NOT IN ($4, $5, $6, $7)`,
+		transactionTableName)
+
+	return total, repo.db.Get(
+		&total,
+		query,
+		userId,
+		currency,
+		since,
+		services.TransactionErrorStatus,
+		services.TransactionFailedStatus,
+		services.TransactionCanceledStatus,
+		services.TransactionExpiredStatus,
+	)
+}
+
+func insertRiskDecision(dbTransaction *sqlx.Tx, decision *models.RiskDecision) error {
+	/*Insert risk decision of created transaction within db transaction.*/
+	reasons, err := json.Marshal(decision.Reasons)
+	if err != nil {
+		return err
+	}
+
+	// build query string
+	query := fmt.Sprintf(
+		"INSERT INTO %s (transaction_id, outcome, reasons) values ($1, $2, $3) RETURNING id, created_at",
+		riskDecisionTableName)
+
+	return dbTransaction.QueryRowx(query, decision.TransactionId, decision.Outcome, string(reasons)).
+		Scan(&decision.Id, &decision.CreatedAt)
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiskPostgresRepository_History(t *testing.T) {
+	// Arrange
+	db := newTestPostgresDB(t)
+	defer db.Close()
+
+	repo := NewRiskPostgresRepository(db)
+	transactions := NewTransactionPostgresRepository(db)
+	user := newTestUser(t, db)
+	since := time.Now().Add(-time.Minute)
+
+	for _, status := range []string{services.TransactionErrorStatus, services.TransactionFailedStatus, services.TransactionNewStatus} {
+		_, err := transactions.CreateTransaction(&models.Transaction{
+			UserId:    user.Id,
+			UserEmail: user.Email,
+			Amount:    1000,
+			Currency:  "JPY",
+			Status:    status,
+		}, &models.RiskDecision{Outcome: "ALLOW", Reasons: []models.RiskReason{}})
+		require.NoError(t, err)
+	}
+
+	// Act
+	userCount, err := repo.CountUserTransactions(user.Id, since)
+	require.NoError(t, err)
+	emailCount, err := repo.CountEmailTransactions(user.Email, since)
+	require.NoError(t, err)
+	otherEmailCount, err := repo.CountEmailTransactions(strings.ToUpper(user.Email)+"x", since)
+	require.NoError(t, err)
+	total, err := repo.SumUserAmount(user.Id, "JPY", since)
+	require.NoError(t, err)
+	futureTotal, err := repo.SumUserAmount(user.Id, "JPY", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 3, userCount)
+	assert.Equal(t, 3, emailCount)
+	assert.Equal(t, 0, otherEmailCount)
+	// ERROR and FAILED transactions are not counted in volume
+	assert.Equal(t, int64(1000), total)
+	assert.Equal(t, int64(0), futureTotal)
+}
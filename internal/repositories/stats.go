@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/lib/pq"
+)
+
+type StatsPostgresRepository struct {
+	db *database.PostgresDB
+}
+
+func NewStatsPostgresRepository(db *database.PostgresDB) *StatsPostgresRepository {
+	/*Statistics postgres repository constructor function.*/
+	return &StatsPostgresRepository{db: db}
+}
+
+func (repo *StatsPostgresRepository) GetTransactionStats(filter *models.TransactionStatsFilter) ([]*models.TransactionStatsRow, error) {
+	/*
+		Return transactions aggregated by db per bucket, status and currency and per bucket,
+		window rows (without bucket) are aggregated per status and currency and over the whole window.
+
+		Rows are ordered by bucket (window rows go last), row without status goes first in the bucket.
+		Amounts are summed per currency only.
+	*/
+	var rows []*models.TransactionStatsRow = make([]*models.TransactionStatsRow, 0)
+
+	// build query string
+	query, args := transactionStatsQuery(filter)
+
+	// evaluate query and parse data to slice of row structs
+	if err := repo.db.Select(&rows, query+";", args...); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func transactionStatsQuery(filter *models.TransactionStatsFilter) (string, []interface{}) {
+	/*
+		Build query aggregating transactions created within the window with grouping sets,
+		buckets are truncated in UTC, unknown interval falls back to day.
+	*/
+	var query *selectQuery = newSelectQuery(transactionTableName)
+	var bucket string = "date_trunc('day', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'"
+	var successful []string = []string{
+		services.TransactionSuccessStatus,
+		services.TransactionPartiallyRefundedStatus,
+		services.TransactionRefundedStatus,
+	}
+
+	// interval is taken from the fixed set, request value never gets to the query string
+	switch filter.Interval {
+	case "hour":
+		bucket = "date_trunc('hour', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'"
+	case "month":
+		bucket = "date_trunc('month', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'"
+	}
+
+	query.Columns(
+		bucket+" AS bucket, status, currency, count(*) AS count, "+
+			"CASE WHEN GROUPING(currency) = 0 THEN SUM(amount) ELSE 0 END AS amount, "+
+			"count(*) FILTER (WHERE status = ANY(?)) AS successful, "+
+			"count(processed_at) AS processed, "+
+			"AVG(EXTRACT(EPOCH FROM processed_at - created_at)) AS avg_processing_seconds",
+		pq.Array(successful),
+	)
+	query.Where("created_at >= ?", filter.From).Where("created_at < ?", filter.To)
+	if filter.UserId != nil {
+		query.Where("user_id = ?", *filter.UserId)
+	}
+
+	return query.
+		GroupBy("GROUPING SETS (("+bucket+", status, currency), ("+bucket+"), (status, currency), ())").
+		OrderBy("bucket NULLS LAST", false).
+		OrderBy("status NULLS FIRST", false).
+		OrderBy("currency NULLS FIRST", false).
+		Build()
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionStatsQuery(t *testing.T) {
+	// Arrange
+	userId := 1
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	successful := pq.Array([]string{"SUCCESS", "PARTIALLY_REFUNDED", "REFUNDED"})
+	columns := " AS bucket, status, currency, count(*) AS count," +
+		" CASE WHEN GROUPING(currency) = 0 THEN SUM(amount) ELSE 0 END AS amount," +
+		" count(*) FILTER (WHERE status = ANY($1)) AS successful, count(processed_at) AS processed," +
+		" AVG(EXTRACT(EPOCH FROM processed_at - created_at)) AS avg_processing_seconds FROM transaction"
+	order := " ORDER BY bucket NULLS LAST, status NULLS FIRST, currency NULLS FIRST"
+
+	testTable := []struct {
+		name           string
+		filter         *models.TransactionStatsFilter
+		expectedBucket string
+		expectedWhere  string
+		expectedArgs   []interface{}
+	}{
+		{
+			name:           "Test stats of all transactions",
+			filter:         &models.TransactionStatsFilter{From: from, To: to, Interval: "hour"},
+			expectedBucket: "date_trunc('hour', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'",
+			expectedWhere:  " WHERE created_at >= $2 AND created_at < $3",
+			expectedArgs:   []interface{}{successful, from, to},
+		},
+		{
+			name:           "Test stats of user transactions",
+			filter:         &models.TransactionStatsFilter{From: from, To: to, Interval: "month", UserId: &userId},
+			expectedBucket: "date_trunc('month', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'",
+			expectedWhere:  " WHERE created_at >= $2 AND created_at < $3 AND user_id = $4",
+			expectedArgs:   []interface{}{successful, from, to, 1},
+		},
+		{
+			name:           "Test stats with unknown interval",
+			filter:         &models.TransactionStatsFilter{From: from, To: to, Interval: "week'); DROP TABLE transaction"},
+			expectedBucket: "date_trunc('day', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'",
+			expectedWhere:  " WHERE created_at >= $2 AND created_at < $3",
+			expectedArgs:   []interface{}{successful, from, to},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			query, args := transactionStatsQuery(testCase.filter)
+
+			// Assert
+			assert.Equal(t,
+				"SELECT "+testCase.expectedBucket+columns+testCase.expectedWhere+
+					" GROUP BY GROUPING SETS (("+testCase.expectedBucket+", status, currency), ("+
+					testCase.expectedBucket+"), (status, currency), ())"+order,
+				query)
+			assert.Equal(t, testCase.expectedArgs, args)
+		})
+	}
+}
+
+func TestStatsPostgresRepository_GetTransactionStats(t *testing.T) {
+	// Arrange
+	db := newTestPostgresDB(t)
+	defer db.Close()
+
+	// user without transactions, so statistics are not affected by other tests
+	user := newTestUser(t, db)
+	repo := NewTransactionPostgresRepository(db)
+	statsRepo := NewStatsPostgresRepository(db)
+
+	_, err := NewWalletPostgresRepository(db).DepositWallet(user.Id, "EUR", 5000)
+	require.NoError(t, err)
+
+	for _, amount := range []int64{1000, 1500} {
+		_, err = repo.CreateTransaction(&models.Transaction{
+			UserId:    user.Id,
+			UserEmail: user.Email,
+			Amount:    amount,
+			Currency:  "EUR",
+			Status:    services.TransactionNewStatus,
+		}, nil)
+		require.NoError(t, err)
+	}
+	transactions, err := repo.SearchTransactions(
+		&models.TransactionSearchFilter{UserId: &user.Id, Sort: "amount", Order: "asc", Limit: 10},
+	)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+
+	processed, err := repo.UpdateTransactionStatus(transactions[1], services.TransactionSuccessStatus, nil)
+	require.NoError(t, err)
+	require.NotNil(t, processed.ProcessedAt)
+
+	// Act
+	rows, err := statsRepo.GetTransactionStats(&models.TransactionStatsFilter{
+		From:     time.Now().Add(-time.Hour),
+		To:       time.Now().Add(time.Hour),
+		Interval: "day",
+		UserId:   &user.Id,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	// whole window rows go last: total over the window, then totals per status and currency
+	require.GreaterOrEqual(t, len(rows), 3)
+	window := rows[len(rows)-3:]
+	for _, row := range window {
+		assert.Nil(t, row.Bucket)
+	}
+
+	assert.Nil(t, window[0].Status)
+	assert.Equal(t, int64(2), window[0].Count)
+	assert.Equal(t, int64(1), window[0].Processed)
+	assert.Equal(t, int64(1), window[0].Successful)
+	assert.NotNil(t, window[0].AvgProcessingSeconds)
+
+	assert.Equal(t, services.TransactionNewStatus, *window[1].Status)
+	assert.Equal(t, "EUR", *window[1].Currency)
+	assert.Equal(t, int64(1000), window[1].Amount)
+
+	assert.Equal(t, services.TransactionSuccessStatus, *window[2].Status)
+	assert.Equal(t, int64(1500), window[2].Amount)
+}
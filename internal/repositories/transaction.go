@@ -1,14 +1,24 @@
 package repositories
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/ledger"
 	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/jmoiron/sqlx"
 )
 
 var transactionTableName = "transaction"
 
+// Number of rows fetched from export cursor at once
+const exportBatchSize = 500
+
 type TransactionPostgresRepository struct {
 	db *database.PostgresDB
 }
@@ -18,8 +28,14 @@ func NewTransactionPostgresRepository(db *database.PostgresDB) *TransactionPostg
 	return &TransactionPostgresRepository{db: db}
 }
 
-func (repo *TransactionPostgresRepository) CreateTransaction(transaction *models.Transaction) (*models.Transaction, error) {
-	/*Insert new transaction data to DB and return transaction struct filled with new transaction data.*/
+func (repo *TransactionPostgresRepository) CreateTransaction(
+	transaction *models.Transaction, decision *models.RiskDecision,
+) (*models.Transaction, error) {
+	/*
+		Insert new transaction data to DB and return transaction struct filled with new transaction data.
+
+		Risk decision (if passed) is stored within the same db transaction.
+	*/
 
 	// start new db transaction
 	dbTransaction, err := repo.db.Beginx()
@@ -28,16 +44,54 @@ func (repo *TransactionPostgresRepository) CreateTransaction(transaction *models
 		return nil, err
 	}
 
-	// build query string
+	// build query string, transaction declined by provider is processed at once
 	query := fmt.Sprintf(
-		"INSERT INTO %s (user_id, user_email, amount, currency, status) values ($1, $2, $3, $4, $5) RETURNING *",
+		`INSERT INTO %s (
+			user_id, user_email, amount, currency, status, provider, provider_reference, processed_at, expires_at,
+			merchant_id
+		)
+		values ($1, $2, $3, $4, $5, $6, $7, CASE WHEN $8 THEN now()::timestamptz END, $9, $10) RETURNING *`,
 		transactionTableName)
 
 	// evalate insert query and parse new row data to transaction struct
 	row := dbTransaction.QueryRowx(
-		query, transaction.UserId, transaction.UserEmail, transaction.Amount, transaction.Currency, transaction.Status)
+		query,
+		transaction.UserId,
+		transaction.UserEmail,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.Status,
+		transaction.Provider,
+		transaction.ProviderReference,
+		transaction.Status == services.TransactionFailedStatus,
+		transaction.ExpiresAt,
+		transaction.MerchantId,
+	)
 	err = row.StructScan(transaction)
 
+	// user could be deleted after it was retrieved by service
+	err = constraintError(err, transactionUserForeignKey, services.ErrUserNotFound)
+
+	if err == nil {
+		// add transaction event to the outbox within the same db transaction
+		err = enqueueTransactionEvent(dbTransaction, services.TransactionCreatedEvent, transaction)
+	}
+
+	if err == nil && decision != nil {
+		decision.TransactionId = transaction.Id
+		err = insertRiskDecision(dbTransaction, decision)
+	}
+
+	if err == nil && transaction.Status == services.TransactionNewStatus {
+		// authorize transaction amount in the ledger, transactions created with other statuses do not hold funds
+		err = insertLedgerEntry(dbTransaction, ledger.TransactionEntry(transaction))
+	}
+
+	if err == nil && transaction.Status == services.TransactionNewStatus {
+		// hold transaction amount in user wallet, fails if available funds are not enough
+		err = applyTransactionToWallet(dbTransaction, transaction)
+	}
+
 	if err != nil {
 		// roll back db transaction if parsing data to struct was failed
 		dbTransaction.Rollback()
@@ -48,8 +102,62 @@ func (repo *TransactionPostgresRepository) CreateTransaction(transaction *models
 	return transaction, dbTransaction.Commit()
 }
 
-func (repo *TransactionPostgresRepository) UpdateTransactionStatus(transaction *models.Transaction, status string) (*models.Transaction, error) {
-	/*Update transaction status return transaction struct filled with new transaction data.*/
+func (repo *TransactionPostgresRepository) UpdateTransactionStatus(
+	transaction *models.Transaction, status string, forward services.TransactionForward,
+) (*models.Transaction, error) {
+	/*
+		Update transaction status return transaction struct filled with new transaction data.
+
+		Row is locked and updated only if it has the same version as passed transaction struct,
+		services.ErrConflict is returned if transaction was modified concurrently.
+		Forward (provider operation) is performed while row is locked, so concurrent updates and sweeper
+		can not change the status in between, and status is not updated if forward fails.
+	*/
+	var locked *models.Transaction
+
+	// start new db transaction
+	dbTransaction, err := repo.db.Beginx()
+
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err = selectTransactionForUpdate(dbTransaction, transaction.Id)
+
+	if err == nil && locked.Version != transaction.Version {
+		// row version was changed since transaction was retrieved
+		err = services.ErrConcurrentUpdate
+	}
+
+	if err == nil && forward != nil {
+		err = forward(locked)
+	}
+
+	if err == nil {
+		err = setTransactionStatus(dbTransaction, locked, status)
+	}
+
+	if err != nil {
+		// roll back db transaction if update was failed
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	// return transaction struct filled with data and commit db transaction
+	return locked, dbTransaction.Commit()
+}
+
+func (repo *TransactionPostgresRepository) ExpireTransactions(
+	limit int, forward services.TransactionForward,
+) ([]*models.Transaction, error) {
+	/*
+		Move up to limit NEW transactions which expiration time has come to EXPIRED status within one db transaction.
+
+		Rows locked by concurrent status updates (or another sweeper) are skipped, they are expired by the next batch.
+		Forward (provider operation) is performed for every locked row before it's status is updated,
+		the same way as for single status update.
+	*/
+	var transactions []*models.Transaction = make([]*models.Transaction, 0)
 
 	// start new db transaction
 	dbTransaction, err := repo.db.Beginx()
@@ -60,21 +168,30 @@ func (repo *TransactionPostgresRepository) UpdateTransactionStatus(transaction *
 
 	// build query string
 	query := fmt.Sprintf(
-		"UPDATE %s SET status = $1, updated_at = now()::timestamptz WHERE id = $2 RETURNING *;",
+		`SELECT * FROM %s WHERE status = $1 AND expires_at <= now()
+		ORDER BY expires_at LIMIT $2 FOR UPDATE SKIP LOCKED`,
 		transactionTableName)
 
-	// evalate update query and parse new row data to transaction struct
-	row := dbTransaction.QueryRowx(query, status, transaction.Id)
-	err = row.StructScan(transaction)
+	err = dbTransaction.Select(&transactions, query, services.TransactionNewStatus, limit)
+
+	// expired status is applied the same way as another status change (ledger, wallet and event)
+	for i := 0; err == nil && i < len(transactions); i++ {
+		if forward != nil {
+			err = forward(transactions[i])
+		}
+		if err == nil {
+			err = setTransactionStatus(dbTransaction, transactions[i], services.TransactionExpiredStatus)
+		}
+	}
 
 	if err != nil {
-		// roll back db transaction if parsing data to struct was failed
+		// roll back db transaction if any update was failed
 		dbTransaction.Rollback()
 		return nil, err
 	}
 
-	// return transaction struct filled with data and commit db transaction
-	return transaction, dbTransaction.Commit()
+	// return expired transactions and commit db transaction
+	return transactions, dbTransaction.Commit()
 }
 
 func (repo *TransactionPostgresRepository) GetTransactionById(transactionId int) (*models.Transaction, error) {
@@ -86,8 +203,206 @@ func (repo *TransactionPostgresRepository) GetTransactionById(transactionId int)
 
 	// evaluate query and parse data to transaction struct
 	if err := repo.db.Get(&transaction, query, transactionId); err != nil {
-		return nil, err
+		return nil, notFoundError(err, services.ErrTransactionNotFound)
+	}
+
+	return &transaction, nil
+}
+
+func setTransactionStatus(dbTransaction *sqlx.Tx, transaction *models.Transaction, status string) error {
+	/*
+		Update transaction status within db transaction, fill transaction struct with new row data.
+
+		Status change event, ledger entry and wallet balances change (if status moves funds)
+		are applied within the same db transaction.
+	*/
+
+	// build query string
+	query := fmt.Sprintf(
+		`UPDATE %s SET status = $1, updated_at = now()::timestamptz, version = version + 1,
+			processed_at = CASE WHEN status = 'NEW' THEN now()::timestamptz ELSE processed_at END
+		WHERE id = $2 AND version = $3 RETURNING *;`,
+		transactionTableName)
+
+	// evalate update query and parse new row data to transaction struct
+	row := dbTransaction.QueryRowx(query, status, transaction.Id, transaction.Version)
+	err := row.StructScan(transaction)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		// row version was changed since transaction was retrieved
+		return services.ErrConcurrentUpdate
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if entry := ledger.TransactionEntry(transaction); entry != nil {
+		if err = insertLedgerEntry(dbTransaction, entry); err != nil {
+			return err
+		}
+	}
+
+	// capture or release funds held in user wallet, transaction row is locked before wallet row
+	if err = applyTransactionToWallet(dbTransaction, transaction); err != nil {
+		return err
+	}
+
+	return enqueueTransactionEvent(dbTransaction, services.TransactionStatusChangedEvent, transaction)
+}
+
+func selectTransactionForUpdate(dbTransaction *sqlx.Tx, transactionId int) (*models.Transaction, error) {
+	/*Return transaction struct retrieved from db by PK, row is locked until the end of db transaction.*/
+	var transaction models.Transaction = models.Transaction{}
+
+	// build query string
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1 FOR UPDATE", transactionTableName)
+
+	// evaluate query and parse data to transaction struct
+	if err := dbTransaction.Get(&transaction, query, transactionId); err != nil {
+		return nil, notFoundError(err, services.ErrTransactionNotFound)
 	}
 
 	return &transaction, nil
 }
+
+func (repo *TransactionPostgresRepository) SearchTransactions(filter *models.TransactionSearchFilter) ([]*models.Transaction, error) {
+	/*Return page of transaction structs retrieved from db filtered and ordered by search params.*/
+	var transactions []*models.Transaction = make([]*models.Transaction, 0)
+
+	// build query string
+	query, args := transactionSearchQuery(filter)
+
+	// evaluate query and parse data to slice of transaction structs
+	if err := repo.db.Select(&transactions, query+";", args...); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (repo *TransactionPostgresRepository) ExportTransactions(
+	ctx context.Context, filter *models.TransactionSearchFilter, fn func(*models.Transaction) error,
+) error {
+	/*
+		Pass every transaction matching search params to fn in requested order.
+
+		Rows are read through server side cursor by batches, so the whole result is never loaded to memory.
+		Cursor lives within read only db transaction which is rolled back as soon as ctx is done.
+	*/
+	var err error
+	var transactions []*models.Transaction
+
+	dbTransaction, err := repo.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	// transaction is read only, rollback after commit is a no-op
+	defer dbTransaction.Rollback()
+
+	// build query string
+	query, args := transactionSearchQuery(filter)
+
+	_, err = dbTransaction.ExecContext(ctx, "DECLARE transaction_export NO SCROLL CURSOR FOR "+query+";", args...)
+	if err != nil {
+		return err
+	}
+
+	fetchQuery := fmt.Sprintf("FETCH %d FROM transaction_export;", exportBatchSize)
+	for {
+		// db transaction is already rolled back when ctx is done, report the reason instead of ErrTxDone
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		transactions = transactions[:0]
+		if err = dbTransaction.SelectContext(ctx, &transactions, fetchQuery); err != nil {
+			return err
+		}
+		if len(transactions) == 0 {
+			break
+		}
+
+		for _, transaction := range transactions {
+			if err = fn(transaction); err != nil {
+				return err
+			}
+		}
+	}
+
+	return dbTransaction.Commit()
+}
+
+func transactionSearchQuery(filter *models.TransactionSearchFilter) (string, []interface{}) {
+	/*
+		Build query selecting transactions filtered by search params.
+
+		Rows are ordered by sort column and id in requested direction, unknown sort column falls back to created_at.
+	*/
+	var query *selectQuery = newSelectQuery(transactionTableName)
+	var statuses []interface{} = make([]interface{}, len(filter.Statuses))
+	var descending bool = filter.Order != "asc"
+	var sortColumn string = "created_at"
+	var afterValue interface{}
+
+	for i, status := range filter.Statuses {
+		statuses[i] = status
+	}
+	query.WhereIn("status", statuses)
+
+	if filter.Currency != "" {
+		query.Where("currency = ?", filter.Currency)
+	}
+	if filter.UserId != nil {
+		query.Where("user_id = ?", *filter.UserId)
+	}
+	if filter.Email != "" {
+		query.Where("user_email ILIKE ?", "%"+escapeLike(filter.Email)+"%")
+	}
+	if filter.AmountMin != nil {
+		query.Where("amount >= ?", *filter.AmountMin)
+	}
+	if filter.AmountMax != nil {
+		query.Where("amount <= ?", *filter.AmountMax)
+	}
+	if filter.CreatedFrom != nil {
+		query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		query.Where("updated_at >= ?", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		query.Where("updated_at < ?", *filter.UpdatedTo)
+	}
+
+	// sort column is taken from the fixed set, request value never gets to the query string
+	switch filter.Sort {
+	case "updated_at":
+		sortColumn = "updated_at"
+	case "amount":
+		sortColumn = "amount"
+	}
+
+	// keyset pagination, retrieve rows placed after the cursor in requested direction
+	if filter.After != nil {
+		switch sortColumn {
+		case "updated_at":
+			afterValue = filter.After.UpdatedAt
+		case "amount":
+			afterValue = filter.After.Amount
+		default:
+			afterValue = filter.After.CreatedAt
+		}
+
+		if descending {
+			query.Where("("+sortColumn+", id) < (?, ?)", afterValue, filter.After.Id)
+		} else {
+			query.Where("("+sortColumn+", id) > (?, ?)", afterValue, filter.After.Id)
+		}
+	}
+
+	return query.OrderBy(sortColumn, descending).OrderBy("id", descending).Limit(filter.Limit).Build()
+}
@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/providers"
+	"github.com/Pythonyan3/payment-service/internal/risk"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DSN of migrated postgres database used by integration tests
+const testDatabaseURLEnv = "TEST_DATABASE_URL"
+
+func newTestPostgresDB(t *testing.T) *database.PostgresDB {
+	/*Connect to test database, skip test if database is not configured.*/
+	dsn := os.Getenv(testDatabaseURLEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set, skipping integration test.", testDatabaseURLEnv)
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	require.NoError(t, err)
+
+	return &database.PostgresDB{DB: db}
+}
+
+func newTestUser(t *testing.T, db *database.PostgresDB) *models.User {
+	/*Create user with unique email, so tests do not affect each other's data.*/
+	user, err := NewUserPostgresRepository(db).CreateUser(&models.User{
+		Email: fmt.Sprintf("user%d@mail.ru", time.Now().UnixNano()),
+	})
+	require.NoError(t, err)
+
+	return user
+}
+
+func TestTransactionPostgresRepository_ConcurrentUpdateStatus(t *testing.T) {
+	// Arrange
+	const workers = 32
+	var wg sync.WaitGroup
+	var start chan struct{} = make(chan struct{})
+	var updated chan *models.Transaction = make(chan *models.Transaction, workers)
+	var failures chan error = make(chan error, workers)
+
+	db := newTestPostgresDB(t)
+	defer db.Close()
+
+	repo := NewTransactionPostgresRepository(db)
+	// transaction is created without provider, so it's status is changed by service only
+	service := services.NewTransactionService(
+		repo,
+		NewUserPostgresRepository(db),
+		providers.NewRegistry(providers.NewFakeProvider("fake")),
+		risk.NewEngine(risk.NewRules(), NewRiskPostgresRepository(db)),
+		time.Hour,
+	)
+	user := newTestUser(t, db)
+
+	// fund user wallet, so transaction amount can be held
+	_, err := NewWalletPostgresRepository(db).DepositWallet(user.Id, "EUR", 1500)
+	require.NoError(t, err)
+
+	transaction, err := repo.CreateTransaction(&models.Transaction{
+		UserId:    user.Id,
+		UserEmail: user.Email,
+		Amount:    1500,
+		Currency:  "EUR",
+		Status:    services.TransactionNewStatus,
+	}, nil)
+	require.NoError(t, err)
+
+	// Act
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var result *models.Transaction
+			var err error
+
+			<-start
+			// half of workers cancel transaction, another half proceed it
+			if i%2 == 0 {
+				result, err = service.UpdateStatus(
+					transaction.Id, services.TransactionCanceledStatus, services.RoleUser)
+			} else {
+				result, err = service.UpdateStatus(
+					transaction.Id, services.TransactionSuccessStatus, services.RoleProvider)
+			}
+
+			if err != nil {
+				failures <- err
+			} else {
+				updated <- result
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(updated)
+	close(failures)
+
+	// Assert
+	require.Len(t, updated, 1, "exactly one concurrent status update must succeed")
+	winner := <-updated
+
+	for err := range failures {
+		var transitionError *services.TransitionError
+		assert.True(t, errors.Is(err, services.ErrConflict) || errors.As(err, &transitionError), err.Error())
+	}
+
+	stored, err := repo.GetTransactionById(transaction.Id)
+	require.NoError(t, err)
+	assert.Equal(t, winner.Status, stored.Status)
+	assert.Equal(t, transaction.Version+1, stored.Version)
+}
+
+// PaymentProvider implementation which pauses after capture until sweeper expired transactions (or timeout)
+type pausingProvider struct {
+	*providers.FakeProvider
+	captured chan struct{}
+	expired  chan struct{}
+}
+
+func (provider *pausingProvider) Capture(ctx context.Context, reference string, amount int64) (*providers.Result, error) {
+	result, err := provider.FakeProvider.Capture(ctx, reference, amount)
+	close(provider.captured)
+	select {
+	case <-provider.expired:
+	case <-time.After(100 * time.Millisecond):
+	}
+	return result, err
+}
+
+func TestTransactionPostgresRepository_ProceedExpireRace(t *testing.T) {
+	// Arrange
+	var proceedErr error
+	var proceeded chan struct{} = make(chan struct{})
+
+	db := newTestPostgresDB(t)
+	defer db.Close()
+
+	repo := NewTransactionPostgresRepository(db)
+	provider := &pausingProvider{
+		FakeProvider: providers.NewFakeProvider("fake"),
+		captured:     make(chan struct{}),
+		expired:      make(chan struct{}),
+	}
+	service := services.NewTransactionService(
+		repo,
+		NewUserPostgresRepository(db),
+		providers.NewRegistry(provider),
+		risk.NewEngine(risk.NewRules(), NewRiskPostgresRepository(db)),
+		time.Hour,
+	)
+	user := newTestUser(t, db)
+	walletRepo := NewWalletPostgresRepository(db)
+	_, err := walletRepo.DepositWallet(user.Id, "EUR", 1500)
+	require.NoError(t, err)
+
+	// expired transaction authorized by provider
+	payment, err := provider.Authorize(context.Background(), &providers.Payment{Amount: 1500, Currency: "EUR"})
+	require.NoError(t, err)
+	name := provider.Name()
+	past := time.Now().Add(-time.Minute)
+	transaction, err := repo.CreateTransaction(&models.Transaction{
+		UserId:            user.Id,
+		UserEmail:         user.Email,
+		Amount:            1500,
+		Currency:          "EUR",
+		Status:            services.TransactionNewStatus,
+		Provider:          &name,
+		ProviderReference: &payment.Reference,
+		ExpiresAt:         &past,
+	}, nil)
+	require.NoError(t, err)
+
+	// Act
+	go func() {
+		defer close(proceeded)
+		_, proceedErr = service.UpdateStatus(transaction.Id, services.TransactionSuccessStatus, services.RoleProvider)
+	}()
+	// sweeper runs after payment is captured, but before proceed request stored the status
+	<-provider.captured
+	_, expireErr := service.Expire(100)
+	close(provider.expired)
+	<-proceeded
+
+	// Assert
+	// payment state at provider and wallet balances match stored status
+	assert.NoError(t, expireErr)
+	assert.NoError(t, proceedErr)
+	stored, err := repo.GetTransactionById(transaction.Id)
+	require.NoError(t, err)
+	assert.Equal(t, services.TransactionSuccessStatus, stored.Status)
+	result, _ := provider.QueryStatus(context.Background(), payment.Reference)
+	assert.Equal(t, providers.StatusCaptured, result.Status)
+
+	wallets, err := walletRepo.GetUserWallets(user.Id)
+	require.NoError(t, err)
+	require.Len(t, wallets, 1)
+	assert.Equal(t, int64(0), wallets[0].Available)
+	assert.Equal(t, int64(0), wallets[0].Held)
+}
+
+func TestTransactionPostgresRepository_ExpireTransactions(t *testing.T) {
+	// Arrange
+	db := newTestPostgresDB(t)
+	defer db.Close()
+
+	repo := NewTransactionPostgresRepository(db)
+	user := newTestUser(t, db)
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	_, err := NewWalletPostgresRepository(db).DepositWallet(user.Id, "EUR", 3000)
+	require.NoError(t, err)
+
+	stale, err := repo.CreateTransaction(&models.Transaction{
+		UserId:    user.Id,
+		UserEmail: user.Email,
+		Amount:    1500,
+		Currency:  "EUR",
+		Status:    services.TransactionNewStatus,
+		ExpiresAt: &past,
+	}, nil)
+	require.NoError(t, err)
+
+	fresh, err := repo.CreateTransaction(&models.Transaction{
+		UserId:    user.Id,
+		UserEmail: user.Email,
+		Amount:    1500,
+		Currency:  "EUR",
+		Status:    services.TransactionNewStatus,
+		ExpiresAt: &future,
+	}, nil)
+	require.NoError(t, err)
+
+	// Act
+	var expiredIds map[int]bool = make(map[int]bool)
+	for {
+		expired, err := repo.ExpireTransactions(100, nil)
+		require.NoError(t, err)
+		for _, transaction := range expired {
+			expiredIds[transaction.Id] = true
+		}
+		if len(expired) < 100 {
+			break
+		}
+	}
+
+	// Assert
+	assert.True(t, expiredIds[stale.Id])
+	assert.False(t, expiredIds[fresh.Id])
+
+	stored, err := repo.GetTransactionById(stale.Id)
+	require.NoError(t, err)
+	assert.Equal(t, services.TransactionExpiredStatus, stored.Status)
+
+	stored, err = repo.GetTransactionById(fresh.Id)
+	require.NoError(t, err)
+	assert.Equal(t, services.TransactionNewStatus, stored.Status)
+}
@@ -5,6 +5,16 @@ import (
 
 	"github.com/Pythonyan3/payment-service/internal/database"
 	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+)
+
+var userTableName = "users"
+
+const (
+	// Names of constraints referring to user
+	userEmailIndex            = "users_email_idx"
+	transactionUserForeignKey = "transaction_user_id_fkey"
+	walletUserForeignKey      = "wallet_user_id_fkey"
 )
 
 type UserPostgresRepository struct {
@@ -16,34 +26,136 @@ func NewUserPostgresRepository(db *database.PostgresDB) *UserPostgresRepository
 	return &UserPostgresRepository{db: db}
 }
 
-func (repo *UserPostgresRepository) GetUserTransactionsById(userId int) ([]*models.Transaction, error) {
-	/*Return slice of transaction structs retrieved from db filtered by user id.*/
-	var transactions []*models.Transaction = make([]*models.Transaction, 0)
-	var query string
+func (repo *UserPostgresRepository) CreateUser(user *models.User) (*models.User, error) {
+	/*Insert new user data to DB and return user struct filled with new user data.*/
 
 	// build query string
-	query = fmt.Sprintf("SELECT * FROM %s WHERE user_id = $1 ORDER BY created_at DESC;", transactionTableName)
+	query := fmt.Sprintf("INSERT INTO %s (email, name) values ($1, $2) RETURNING *", userTableName)
 
-	// evaluate query and parse data to slice of transaction structs
-	if err := repo.db.Select(&transactions, query, userId); err != nil {
-		return nil, err
+	// evalate insert query and parse new row data to user struct
+	if err := repo.db.QueryRowx(query, user.Email, user.Name).StructScan(user); err != nil {
+		return nil, constraintError(err, userEmailIndex, services.ErrUserEmailTaken)
 	}
 
-	return transactions, nil
+	return user, nil
 }
 
-func (repo *UserPostgresRepository) GetUserTransactionsByEmail(userEmail string) ([]*models.Transaction, error) {
-	/*Return slice of transaction structs retrieved from db filtered by user email.*/
+func (repo *UserPostgresRepository) GetUserById(userId int) (*models.User, error) {
+	/*Return user struct retrieved from db by PK.*/
+	var user models.User = models.User{}
+
+	// build query string
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1", userTableName)
+
+	// evaluate query and parse data to user struct
+	if err := repo.db.Get(&user, query, userId); err != nil {
+		return nil, notFoundError(err, services.ErrUserNotFound)
+	}
+
+	return &user, nil
+}
+
+func (repo *UserPostgresRepository) GetUserByEmail(userEmail string) (*models.User, error) {
+	/*Return user struct retrieved from db by email, emails are compared case-insensitively.*/
+	var user models.User = models.User{}
+
+	// build query string
+	query := fmt.Sprintf("SELECT * FROM %s WHERE lower(email) = lower($1)", userTableName)
+
+	// evaluate query and parse data to user struct
+	if err := repo.db.Get(&user, query, userEmail); err != nil {
+		return nil, notFoundError(err, services.ErrUserNotFound)
+	}
+
+	return &user, nil
+}
+
+func (repo *UserPostgresRepository) UpdateUser(user *models.User) (*models.User, error) {
+	/*Update user data and return user struct filled with new user data.*/
+
+	// build query string
+	query := fmt.Sprintf(
+		"UPDATE %s SET email = $1, name = $2, updated_at = now()::timestamptz WHERE id = $3 RETURNING *",
+		userTableName)
+
+	// evalate update query and parse new row data to user struct
+	err := repo.db.QueryRowx(query, user.Email, user.Name, user.Id).StructScan(user)
+
+	if err != nil {
+		return nil, constraintError(notFoundError(err, services.ErrUserNotFound), userEmailIndex, services.ErrUserEmailTaken)
+	}
+
+	return user, nil
+}
+
+func (repo *UserPostgresRepository) DeleteUser(userId int) error {
+	/*Delete user from db, user referenced by transactions or wallets is not deleted.*/
+
+	// build query string
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 RETURNING id", userTableName)
+
+	// evaluate delete query
+	err := repo.db.QueryRowx(query, userId).Scan(&userId)
+	err = constraintError(err, transactionUserForeignKey, services.ErrUserInUse)
+	err = constraintError(err, walletUserForeignKey, services.ErrUserInUse)
+
+	return notFoundError(err, services.ErrUserNotFound)
+}
+
+func (repo *UserPostgresRepository) GetUserTransactionsById(userId int, filter *models.TransactionFilter) ([]*models.Transaction, error) {
+	/*Return page of transaction structs retrieved from db filtered by user id.*/
+	return repo.selectUserTransactions("user_id", userId, filter)
+}
+
+func (repo *UserPostgresRepository) selectUserTransactions(
+	userColumn string, userValue interface{}, filter *models.TransactionFilter,
+) ([]*models.Transaction, error) {
+	/*Return slice of transaction structs filtered by user column value and filter params.*/
 	var transactions []*models.Transaction = make([]*models.Transaction, 0)
-	var query string
 
 	// build query string
-	query = fmt.Sprintf("SELECT * FROM %s WHERE user_email = $1 ORDER BY created_at DESC;", transactionTableName)
+	query, args := userTransactionsQuery(userColumn, userValue, filter)
 
 	// evaluate query and parse data to slice of transaction structs
-	if err := repo.db.Select(&transactions, query, userEmail); err != nil {
+	if err := repo.db.Select(&transactions, query+";", args...); err != nil {
 		return nil, err
 	}
 
 	return transactions, nil
 }
+
+func userTransactionsQuery(
+	userColumn string, userValue interface{}, filter *models.TransactionFilter,
+) (string, []interface{}) {
+	/*
+		Build query selecting transactions filtered by user column value and filter params.
+
+		Rows are ordered from newest to oldest, zero filter limit means no limit.
+	*/
+	var query *selectQuery = newSelectQuery(transactionTableName).Where(userColumn+" = ?", userValue)
+
+	if filter.Status != "" {
+		query.Where("status = ?", filter.Status)
+	}
+	if filter.Currency != "" {
+		query.Where("currency = ?", filter.Currency)
+	}
+	if filter.AmountMin != nil {
+		query.Where("amount >= ?", *filter.AmountMin)
+	}
+	if filter.AmountMax != nil {
+		query.Where("amount <= ?", *filter.AmountMax)
+	}
+	if filter.CreatedFrom != nil {
+		query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	// keyset pagination, retrieve rows placed after the cursor
+	if filter.After != nil {
+		query.Where("(created_at, id) < (?, ?)", filter.After.CreatedAt, filter.After.Id)
+	}
+
+	return query.OrderBy("created_at", true).OrderBy("id", true).Limit(filter.Limit).Build()
+}
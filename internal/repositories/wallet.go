@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/ledger"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var walletTableName = "wallet"
+
+type WalletPostgresRepository struct {
+	db *database.PostgresDB
+}
+
+func NewWalletPostgresRepository(db *database.PostgresDB) *WalletPostgresRepository {
+	/*Wallet postgres repository constructor function.*/
+	return &WalletPostgresRepository{db: db}
+}
+
+func (repo *WalletPostgresRepository) GetUserWallets(userId int) ([]*models.Wallet, error) {
+	/*Return slice of all wallets of the user ordered by currency.*/
+	var wallets []*models.Wallet = make([]*models.Wallet, 0)
+
+	// build query string
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id = $1 ORDER BY currency;", walletTableName)
+
+	// evaluate query and parse data to slice of wallet structs
+	if err := repo.db.Select(&wallets, query, userId); err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+func (repo *WalletPostgresRepository) DepositWallet(userId int, currency string, amount int64) (*models.Wallet, error) {
+	/*
+		Add amount to available funds of user wallet and return wallet struct filled with new wallet data.
+
+		Wallet is created if it does not exist, deposit is recorded to the ledger within the same db transaction.
+	*/
+	var wallet *models.Wallet
+
+	// start new db transaction
+	dbTransaction, err := repo.db.Beginx()
+
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err = selectWalletForUpdate(dbTransaction, userId, currency)
+
+	if err == nil {
+		err = changeWalletBalance(dbTransaction, wallet, amount, 0)
+	}
+
+	if err == nil {
+		err = insertLedgerEntry(dbTransaction, ledger.DepositEntry(wallet, amount))
+	}
+
+	if err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+
+	// return wallet struct filled with data and commit db transaction
+	return wallet, dbTransaction.Commit()
+}
+
+func applyTransactionToWallet(dbTransaction *sqlx.Tx, transaction *models.Transaction) error {
+	/*
+		Change balances of user wallet according to assigned transaction status within db transaction.
+
+		services.ErrInsufficientFunds is returned if wallet available funds are not enough to hold transaction amount.
+	*/
+	var wallet *models.Wallet
+	var err error
+
+	available, held := services.TransactionWalletChange(transaction)
+	if available == 0 && held == 0 {
+		return nil
+	}
+
+	if wallet, err = selectWalletForUpdate(dbTransaction, transaction.UserId, transaction.Currency); err != nil {
+		return err
+	}
+
+	return changeWalletBalance(dbTransaction, wallet, available, held)
+}
+
+func selectWalletForUpdate(dbTransaction *sqlx.Tx, userId int, currency string) (*models.Wallet, error) {
+	/*
+		Return wallet struct of the user and currency, row is locked until the end of db transaction.
+
+		Empty wallet is created if it does not exist.
+	*/
+	var wallet models.Wallet = models.Wallet{}
+
+	// build query string, conflicting insert waits for concurrent db transaction creating the same wallet
+	query := fmt.Sprintf(
+		"INSERT INTO %s (user_id, currency) values ($1, $2) ON CONFLICT (user_id, currency) DO NOTHING;",
+		walletTableName)
+
+	if _, err := dbTransaction.Exec(query, userId, currency); err != nil {
+		return nil, constraintError(err, walletUserForeignKey, services.ErrUserNotFound)
+	}
+
+	// build query string
+	query = fmt.Sprintf("SELECT * FROM %s WHERE user_id = $1 AND currency = $2 FOR UPDATE;", walletTableName)
+
+	// evaluate query and parse data to wallet struct
+	if err := dbTransaction.Get(&wallet, query, userId, currency); err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+func changeWalletBalance(dbTransaction *sqlx.Tx, wallet *models.Wallet, available int64, held int64) error {
+	/*
+		Add passed amounts to balances of locked wallet, fill wallet struct with new row data.
+
+		Available funds can not be overdrawn, held funds of the wallet are guarded by db constraint.
+	*/
+	if wallet.Available+available < 0 {
+		return services.ErrInsufficientFunds
+	}
+
+	if (available > 0 && wallet.Available > math.MaxInt64-available) || (held > 0 && wallet.Held > math.MaxInt64-held) {
+		return services.ErrWalletBalanceExceeded
+	}
+
+	// build query string
+	query := fmt.Sprintf(
+		`UPDATE %s SET available = available + $1, held = held + $2, updated_at = now()::timestamptz
+		WHERE id = $3 RETURNING *;`,
+		walletTableName)
+
+	// evalate update query and parse new row data to wallet struct
+	return dbTransaction.QueryRowx(query, available, held, wallet.Id).StructScan(wallet)
+}
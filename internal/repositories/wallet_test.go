@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletPostgresRepository_ConcurrentHolds(t *testing.T) {
+	// Arrange
+	const workers = 16
+	const funded = 5
+	var wg sync.WaitGroup
+	var start chan struct{} = make(chan struct{})
+	var created chan *models.Transaction = make(chan *models.Transaction, workers)
+	var failures chan error = make(chan error, workers)
+
+	db := newTestPostgresDB(t)
+	defer db.Close()
+
+	// user without wallets, so balances are not affected by other tests
+	user := newTestUser(t, db)
+	userId := user.Id
+
+	repo := NewTransactionPostgresRepository(db)
+	walletRepo := NewWalletPostgresRepository(db)
+
+	_, err := walletRepo.DepositWallet(userId, "EUR", funded*1000)
+	require.NoError(t, err)
+
+	// Act
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			transaction, err := repo.CreateTransaction(&models.Transaction{
+				UserId:    userId,
+				UserEmail: user.Email,
+				Amount:    1000,
+				Currency:  "EUR",
+				Status:    services.TransactionNewStatus,
+			}, nil)
+
+			if err != nil {
+				failures <- err
+			} else {
+				created <- transaction
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(created)
+	close(failures)
+
+	// Assert
+	assert.Len(t, created, funded, "only funded transactions must be created")
+	for err := range failures {
+		assert.True(t, errors.Is(err, services.ErrInsufficientFunds), err.Error())
+	}
+
+	wallets, err := walletRepo.GetUserWallets(userId)
+	require.NoError(t, err)
+	require.Len(t, wallets, 1)
+	assert.Equal(t, int64(0), wallets[0].Available)
+	assert.Equal(t, int64(funded*1000), wallets[0].Held)
+
+	// captured transaction amount is debited from held funds
+	_, err = repo.UpdateTransactionStatus(<-created, services.TransactionSuccessStatus, nil)
+	require.NoError(t, err)
+
+	wallets, err = walletRepo.GetUserWallets(userId)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), wallets[0].Available)
+	assert.Equal(t, int64((funded-1)*1000), wallets[0].Held)
+}
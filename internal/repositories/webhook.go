@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/database"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	webhookEndpointTableName = "webhook_endpoint"
+	webhookEventTableName    = "webhook_event"
+	webhookDeliveryTableName = "webhook_delivery"
+)
+
+type WebhookPostgresRepository struct {
+	db *database.PostgresDB
+}
+
+func NewWebhookPostgresRepository(db *database.PostgresDB) *WebhookPostgresRepository {
+	/*Webhook postgres repository constructor function.*/
+	return &WebhookPostgresRepository{db: db}
+}
+
+func (repo *WebhookPostgresRepository) CreateWebhookEndpoint(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	/*Insert new webhook endpoint data to DB and return endpoint struct filled with new endpoint data.*/
+
+	// build query string
+	query := fmt.Sprintf(
+		"INSERT INTO %s (url, secret, merchant_id) values ($1, $2, $3) RETURNING *", webhookEndpointTableName)
+
+	// evalate insert query and parse new row data to endpoint struct
+	err := repo.db.QueryRowx(query, endpoint.URL, endpoint.Secret, endpoint.MerchantId).StructScan(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (repo *WebhookPostgresRepository) GetWebhookEndpoints(merchantId *string) ([]*models.WebhookEndpoint, error) {
+	/*Return slice of active webhook endpoint structs of the merchant retrieved from db (all endpoints if merchant is nil).*/
+	var endpoints []*models.WebhookEndpoint = make([]*models.WebhookEndpoint, 0)
+
+	// build query string
+	query := fmt.Sprintf(
+		"SELECT * FROM %s WHERE is_active AND ($1::varchar IS NULL OR merchant_id = $1) ORDER BY id;",
+		webhookEndpointTableName)
+
+	// evaluate query and parse data to slice of endpoint structs
+	if err := repo.db.Select(&endpoints, query, merchantId); err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (repo *WebhookPostgresRepository) DeactivateWebhookEndpoint(
+	endpointId int, merchantId *string,
+) (*models.WebhookEndpoint, error) {
+	/*
+		Deactivate webhook endpoint, pending deliveries to this endpoint are not performed anymore.
+
+		Endpoint of another merchant is not found (any endpoint is deactivated if merchant is nil).
+	*/
+	var endpoint models.WebhookEndpoint = models.WebhookEndpoint{}
+
+	// build query string
+	query := fmt.Sprintf(
+		`UPDATE %s SET is_active = false
+		WHERE id = $1 AND is_active AND ($2::varchar IS NULL OR merchant_id = $2) RETURNING *;`,
+		webhookEndpointTableName)
+
+	// evaluate query and parse data to endpoint struct
+	if err := repo.db.Get(&endpoint, query, endpointId, merchantId); err != nil {
+		return nil, notFoundError(err, services.ErrWebhookEndpointNotFound)
+	}
+
+	return &endpoint, nil
+}
+
+func (repo *WebhookPostgresRepository) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	/*
+		Return slice of pending deliveries which next attempt time has come.
+
+		Claimed deliveries next attempt time is moved forward by lease duration,
+		so concurrent dispatchers do not perform the same deliveries.
+	*/
+	var deliveries []*models.WebhookDelivery = make([]*models.WebhookDelivery, 0)
+
+	// build query string
+	query := fmt.Sprintf(
+		`WITH claimed AS (
+			UPDATE %[1]s SET next_attempt_at = now()::timestamptz + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT delivery.id FROM %[1]s delivery
+				JOIN %[2]s endpoint ON endpoint.id = delivery.endpoint_id
+				WHERE delivery.status = $3 AND delivery.next_attempt_at <= now()::timestamptz AND endpoint.is_active
+				ORDER BY delivery.next_attempt_at
+				LIMIT $1
+				FOR UPDATE OF delivery SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT claimed.*,
+			endpoint.url AS endpoint_url,
+			endpoint.secret AS endpoint_secret,
+			event.event_type AS event_type,
+			event.payload AS event_payload,
+			event.created_at AS event_created_at
+		FROM claimed
+		JOIN %[2]s endpoint ON endpoint.id = claimed.endpoint_id
+		JOIN %[3]s event ON event.id = claimed.event_id
+		ORDER BY claimed.next_attempt_at;`,
+		webhookDeliveryTableName, webhookEndpointTableName, webhookEventTableName)
+
+	// evaluate query and parse data to slice of delivery structs
+	err := repo.db.Select(&deliveries, query, limit, lease.Seconds(), services.WebhookDeliveryPendingStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (repo *WebhookPostgresRepository) UpdateWebhookDelivery(delivery *models.WebhookDelivery) error {
+	/*Save delivery attempt result: status, attempts count, next attempt time and last error.*/
+
+	// build query string
+	query := fmt.Sprintf(
+		`UPDATE %s SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, updated_at = now()::timestamptz
+		WHERE id = $5;`,
+		webhookDeliveryTableName)
+
+	_, err := repo.db.Exec(
+		query, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.Id)
+
+	return err
+}
+
+func enqueueTransactionEvent(dbTransaction *sqlx.Tx, eventType string, transaction *models.Transaction) error {
+	/*
+		Insert transaction event to the outbox within db transaction.
+
+		Delivery of the event is scheduled for every active webhook endpoint of transaction merchant
+		and endpoints registered by admin (without merchant).
+	*/
+	payload, err := json.Marshal(transaction)
+	if err != nil {
+		return err
+	}
+
+	// build query string
+	query := fmt.Sprintf(
+		`WITH event AS (
+			INSERT INTO %s (event_type, transaction_id, payload) values ($1, $2, $3) RETURNING id
+		)
+		INSERT INTO %s (event_id, endpoint_id)
+		SELECT event.id, endpoint.id FROM event, %s endpoint
+		WHERE endpoint.is_active AND (endpoint.merchant_id IS NULL OR endpoint.merchant_id = $4);`,
+		webhookEventTableName, webhookDeliveryTableName, webhookEndpointTableName)
+
+	_, err = dbTransaction.Exec(query, eventType, transaction.Id, string(payload), transaction.MerchantId)
+
+	return err
+}
@@ -0,0 +1,127 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/money"
+)
+
+const (
+	// Outcomes of risk decision, blocked transaction is created with ERROR status
+	OutcomeAllow string = "ALLOW"
+	OutcomeBlock string = "BLOCK"
+)
+
+const (
+	// Names of rules reported in risk decision reasons
+	RuleAmountLimit   string = "amount_limit"
+	RuleUserVelocity  string = "user_velocity"
+	RuleEmailVelocity string = "email_velocity"
+	RuleBlockedEmail  string = "blocked_email"
+	RuleBlockedDomain string = "blocked_domain"
+	RuleDailyVolume   string = "daily_volume"
+)
+
+// Previously created transactions used by velocity and volume rules
+type History interface {
+	// number of user transactions (of any status) created since the time
+	CountUserTransactions(userId int, since time.Time) (int, error)
+	// number of transactions with the email (of any status) created since the time
+	CountEmailTransactions(email string, since time.Time) (int, error)
+	// total amount of user transactions in the currency created since the time, ERROR, FAILED, CANCELED and EXPIRED are not counted
+	SumUserAmount(userId int, currency string, since time.Time) (int64, error)
+}
+
+// Risk rules engine, evaluates every rule so decision contains all of matched rules
+type Engine struct {
+	rules   *Rules
+	history History
+	now     func() time.Time
+}
+
+func NewEngine(rules *Rules, history History) *Engine {
+	/*Risk engine constructor function.*/
+	return &Engine{rules: rules, history: history, now: time.Now}
+}
+
+func (engine *Engine) Evaluate(transaction *models.Transaction) (*models.RiskDecision, error) {
+	/*Evaluate rules for transaction which is about to be created, transaction is blocked if any rule matches.*/
+	var decision *models.RiskDecision = &models.RiskDecision{Outcome: OutcomeAllow, Reasons: []models.RiskReason{}}
+	var now time.Time = engine.now()
+	var email string = strings.ToLower(transaction.UserEmail)
+	var reason string
+
+	// amount rules
+	if limit, ok := engine.rules.AmountLimits[transaction.Currency]; ok && transaction.Amount > limit {
+		reason = fmt.Sprintf("amount exceeds %s %s limit", money.Format(limit, transaction.Currency), transaction.Currency)
+		block(decision, RuleAmountLimit, reason)
+	}
+
+	// blocked payers
+	if engine.rules.BlockedEmails[email] {
+		block(decision, RuleBlockedEmail, "email is blocked")
+	}
+	if domain, blocked := engine.blockedDomain(email); blocked {
+		block(decision, RuleBlockedDomain, fmt.Sprintf("email domain %s is blocked", domain))
+	}
+
+	// velocity rules
+	for _, limit := range engine.rules.VelocityLimits {
+		var count int
+		var err error
+		var rule string = RuleUserVelocity
+
+		if limit.Key == VelocityKeyEmail {
+			rule = RuleEmailVelocity
+			count, err = engine.history.CountEmailTransactions(transaction.UserEmail, now.Add(-limit.Window))
+		} else {
+			count, err = engine.history.CountUserTransactions(transaction.UserId, now.Add(-limit.Window))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s history failed: %w", rule, err)
+		}
+
+		if count >= limit.MaxCount {
+			reason = fmt.Sprintf("%d transactions were created within %s, limit is %d", count, limit.Window, limit.MaxCount)
+			block(decision, rule, reason)
+		}
+	}
+
+	// daily volume rules
+	if limit, ok := engine.rules.DailyVolumeLimits[transaction.Currency]; ok {
+		total, err := engine.history.SumUserAmount(transaction.UserId, transaction.Currency, now.Add(-dailyVolumeWindow))
+		if err != nil {
+			return nil, fmt.Errorf("history.SumUserAmount failed: %w", err)
+		}
+
+		if total+transaction.Amount > limit {
+			reason = fmt.Sprintf("daily volume exceeds %s %s limit", money.Format(limit, transaction.Currency), transaction.Currency)
+			block(decision, RuleDailyVolume, reason)
+		}
+	}
+
+	return decision, nil
+}
+
+func (engine *Engine) blockedDomain(email string) (string, bool) {
+	/*Return blocked domain of the email, subdomains of blocked domain are blocked too.*/
+	_, domain, found := strings.Cut(email, "@")
+
+	for found && domain != "" {
+		if engine.rules.BlockedDomains[domain] {
+			return domain, true
+		}
+		_, domain, found = strings.Cut(domain, ".")
+	}
+
+	return "", false
+}
+
+func block(decision *models.RiskDecision, rule string, message string) {
+	/*Add matched rule to the decision and block the transaction.*/
+	decision.Outcome = OutcomeBlock
+	decision.Reasons = append(decision.Reasons, models.RiskReason{Rule: rule, Message: message})
+}
@@ -0,0 +1,138 @@
+package risk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// in-memory History implementation
+type historyStub struct {
+	userCount  int
+	emailCount int
+	amount     int64
+	err        error
+}
+
+func (stub *historyStub) CountUserTransactions(userId int, since time.Time) (int, error) {
+	return stub.userCount, stub.err
+}
+
+func (stub *historyStub) CountEmailTransactions(email string, since time.Time) (int, error) {
+	return stub.emailCount, stub.err
+}
+
+func (stub *historyStub) SumUserAmount(userId int, currency string, since time.Time) (int64, error) {
+	return stub.amount, stub.err
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	// Arrange
+	rules := NewRules()
+	rules.AmountLimits["EUR"] = 100000
+	rules.VelocityLimits = []VelocityLimit{
+		{Key: VelocityKeyUser, Window: time.Hour, MaxCount: 5},
+		{Key: VelocityKeyEmail, Window: 24 * time.Hour, MaxCount: 20},
+	}
+	rules.BlockedEmails["fraud@mail.ru"] = true
+	rules.BlockedDomains["spam.com"] = true
+	rules.DailyVolumeLimits["EUR"] = 500000
+
+	testTable := []struct {
+		name            string
+		transaction     *models.Transaction
+		history         *historyStub
+		expectedOutcome string
+		expectedRules   []string
+	}{
+		{
+			name:            "Test allowed transaction",
+			transaction:     &models.Transaction{UserEmail: "email@mail.ru", Amount: 100000, Currency: "EUR"},
+			history:         &historyStub{userCount: 4, emailCount: 19, amount: 400000},
+			expectedOutcome: OutcomeAllow,
+			expectedRules:   []string{},
+		},
+		{
+			name:            "Test currency without limits",
+			transaction:     &models.Transaction{UserEmail: "email@mail.ru", Amount: 10000000, Currency: "JPY"},
+			history:         &historyStub{},
+			expectedOutcome: OutcomeAllow,
+			expectedRules:   []string{},
+		},
+		{
+			name:            "Test amount limit",
+			transaction:     &models.Transaction{UserEmail: "email@mail.ru", Amount: 100001, Currency: "EUR"},
+			history:         &historyStub{},
+			expectedOutcome: OutcomeBlock,
+			expectedRules:   []string{RuleAmountLimit},
+		},
+		{
+			name:            "Test blocked email",
+			transaction:     &models.Transaction{UserEmail: "Fraud@Mail.ru", Amount: 100, Currency: "EUR"},
+			history:         &historyStub{},
+			expectedOutcome: OutcomeBlock,
+			expectedRules:   []string{RuleBlockedEmail},
+		},
+		{
+			name:            "Test blocked subdomain",
+			transaction:     &models.Transaction{UserEmail: "user@eu.spam.com", Amount: 100, Currency: "EUR"},
+			history:         &historyStub{},
+			expectedOutcome: OutcomeBlock,
+			expectedRules:   []string{RuleBlockedDomain},
+		},
+		{
+			name:            "Test similar domain",
+			transaction:     &models.Transaction{UserEmail: "user@notspam.com", Amount: 100, Currency: "EUR"},
+			history:         &historyStub{},
+			expectedOutcome: OutcomeAllow,
+			expectedRules:   []string{},
+		},
+		{
+			name:            "Test velocity limits",
+			transaction:     &models.Transaction{UserEmail: "email@mail.ru", Amount: 100, Currency: "EUR"},
+			history:         &historyStub{userCount: 5, emailCount: 20},
+			expectedOutcome: OutcomeBlock,
+			expectedRules:   []string{RuleUserVelocity, RuleEmailVelocity},
+		},
+		{
+			name:            "Test daily volume",
+			transaction:     &models.Transaction{UserEmail: "email@mail.ru", Amount: 100001, Currency: "EUR"},
+			history:         &historyStub{amount: 400000},
+			expectedOutcome: OutcomeBlock,
+			expectedRules:   []string{RuleAmountLimit, RuleDailyVolume},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			engine := NewEngine(rules, testCase.history)
+
+			// Act
+			decision, err := engine.Evaluate(testCase.transaction)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedOutcome, decision.Outcome)
+			matched := []string{}
+			for _, reason := range decision.Reasons {
+				matched = append(matched, reason.Rule)
+				assert.NotEmpty(t, reason.Message)
+			}
+			assert.Equal(t, testCase.expectedRules, matched)
+		})
+	}
+}
+
+func TestEngine_EvaluateHistoryError(t *testing.T) {
+	rules := NewRules()
+	rules.DailyVolumeLimits["EUR"] = 500000
+	engine := NewEngine(rules, &historyStub{err: errors.New("some error")})
+
+	_, err := engine.Evaluate(&models.Transaction{UserEmail: "email@mail.ru", Amount: 100, Currency: "EUR"})
+
+	assert.Error(t, err)
+}
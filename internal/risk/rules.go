@@ -0,0 +1,149 @@
+package risk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/money"
+)
+
+const (
+	// Velocity limit keys, transactions are counted per user or per user email
+	VelocityKeyUser  string = "user"
+	VelocityKeyEmail string = "email"
+)
+
+// Window of daily volume limits
+const dailyVolumeWindow time.Duration = 24 * time.Hour
+
+// Risk rules evaluated on transaction creation, amounts are in minor units of currency
+type Rules struct {
+	// max amount of single transaction per currency
+	AmountLimits map[string]int64
+	// max number of transactions per user (email) within the window
+	VelocityLimits []VelocityLimit
+	// lowercased emails and domains of users which are not allowed to pay
+	BlockedEmails  map[string]bool
+	BlockedDomains map[string]bool
+	// max total amount of user transactions per currency within the last 24 hours
+	DailyVolumeLimits map[string]int64
+}
+
+type VelocityLimit struct {
+	Key      string
+	Window   time.Duration
+	MaxCount int
+}
+
+// Rules file content, amounts are decimal strings in major units of currency, e.g.:
+//
+//	{
+//		"amount_limits": {"EUR": "1000.00"},
+//		"velocity_limits": [{"key": "user", "window": "1h", "max_count": 10}],
+//		"blocked_emails": ["fraud@mail.com"],
+//		"blocked_domains": ["mailinator.com"],
+//		"daily_volume_limits": {"EUR": "5000"}
+//	}
+type fileRules struct {
+	AmountLimits      map[string]string   `json:"amount_limits"`
+	VelocityLimits    []fileVelocityLimit `json:"velocity_limits"`
+	BlockedEmails     []string            `json:"blocked_emails"`
+	BlockedDomains    []string            `json:"blocked_domains"`
+	DailyVolumeLimits map[string]string   `json:"daily_volume_limits"`
+}
+
+type fileVelocityLimit struct {
+	Key      string `json:"key"`
+	Window   string `json:"window"`
+	MaxCount int    `json:"max_count"`
+}
+
+func NewRules() *Rules {
+	/*Empty rules constructor function, every transaction is allowed by empty rules.*/
+	return &Rules{
+		AmountLimits:      map[string]int64{},
+		BlockedEmails:     map[string]bool{},
+		BlockedDomains:    map[string]bool{},
+		DailyVolumeLimits: map[string]int64{},
+	}
+}
+
+func LoadRules(path string) (*Rules, error) {
+	/*Read and validate JSON rules file, unknown fields are rejected to catch misspelled rules.*/
+	var content fileRules
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoder *json.Decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&content); err != nil {
+		return nil, err
+	}
+
+	return content.rules()
+}
+
+func (content *fileRules) rules() (*Rules, error) {
+	/*Validate rules file content and convert it to rules.*/
+	var rules *Rules = NewRules()
+	var err error
+
+	if rules.AmountLimits, err = parseLimits(content.AmountLimits); err != nil {
+		return nil, fmt.Errorf("amount_limits: %w", err)
+	}
+	if rules.DailyVolumeLimits, err = parseLimits(content.DailyVolumeLimits); err != nil {
+		return nil, fmt.Errorf("daily_volume_limits: %w", err)
+	}
+
+	for i, limit := range content.VelocityLimits {
+		window, err := time.ParseDuration(limit.Window)
+		switch {
+		case limit.Key != VelocityKeyUser && limit.Key != VelocityKeyEmail:
+			return nil, fmt.Errorf("velocity_limits %d: key must be %q or %q", i+1, VelocityKeyUser, VelocityKeyEmail)
+		case err != nil || window <= 0:
+			return nil, fmt.Errorf("velocity_limits %d: window must be a positive duration", i+1)
+		case limit.MaxCount <= 0:
+			return nil, fmt.Errorf("velocity_limits %d: max_count must be positive", i+1)
+		}
+		rules.VelocityLimits = append(rules.VelocityLimits, VelocityLimit{Key: limit.Key, Window: window, MaxCount: limit.MaxCount})
+	}
+
+	for _, email := range content.BlockedEmails {
+		if !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("blocked_emails: %q is not an email", email)
+		}
+		rules.BlockedEmails[strings.ToLower(strings.TrimSpace(email))] = true
+	}
+	for _, domain := range content.BlockedDomains {
+		domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "@."))
+		if domain == "" {
+			return nil, errors.New("blocked_domains: domain must not be empty")
+		}
+		rules.BlockedDomains[domain] = true
+	}
+
+	return rules, nil
+}
+
+func parseLimits(limits map[string]string) (map[string]int64, error) {
+	/*Parse per currency amounts in major units to minor units.*/
+	var parsed map[string]int64 = make(map[string]int64, len(limits))
+
+	for currency, limit := range limits {
+		amount, err := money.ParseAmount(limit, currency)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", currency, err)
+		}
+		parsed[currency] = amount.Minor
+	}
+
+	return parsed, nil
+}
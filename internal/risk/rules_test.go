@@ -0,0 +1,96 @@
+package risk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRules(t *testing.T) {
+	testTable := []struct {
+		name          string
+		content       string
+		expectedRules *Rules
+		expectedErr   string
+	}{
+		{
+			name: "Test rules file",
+			content: `{
+				"amount_limits": {"EUR": "1000.50", "JPY": "100000"},
+				"velocity_limits": [{"key": "user", "window": "1h", "max_count": 10}, {"key": "email", "window": "24h", "max_count": 50}],
+				"blocked_emails": [" Fraud@Mail.ru "],
+				"blocked_domains": ["@Spam.com"],
+				"daily_volume_limits": {"EUR": "5000"}
+			}`,
+			expectedRules: &Rules{
+				AmountLimits: map[string]int64{"EUR": 100050, "JPY": 100000},
+				VelocityLimits: []VelocityLimit{
+					{Key: VelocityKeyUser, Window: time.Hour, MaxCount: 10},
+					{Key: VelocityKeyEmail, Window: 24 * time.Hour, MaxCount: 50},
+				},
+				BlockedEmails:     map[string]bool{"fraud@mail.ru": true},
+				BlockedDomains:    map[string]bool{"spam.com": true},
+				DailyVolumeLimits: map[string]int64{"EUR": 500000},
+			},
+		},
+		{
+			name:          "Test empty rules file",
+			content:       `{}`,
+			expectedRules: NewRules(),
+		},
+		{
+			name:        "Test misspelled rule",
+			content:     `{"amount_limit": {"EUR": "1000"}}`,
+			expectedErr: `json: unknown field "amount_limit"`,
+		},
+		{
+			name:        "Test unknown currency",
+			content:     `{"amount_limits": {"ABC": "1000"}}`,
+			expectedErr: "amount_limits: ABC: unknown currency",
+		},
+		{
+			name:        "Test amount exceeding currency precision",
+			content:     `{"daily_volume_limits": {"JPY": "1000.5"}}`,
+			expectedErr: "daily_volume_limits: JPY: amount has more decimal places than currency allows",
+		},
+		{
+			name:        "Test unknown velocity key",
+			content:     `{"velocity_limits": [{"key": "ip", "window": "1h", "max_count": 10}]}`,
+			expectedErr: `velocity_limits 1: key must be "user" or "email"`,
+		},
+		{
+			name:        "Test invalid velocity window",
+			content:     `{"velocity_limits": [{"key": "user", "window": "1 day", "max_count": 10}]}`,
+			expectedErr: "velocity_limits 1: window must be a positive duration",
+		},
+		{
+			name:        "Test invalid velocity count",
+			content:     `{"velocity_limits": [{"key": "user", "window": "1h"}]}`,
+			expectedErr: "velocity_limits 1: max_count must be positive",
+		},
+		{
+			name:        "Test invalid blocked email",
+			content:     `{"blocked_emails": ["mail.ru"]}`,
+			expectedErr: `blocked_emails: "mail.ru" is not an email`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.json")
+			assert.NoError(t, os.WriteFile(path, []byte(testCase.content), 0600))
+
+			rules, err := LoadRules(path)
+
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedRules, rules)
+		})
+	}
+}
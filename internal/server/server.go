@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"net"
 	"net/http"
 )
 
@@ -18,3 +20,21 @@ func NewServer(port string, handler http.Handler) *Server {
 func (server *Server) Run() error {
 	return server.httpServer.ListenAndServe()
 }
+
+func (server *Server) Serve(listener net.Listener) error {
+	/*Accept connections on given listener, used instead of Run when listener is created by caller.*/
+	return server.httpServer.Serve(listener)
+}
+
+func (server *Server) Shutdown(ctx context.Context) error {
+	/*
+		Stop accepting new connections and wait for in-flight requests to complete until ctx is done,
+		connections still active after that are closed. Run returns http.ErrServerClosed after shutdown.
+	*/
+	err := server.httpServer.Shutdown(ctx)
+	if err != nil {
+		server.httpServer.Close()
+	}
+
+	return err
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestServer(t *testing.T, handler http.Handler) (*Server, string, chan error) {
+	/*Start server on random local port, return it's URL and channel receiving Serve result.*/
+	var served chan error = make(chan error, 1)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewServer("0", handler)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	return server, "http://" + listener.Addr().String(), served
+}
+
+func TestServer_ShutdownDrainsRequests(t *testing.T) {
+	// Arrange
+	var started chan struct{} = make(chan struct{})
+	var release chan struct{} = make(chan struct{})
+	var responses chan *http.Response = make(chan *http.Response, 1)
+
+	server, url, served := startTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	go func() {
+		response, err := http.Get(url)
+		if err != nil {
+			close(responses)
+			return
+		}
+		response.Body.Close()
+		responses <- response
+	}()
+	<-started
+
+	// Act
+	var shutdownDone chan error = make(chan error, 1)
+	go func() {
+		shutdownDone <- server.Shutdown(context.Background())
+	}()
+
+	// Assert
+	// shutdown waits for in-flight request
+	select {
+	case <-shutdownDone:
+		t.Fatal("shutdown returned before in-flight request completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	response, ok := <-responses
+	require.True(t, ok, "in-flight request must not be cut off")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NoError(t, <-shutdownDone)
+	assert.True(t, errors.Is(<-served, http.ErrServerClosed))
+
+	// new connections are not accepted after shutdown
+	_, err := http.Get(url)
+	assert.Error(t, err)
+}
+
+func TestServer_ShutdownTimeout(t *testing.T) {
+	// Arrange
+	var started chan struct{} = make(chan struct{})
+	var release chan struct{} = make(chan struct{})
+	defer close(release)
+
+	server, url, served := startTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	go func() {
+		if response, err := http.Get(url); err == nil {
+			response.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := server.Shutdown(ctx)
+
+	// Assert
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	assert.True(t, errors.Is(<-served, http.ErrServerClosed))
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+)
+
+const (
+	// Prefix of generated API keys, makes keys recognizable (e.g. by secret scanners)
+	apiKeyPrefix string = "psk_"
+	// Length of random part of generated API key in bytes
+	apiKeyLength int = 32
+	// Length of key prefix stored in plain text to distinguish keys
+	apiKeyDisplayPrefixLength int = 12
+)
+
+var (
+	// Error returned when API key does not exist
+	ErrApiKeyNotFound = NewError("api_key_not_found", "API key not found.", ErrNotFound)
+	// Error returned when API key does not exist or is revoked
+	ErrInvalidApiKey = NewError("invalid_api_key", "API key is not valid.", ErrUnauthorized)
+)
+
+type ApiKeyRepository interface {
+	CreateApiKey(apiKey *models.ApiKey) (*models.ApiKey, error)
+	GetApiKeys() ([]*models.ApiKey, error)
+	GetActiveApiKeyByHash(keyHash string) (*models.ApiKey, error)
+	TouchApiKey(apiKeyId int) error
+	RevokeApiKey(apiKeyId int) (*models.ApiKey, error)
+}
+
+type ApiKeyService struct {
+	repo ApiKeyRepository
+}
+
+func NewApiKeyService(repo ApiKeyRepository) *ApiKeyService {
+	/*API key service constructor function.*/
+	return &ApiKeyService{repo: repo}
+}
+
+func (service *ApiKeyService) Create(apiKeyInput *models.ApiKeyInput) (*models.ApiKey, error) {
+	/*
+		Create new API key of provider.
+
+		Only hash of the key is stored, plain key is returned only once.
+	*/
+	var secret []byte = make([]byte, apiKeyLength)
+
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("rand.Read failed: %w", err)
+	}
+
+	var key string = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey, err := service.repo.CreateApiKey(&models.ApiKey{
+		Provider: apiKeyInput.Provider,
+		Prefix:   key[:apiKeyDisplayPrefixLength],
+		KeyHash:  hashApiKey(key),
+		Scopes:   apiKeyInput.Scopes,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("service.repo.CreateApiKey failed: %w", err)
+	}
+
+	apiKey.Key = key
+
+	return apiKey, nil
+}
+
+func (service *ApiKeyService) List() ([]*models.ApiKey, error) {
+	/*Retrieve list of all API keys (including revoked ones).*/
+	return service.repo.GetApiKeys()
+}
+
+func (service *ApiKeyService) Revoke(apiKeyId int) error {
+	/*Revoke API key, it can not be used for authentication anymore.*/
+	_, err := service.repo.RevokeApiKey(apiKeyId)
+
+	return err
+}
+
+func (service *ApiKeyService) Authenticate(key string) (*auth.Principal, error) {
+	/*
+		Find active API key and return principal of it's provider, key last usage time is updated.
+
+		Subject is prefixed with auth.ProviderSubjectPrefix, so provider named as user id does not get access of the user.
+	*/
+	apiKey, err := service.repo.GetActiveApiKeyByHash(hashApiKey(key))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = service.repo.TouchApiKey(apiKey.Id); err != nil {
+		return nil, fmt.Errorf("service.repo.TouchApiKey failed: %w", err)
+	}
+
+	return &auth.Principal{Subject: auth.ProviderSubjectPrefix + apiKey.Provider, Scopes: apiKey.Scopes}, nil
+}
+
+func hashApiKey(key string) string {
+	/*Return hex encoded SHA-256 hash of API key.*/
+	var hash [32]byte = sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// in-memory ApiKeyRepository implementation
+type apiKeyRepositoryStub struct {
+	apiKeys []*models.ApiKey
+}
+
+func (stub *apiKeyRepositoryStub) CreateApiKey(apiKey *models.ApiKey) (*models.ApiKey, error) {
+	apiKey.Id = len(stub.apiKeys) + 1
+	stub.apiKeys = append(stub.apiKeys, apiKey)
+	return apiKey, nil
+}
+
+func (stub *apiKeyRepositoryStub) GetApiKeys() ([]*models.ApiKey, error) {
+	return stub.apiKeys, nil
+}
+
+func (stub *apiKeyRepositoryStub) GetActiveApiKeyByHash(keyHash string) (*models.ApiKey, error) {
+	for _, apiKey := range stub.apiKeys {
+		if apiKey.KeyHash == keyHash && apiKey.RevokedAt == nil {
+			return apiKey, nil
+		}
+	}
+	return nil, ErrInvalidApiKey
+}
+
+func (stub *apiKeyRepositoryStub) TouchApiKey(apiKeyId int) error {
+	now := time.Now()
+	stub.apiKeys[apiKeyId-1].LastUsedAt = &now
+	return nil
+}
+
+func (stub *apiKeyRepositoryStub) RevokeApiKey(apiKeyId int) (*models.ApiKey, error) {
+	if apiKeyId > len(stub.apiKeys) {
+		return nil, ErrApiKeyNotFound
+	}
+	now := time.Now()
+	stub.apiKeys[apiKeyId-1].RevokedAt = &now
+	return stub.apiKeys[apiKeyId-1], nil
+}
+
+func TestApiKeyService(t *testing.T) {
+	repo := &apiKeyRepositoryStub{}
+	service := NewApiKeyService(repo)
+
+	// created key is returned once, only it's hash is stored
+	apiKey, err := service.Create(&models.ApiKeyInput{Provider: "provider-1", Scopes: []string{RoleProvider}})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(apiKey.Key, apiKeyPrefix))
+	assert.Equal(t, apiKey.Key[:apiKeyDisplayPrefixLength], apiKey.Prefix)
+	assert.Equal(t, hashApiKey(apiKey.Key), repo.apiKeys[0].KeyHash)
+
+	// key authenticates provider and updates last usage time
+	principal, err := service.Authenticate(apiKey.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, "provider:provider-1", principal.Subject)
+	assert.Equal(t, "provider-1", principal.Provider())
+	assert.True(t, principal.HasScope(RoleProvider))
+	assert.NotNil(t, repo.apiKeys[0].LastUsedAt)
+
+	// revoked key is not accepted anymore
+	assert.NoError(t, service.Revoke(apiKey.Id))
+	_, err = service.Authenticate(apiKey.Key)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	assert.True(t, errors.Is(service.Revoke(100), ErrNotFound))
+}
+
+func TestApiKeyService_NumericProvider(t *testing.T) {
+	repo := &apiKeyRepositoryStub{}
+	service := NewApiKeyService(repo)
+
+	apiKey, err := service.Create(&models.ApiKeyInput{Provider: "42", Scopes: []string{RoleProvider}})
+	assert.NoError(t, err)
+
+	// provider named as user id does not get access to the user
+	principal, err := service.Authenticate(apiKey.Key)
+	assert.NoError(t, err)
+	assert.False(t, principal.IsUser(42))
+	assert.True(t, errors.Is(AuthorizeTransaction(principal, &models.Transaction{UserId: 42}), ErrTransactionAccessDenied))
+	assert.Equal(t, "42", principal.Provider())
+}
@@ -0,0 +1,88 @@
+package services
+
+import "errors"
+
+var (
+	// Kinds of errors, every service error is wrapping one of them
+	ErrNotFound        = errors.New("entity not found")
+	ErrForbidden       = errors.New("access to entity is denied")
+	ErrUnauthorized    = errors.New("credentials are not valid")
+	ErrConflict        = errors.New("entity was modified concurrently")
+	ErrTerminalStatus  = errors.New("entity status does not allow the operation")
+	ErrValidation      = errors.New("validation failed")
+	ErrPaymentRequired = errors.New("not enough funds to perform the operation")
+	ErrUnavailable     = errors.New("external service is not available")
+)
+
+var (
+	// Errors returned when entities do not exist
+	ErrTransactionNotFound     = NewError("transaction_not_found", "Transaction not found.", ErrNotFound)
+	ErrWebhookEndpointNotFound = NewError("webhook_endpoint_not_found", "Webhook endpoint not found.", ErrNotFound)
+	ErrLedgerAccountNotFound   = NewError("ledger_account_not_found", "Ledger account not found.", ErrNotFound)
+	ErrUserNotFound            = NewError("user_not_found", "User not found.", ErrNotFound)
+	// Error returned when entity belongs to another user
+	ErrTransactionAccessDenied = NewError(
+		"transaction_access_denied", "Transaction belongs to another user.", ErrForbidden)
+	// Error returned when user data is accessed with token of another user
+	ErrUserAccessDenied = NewError("user_access_denied", "Access to data of another user is denied.", ErrForbidden)
+	// Error returned when webhook endpoints are managed by principal which is neither merchant nor admin
+	ErrWebhookEndpointAccessDenied = NewError(
+		"webhook_endpoint_access_denied", "Webhook endpoints are managed only by merchants and admins.", ErrForbidden)
+	// Error returned when entity was modified by another request between read and update
+	ErrConcurrentUpdate = NewError("concurrent_update", "Entity was modified concurrently, try again.", ErrConflict)
+)
+
+// Typed service error with machine-readable code
+type Error struct {
+	Code    string
+	Message string
+	Kind    error
+}
+
+// Error returned when input data is not valid, contains per field details
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Validation error details of one field
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+func NewError(code string, message string, kind error) *Error {
+	/*Service error constructor function.*/
+	return &Error{Code: code, Message: message, Kind: kind}
+}
+
+func (err *Error) Error() string {
+	/*Return human readable error message.*/
+	return err.Message
+}
+
+func (err *Error) Unwrap() error {
+	/*Return kind of the error, so errors.Is(err, ErrNotFound) and etc. work.*/
+	return err.Kind
+}
+
+func (err *ValidationError) Error() string {
+	/*Return text representation of validation error.*/
+	var message string = ErrValidation.Error()
+
+	for i, field := range err.Fields {
+		if i == 0 {
+			message += ": "
+		} else {
+			message += "; "
+		}
+		message += field.Field + " " + field.Message
+	}
+
+	return message
+}
+
+func (err *ValidationError) Unwrap() error {
+	/*Return kind of the error.*/
+	return ErrValidation
+}
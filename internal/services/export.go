@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+)
+
+type ExportRepository interface {
+	ExportTransactions(ctx context.Context, filter *models.TransactionSearchFilter, fn func(*models.Transaction) error) error
+}
+
+type ExportService struct {
+	repo ExportRepository
+}
+
+func NewExportService(repo ExportRepository) *ExportService {
+	/*Export service constructor function.*/
+	return &ExportService{repo: repo}
+}
+
+func (service *ExportService) Transactions(
+	ctx context.Context, filter *models.TransactionSearchFilter, fn func(*models.Transaction) error,
+) error {
+	/*
+		Pass every transaction matching search params to fn in requested order, export is not paginated
+		so limit and cursor are ignored.
+
+		Export is stopped on the first fn error or when ctx is done.
+	*/
+	var exportFilter models.TransactionSearchFilter = *filter
+
+	exportFilter.Limit = 0
+	exportFilter.Cursor = ""
+	exportFilter.After = nil
+
+	return service.repo.ExportTransactions(ctx, &exportFilter, fn)
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type exportRepositoryStub struct {
+	filter *models.TransactionSearchFilter
+}
+
+func (repo *exportRepositoryStub) ExportTransactions(
+	ctx context.Context, filter *models.TransactionSearchFilter, fn func(*models.Transaction) error,
+) error {
+	repo.filter = filter
+	return fn(&models.Transaction{Id: 1})
+}
+
+func TestExportService_Transactions(t *testing.T) {
+	// Arrange
+	var exported []int
+	repo := &exportRepositoryStub{}
+	filter := &models.TransactionSearchFilter{
+		Statuses: []string{TransactionNewStatus},
+		Sort:     "amount",
+		Order:    "asc",
+		Limit:    50,
+		Cursor:   "abc",
+	}
+
+	// Act
+	err := NewExportService(repo).Transactions(context.Background(), filter, func(transaction *models.Transaction) error {
+		exported = append(exported, transaction.Id)
+		return nil
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, exported)
+	// export is not paginated
+	assert.Equal(t, &models.TransactionSearchFilter{
+		Statuses: []string{TransactionNewStatus},
+		Sort:     "amount",
+		Order:    "asc",
+	}, repo.filter)
+	// passed filter is not changed
+	assert.Equal(t, 50, filter.Limit)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/money"
+)
+
+type FxRepository interface {
+	GetUserTransactionsFxRates(userId int, currency string, filter *models.TransactionFilter) ([]*models.TransactionFxRate, error)
+	GetUserTransactionsFxTotals(userId int, currency string, filter *models.TransactionFilter) ([]*models.TransactionFxRateTotal, error)
+	CreateFxConversions(conversions []*models.FxConversion) error
+}
+
+type FxService struct {
+	repo FxRepository
+}
+
+func NewFxService(repo FxRepository) *FxService {
+	/*FX service constructor function.*/
+	return &FxService{repo: repo}
+}
+
+func (service *FxService) ConvertUserTransactions(
+	userId int, currency string, filter *models.TransactionFilter,
+) (*models.ConvertedTransactionsReport, error) {
+	/*
+		Retrieve page of user's transactions and total of all transactions matched the filter
+		converted to the currency with rates effective at transactions creation dates.
+
+		Rates used to convert transactions of the page are recorded for audit.
+	*/
+	var err error
+	var pageFilter *models.TransactionFilter
+	var totalsFilter models.TransactionFilter = *filter
+	var transactions []*models.TransactionFxRate
+	var totals []*models.TransactionFxRateTotal
+	var conversions []*models.FxConversion
+	var report *models.ConvertedTransactionsReport
+
+	// report currency is validated by handler, but service can be used without it
+	target, ok := money.LookupCurrency(currency)
+	if !ok {
+		return nil, &ValidationError{Fields: []FieldError{
+			{Field: "currency", Tag: "currency", Message: "must be a supported ISO 4217 currency code"},
+		}}
+	}
+
+	if pageFilter, err = newPageFilter(filter); err != nil {
+		return nil, err
+	}
+
+	if transactions, err = service.repo.GetUserTransactionsFxRates(userId, currency, pageFilter); err != nil {
+		return nil, err
+	}
+
+	// total covers all pages
+	totalsFilter.Cursor, totalsFilter.Limit = "", 0
+	if totals, err = service.repo.GetUserTransactionsFxTotals(userId, currency, &totalsFilter); err != nil {
+		return nil, err
+	}
+
+	report = &models.ConvertedTransactionsReport{
+		Currency: target.Code,
+		Items:    make([]*models.ConvertedTransaction, 0, len(transactions)),
+		Limit:    filter.Limit,
+	}
+
+	for _, total := range totals {
+		amount, err := convertAmount(total.Amount, total.Currency, target, total.EffectiveFxRate)
+		if err != nil {
+			return nil, err
+		}
+		if (amount > 0 && report.Total.Amount > math.MaxInt64-amount) ||
+			(amount < 0 && report.Total.Amount < math.MinInt64-amount) {
+			return nil, money.ErrOverflow
+		}
+		report.Total.Amount += amount
+		report.Total.Count += total.Count
+	}
+	report.Total.AmountFormatted = money.Format(report.Total.Amount, target.Code)
+
+	// extra row (if exists) is dropped and used as a sign of the next page
+	if len(transactions) > filter.Limit {
+		transactions = transactions[:filter.Limit]
+		last := transactions[filter.Limit-1]
+		report.NextCursor = (&models.TransactionCursor{CreatedAt: last.CreatedAt, Id: last.Id}).Encode()
+	}
+
+	for _, transaction := range transactions {
+		amount, err := convertAmount(transaction.Amount, transaction.Currency, target, transaction.EffectiveFxRate)
+		if err != nil {
+			return nil, err
+		}
+
+		converted := &models.ConvertedTransaction{
+			Transaction:              &transaction.Transaction,
+			ConvertedAmount:          amount,
+			ConvertedAmountFormatted: money.Format(amount, target.Code),
+			Rate:                     "1",
+		}
+
+		if transaction.RateId != nil {
+			converted.Rate = *transaction.Rate
+			converted.RateId = transaction.RateId
+			converted.RateDate = transaction.RateDate.Format("2006-01-02")
+
+			conversions = append(conversions, &models.FxConversion{
+				TransactionId:   transaction.Id,
+				RateId:          *transaction.RateId,
+				FromCurrency:    transaction.Currency,
+				ToCurrency:      target.Code,
+				Rate:            *transaction.Rate,
+				Amount:          transaction.Amount,
+				ConvertedAmount: amount,
+			})
+		}
+
+		report.Items = append(report.Items, converted)
+	}
+
+	if len(conversions) > 0 {
+		if err = service.repo.CreateFxConversions(conversions); err != nil {
+			return nil, fmt.Errorf("service.repo.CreateFxConversions failed: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func convertAmount(amount int64, currency string, target money.Currency, rate models.EffectiveFxRate) (int64, error) {
+	/*Convert amount in minor units of currency to target currency, same currency amount is returned as is.*/
+	if currency == target.Code {
+		return amount, nil
+	}
+
+	source, ok := money.LookupCurrency(currency)
+	if !ok || rate.Rate == nil {
+		return 0, NewError(
+			"fx_rate_not_found",
+			fmt.Sprintf("FX rate from %s to %s effective at transaction creation date is not found.", currency, target.Code),
+			ErrConflict,
+		)
+	}
+
+	parsedRate, err := money.ParseRate(*rate.Rate)
+	if err != nil {
+		return 0, fmt.Errorf("money.ParseRate failed: %w", err)
+	}
+
+	return money.Convert(amount, source, target, parsedRate)
+}
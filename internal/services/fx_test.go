@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// FxRepository implementation which returns prepared rows and records conversions
+type fxRepositoryStub struct {
+	transactions []*models.TransactionFxRate
+	totals       []*models.TransactionFxRateTotal
+	conversions  []*models.FxConversion
+}
+
+func (stub *fxRepositoryStub) GetUserTransactionsFxRates(userId int, currency string, filter *models.TransactionFilter) ([]*models.TransactionFxRate, error) {
+	return stub.transactions, nil
+}
+
+func (stub *fxRepositoryStub) GetUserTransactionsFxTotals(userId int, currency string, filter *models.TransactionFilter) ([]*models.TransactionFxRateTotal, error) {
+	return stub.totals, nil
+}
+
+func (stub *fxRepositoryStub) CreateFxConversions(conversions []*models.FxConversion) error {
+	stub.conversions = append(stub.conversions, conversions...)
+	return nil
+}
+
+func newEffectiveFxRate(id int, rate string, date time.Time) models.EffectiveFxRate {
+	return models.EffectiveFxRate{RateId: &id, Rate: &rate, RateDate: &date}
+}
+
+func TestFxService_ConvertUserTransactions(t *testing.T) {
+	rateDate := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	createdAt := rateDate.Add(10 * time.Hour)
+
+	testTable := []struct {
+		name                string
+		transactions        []*models.TransactionFxRate
+		totals              []*models.TransactionFxRateTotal
+		limit               int
+		expectedAmounts     []int64
+		expectedTotal       models.ConvertedTotal
+		expectedConversions int
+		expectedNextCursor  bool
+		expectedErr         error
+	}{
+		{
+			name: "Test convert transactions of different currencies",
+			transactions: []*models.TransactionFxRate{
+				{
+					Transaction:     models.Transaction{Id: 2, Amount: 1000, Currency: "EUR", CreatedAt: createdAt},
+					EffectiveFxRate: newEffectiveFxRate(1, "1.1", rateDate),
+				},
+				{
+					Transaction: models.Transaction{Id: 1, Amount: 500, Currency: "USD", CreatedAt: createdAt},
+				},
+			},
+			totals: []*models.TransactionFxRateTotal{
+				{Currency: "EUR", Amount: 3000, Count: 3, EffectiveFxRate: newEffectiveFxRate(1, "1.1", rateDate)},
+				{Currency: "USD", Amount: 500, Count: 1},
+				{Currency: "JPY", Amount: 15000, Count: 1, EffectiveFxRate: newEffectiveFxRate(2, "0.0066", rateDate)},
+			},
+			limit:               10,
+			expectedAmounts:     []int64{1100, 500},
+			expectedTotal:       models.ConvertedTotal{Count: 5, Amount: 13700, AmountFormatted: "137.00"},
+			expectedConversions: 1,
+		},
+		{
+			name: "Test convert page with the next one",
+			transactions: []*models.TransactionFxRate{
+				{Transaction: models.Transaction{Id: 2, Amount: 100, Currency: "USD", CreatedAt: createdAt}},
+				{Transaction: models.Transaction{Id: 1, Amount: 200, Currency: "USD", CreatedAt: createdAt}},
+			},
+			totals:             []*models.TransactionFxRateTotal{{Currency: "USD", Amount: 300, Count: 2}},
+			limit:              1,
+			expectedAmounts:    []int64{100},
+			expectedTotal:      models.ConvertedTotal{Count: 2, Amount: 300, AmountFormatted: "3.00"},
+			expectedNextCursor: true,
+		},
+		{
+			name: "Test convert without rate",
+			transactions: []*models.TransactionFxRate{
+				{Transaction: models.Transaction{Id: 1, Amount: 1000, Currency: "EUR", CreatedAt: createdAt}},
+			},
+			totals:      []*models.TransactionFxRateTotal{{Currency: "EUR", Amount: 1000, Count: 1}},
+			limit:       10,
+			expectedErr: ErrConflict,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &fxRepositoryStub{transactions: testCase.transactions, totals: testCase.totals}
+			service := NewFxService(repo)
+
+			report, err := service.ConvertUserTransactions(1, "USD", &models.TransactionFilter{Limit: testCase.limit})
+
+			if testCase.expectedErr != nil {
+				assert.True(t, errors.Is(err, testCase.expectedErr), err)
+				assert.Empty(t, repo.conversions)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "USD", report.Currency)
+			assert.Equal(t, testCase.expectedTotal, report.Total)
+			assert.Len(t, report.Items, len(testCase.expectedAmounts))
+			for i, amount := range testCase.expectedAmounts {
+				assert.Equal(t, amount, report.Items[i].ConvertedAmount)
+			}
+			assert.Len(t, repo.conversions, testCase.expectedConversions)
+			assert.Equal(t, testCase.expectedNextCursor, report.NextCursor != "")
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+)
+
+var (
+	// Error returned when idempotency key was already used with another request body
+	ErrIdempotencyKeyMismatch = NewError(
+		"idempotency_key_mismatch", "Idempotency-Key was already used with another request.", ErrConflict)
+	// Error returned when request with the same idempotency key is not finished yet
+	ErrIdempotencyKeyInProgress = NewError(
+		"idempotency_key_in_progress", "Request with the same Idempotency-Key is in progress.", ErrConflict)
+)
+
+type IdempotencyKeyRepository interface {
+	ReserveIdempotencyKey(idempotencyKey *models.IdempotencyKey) (bool, error)
+	GetIdempotencyKey(subject string, key string) (*models.IdempotencyKey, error)
+	SaveIdempotencyKeyResponse(subject string, key string, status int, headers map[string][]string, body []byte) error
+	DeleteIdempotencyKey(subject string, key string) error
+}
+
+type IdempotencyService struct {
+	repo IdempotencyKeyRepository
+	ttl  time.Duration
+}
+
+func NewIdempotencyService(repo IdempotencyKeyRepository, ttl time.Duration) *IdempotencyService {
+	/*Idempotency service constructor function.*/
+	return &IdempotencyService{repo: repo, ttl: ttl}
+}
+
+func (service *IdempotencyService) Begin(subject string, key string, requestBody []byte) (*models.IdempotencyKey, error) {
+	/*
+		Start processing of the request with idempotency key of the subject (client), keys of subjects do not collide.
+
+		Return nil if key was reserved for the current request,
+		otherwise return stored idempotency key with the original response to replay.
+	*/
+	var err error
+	var reserved bool
+	var idempotencyKey *models.IdempotencyKey
+	var requestHash string = hashRequestBody(requestBody)
+
+	// trying to reserve key for the current request
+	reserved, err = service.repo.ReserveIdempotencyKey(&models.IdempotencyKey{
+		Subject:     subject,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(service.ttl),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("service.repo.ReserveIdempotencyKey failed: %w", err)
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	// key already used, retrieve stored request data
+	idempotencyKey, err = service.repo.GetIdempotencyKey(subject, key)
+
+	if err != nil {
+		return nil, fmt.Errorf("service.repo.GetIdempotencyKey failed: %w", err)
+	}
+
+	// same key is allowed to be used only with the same request body
+	if idempotencyKey.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyMismatch
+	}
+
+	// original request still processing, response is not stored yet
+	if idempotencyKey.ResponseStatus == nil {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return idempotencyKey, nil
+}
+
+func (service *IdempotencyService) Complete(
+	subject string, key string, status int, headers map[string][]string, body []byte,
+) error {
+	/*Store response of the request performed with idempotency key.*/
+	return service.repo.SaveIdempotencyKeyResponse(subject, key, status, headers, body)
+}
+
+func (service *IdempotencyService) Release(subject string, key string) error {
+	/*Release idempotency key so failed request can be retried with the same key.*/
+	return service.repo.DeleteIdempotencyKey(subject, key)
+}
+
+func hashRequestBody(body []byte) string {
+	/*Return hex encoded SHA-256 hash of request body.*/
+	var hash [32]byte = sha256.Sum256(body)
+
+	return hex.EncodeToString(hash[:])
+}
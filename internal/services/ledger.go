@@ -0,0 +1,62 @@
+package services
+
+import "github.com/Pythonyan3/payment-service/internal/models"
+
+type LedgerRepository interface {
+	GetLedgerAccountBalances() ([]*models.LedgerAccountBalance, error)
+	GetLedgerAccountBalance(accountId int) (*models.LedgerAccountBalance, error)
+	GetLedgerAccountEntries(accountId int, filter *models.LedgerEntryFilter) ([]*models.LedgerEntry, error)
+}
+
+type LedgerService struct {
+	repo LedgerRepository
+}
+
+func NewLedgerService(repo LedgerRepository) *LedgerService {
+	/*Ledger service constructor function.*/
+	return &LedgerService{repo: repo}
+}
+
+func (service *LedgerService) ListAccounts() ([]*models.LedgerAccountBalance, error) {
+	/*Retrieve all ledger accounts with their balances.*/
+	return service.repo.GetLedgerAccountBalances()
+}
+
+func (service *LedgerService) GetAccount(accountId int) (*models.LedgerAccountBalance, error) {
+	/*Retrieve ledger account with it's balance by PK.*/
+	return service.repo.GetLedgerAccountBalance(accountId)
+}
+
+func (service *LedgerService) GetAccountEntries(accountId int, filter *models.LedgerEntryFilter) (*models.LedgerEntryPage, error) {
+	/*Retrieve page of journal entries with postings to the account, newest entries first.*/
+	var err error
+	var pageFilter models.LedgerEntryFilter = *filter
+	var entries []*models.LedgerEntry
+	var page *models.LedgerEntryPage
+
+	// check account exists, so unknown account is not reported as account without entries
+	if _, err = service.repo.GetLedgerAccountBalance(accountId); err != nil {
+		return nil, err
+	}
+
+	if filter.Cursor != "" {
+		if pageFilter.After, err = models.DecodeTransactionCursor(filter.Cursor); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	// request one extra row to find out whether the next page exists
+	pageFilter.Limit = filter.Limit + 1
+
+	if entries, err = service.repo.GetLedgerAccountEntries(accountId, &pageFilter); err != nil {
+		return nil, err
+	}
+
+	page = &models.LedgerEntryPage{Items: entries, Limit: filter.Limit}
+	if len(entries) > filter.Limit {
+		page.Items = entries[:filter.Limit]
+		last := page.Items[filter.Limit-1]
+		page.NextCursor = (&models.TransactionCursor{CreatedAt: last.CreatedAt, Id: last.Id}).Encode()
+	}
+
+	return page, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// in-memory LedgerRepository implementation, entries are ordered from newest to oldest
+type ledgerRepositoryStub struct {
+	accounts map[int]*models.LedgerAccountBalance
+	entries  []*models.LedgerEntry
+	filter   *models.LedgerEntryFilter
+}
+
+func (stub *ledgerRepositoryStub) GetLedgerAccountBalances() ([]*models.LedgerAccountBalance, error) {
+	balances := make([]*models.LedgerAccountBalance, 0, len(stub.accounts))
+	for _, balance := range stub.accounts {
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+func (stub *ledgerRepositoryStub) GetLedgerAccountBalance(accountId int) (*models.LedgerAccountBalance, error) {
+	balance, ok := stub.accounts[accountId]
+	if !ok {
+		return nil, ErrLedgerAccountNotFound
+	}
+	return balance, nil
+}
+
+func (stub *ledgerRepositoryStub) GetLedgerAccountEntries(accountId int, filter *models.LedgerEntryFilter) ([]*models.LedgerEntry, error) {
+	stub.filter = filter
+	entries := make([]*models.LedgerEntry, 0)
+	for _, entry := range stub.entries {
+		if filter.After != nil && entry.Id >= filter.After.Id {
+			continue
+		}
+		if len(entries) == filter.Limit {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func TestLedgerService_GetAccountEntries(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	repo := &ledgerRepositoryStub{
+		accounts: map[int]*models.LedgerAccountBalance{1: {LedgerAccount: models.LedgerAccount{Id: 1}}},
+		entries: []*models.LedgerEntry{
+			{Id: 3, CreatedAt: createdAt},
+			{Id: 2, CreatedAt: createdAt},
+			{Id: 1, CreatedAt: createdAt},
+		},
+	}
+	service := NewLedgerService(repo)
+
+	// first page
+	page, err := service.GetAccountEntries(1, &models.LedgerEntryFilter{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, repo.filter.Limit)
+	assert.Equal(t, []*models.LedgerEntry{repo.entries[0], repo.entries[1]}, page.Items)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// last page
+	page, err = service.GetAccountEntries(1, &models.LedgerEntryFilter{Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.LedgerEntry{repo.entries[2]}, page.Items)
+	assert.Empty(t, page.NextCursor)
+
+	// bad cursor
+	_, err = service.GetAccountEntries(1, &models.LedgerEntryFilter{Limit: 2, Cursor: "bad"})
+	assert.True(t, errors.Is(err, ErrInvalidCursor), err)
+
+	// unknown account
+	_, err = service.GetAccountEntries(2, &models.LedgerEntryFilter{Limit: 2})
+	assert.True(t, errors.Is(err, ErrNotFound), err)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: api_key.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	reflect "reflect"
+
+	models "github.com/Pythonyan3/payment-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockApiKeyService is a mock of ApiKeyService interface.
+type MockApiKeyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockApiKeyServiceMockRecorder
+}
+
+// MockApiKeyServiceMockRecorder is the mock recorder for MockApiKeyService.
+type MockApiKeyServiceMockRecorder struct {
+	mock *MockApiKeyService
+}
+
+// NewMockApiKeyService creates a new mock instance.
+func NewMockApiKeyService(ctrl *gomock.Controller) *MockApiKeyService {
+	mock := &MockApiKeyService{ctrl: ctrl}
+	mock.recorder = &MockApiKeyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApiKeyService) EXPECT() *MockApiKeyServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockApiKeyService) Create(apiKeyInput *models.ApiKeyInput) (*models.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", apiKeyInput)
+	ret0, _ := ret[0].(*models.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockApiKeyServiceMockRecorder) Create(apiKeyInput interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockApiKeyService)(nil).Create), apiKeyInput)
+}
+
+// List mocks base method.
+func (m *MockApiKeyService) List() ([]*models.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]*models.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockApiKeyServiceMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockApiKeyService)(nil).List))
+}
+
+// Revoke mocks base method.
+func (m *MockApiKeyService) Revoke(apiKeyId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", apiKeyId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockApiKeyServiceMockRecorder) Revoke(apiKeyId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockApiKeyService)(nil).Revoke), apiKeyId)
+}
@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: export.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/Pythonyan3/payment-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockExportService is a mock of ExportService interface.
+type MockExportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportServiceMockRecorder
+}
+
+// MockExportServiceMockRecorder is the mock recorder for MockExportService.
+type MockExportServiceMockRecorder struct {
+	mock *MockExportService
+}
+
+// NewMockExportService creates a new mock instance.
+func NewMockExportService(ctrl *gomock.Controller) *MockExportService {
+	mock := &MockExportService{ctrl: ctrl}
+	mock.recorder = &MockExportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportService) EXPECT() *MockExportServiceMockRecorder {
+	return m.recorder
+}
+
+// Transactions mocks base method.
+func (m *MockExportService) Transactions(ctx context.Context, filter *models.TransactionSearchFilter, fn func(*models.Transaction) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transactions", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transactions indicates an expected call of Transactions.
+func (mr *MockExportServiceMockRecorder) Transactions(ctx, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transactions", reflect.TypeOf((*MockExportService)(nil).Transactions), ctx, filter, fn)
+}
+
+// MocktransactionWriter is a mock of transactionWriter interface.
+type MocktransactionWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MocktransactionWriterMockRecorder
+}
+
+// MocktransactionWriterMockRecorder is the mock recorder for MocktransactionWriter.
+type MocktransactionWriterMockRecorder struct {
+	mock *MocktransactionWriter
+}
+
+// NewMocktransactionWriter creates a new mock instance.
+func NewMocktransactionWriter(ctrl *gomock.Controller) *MocktransactionWriter {
+	mock := &MocktransactionWriter{ctrl: ctrl}
+	mock.recorder = &MocktransactionWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktransactionWriter) EXPECT() *MocktransactionWriterMockRecorder {
+	return m.recorder
+}
+
+// Flush mocks base method.
+func (m *MocktransactionWriter) Flush() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MocktransactionWriterMockRecorder) Flush() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MocktransactionWriter)(nil).Flush))
+}
+
+// Write mocks base method.
+func (m *MocktransactionWriter) Write(transaction *models.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write.
+func (mr *MocktransactionWriterMockRecorder) Write(transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MocktransactionWriter)(nil).Write), transaction)
+}
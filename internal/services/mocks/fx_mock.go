@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: fx.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	reflect "reflect"
+
+	models "github.com/Pythonyan3/payment-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFxService is a mock of FxService interface.
+type MockFxService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFxServiceMockRecorder
+}
+
+// MockFxServiceMockRecorder is the mock recorder for MockFxService.
+type MockFxServiceMockRecorder struct {
+	mock *MockFxService
+}
+
+// NewMockFxService creates a new mock instance.
+func NewMockFxService(ctrl *gomock.Controller) *MockFxService {
+	mock := &MockFxService{ctrl: ctrl}
+	mock.recorder = &MockFxServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFxService) EXPECT() *MockFxServiceMockRecorder {
+	return m.recorder
+}
+
+// ConvertUserTransactions mocks base method.
+func (m *MockFxService) ConvertUserTransactions(userId int, currency string, filter *models.TransactionFilter) (*models.ConvertedTransactionsReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConvertUserTransactions", userId, currency, filter)
+	ret0, _ := ret[0].(*models.ConvertedTransactionsReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConvertUserTransactions indicates an expected call of ConvertUserTransactions.
+func (mr *MockFxServiceMockRecorder) ConvertUserTransactions(userId, currency, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertUserTransactions", reflect.TypeOf((*MockFxService)(nil).ConvertUserTransactions), userId, currency, filter)
+}
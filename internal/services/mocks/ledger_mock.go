@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ledger.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	reflect "reflect"
+
+	models "github.com/Pythonyan3/payment-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLedgerService is a mock of LedgerService interface.
+type MockLedgerService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerServiceMockRecorder
+}
+
+// MockLedgerServiceMockRecorder is the mock recorder for MockLedgerService.
+type MockLedgerServiceMockRecorder struct {
+	mock *MockLedgerService
+}
+
+// NewMockLedgerService creates a new mock instance.
+func NewMockLedgerService(ctrl *gomock.Controller) *MockLedgerService {
+	mock := &MockLedgerService{ctrl: ctrl}
+	mock.recorder = &MockLedgerServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerService) EXPECT() *MockLedgerServiceMockRecorder {
+	return m.recorder
+}
+
+// GetAccount mocks base method.
+func (m *MockLedgerService) GetAccount(accountId int) (*models.LedgerAccountBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", accountId)
+	ret0, _ := ret[0].(*models.LedgerAccountBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockLedgerServiceMockRecorder) GetAccount(accountId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockLedgerService)(nil).GetAccount), accountId)
+}
+
+// GetAccountEntries mocks base method.
+func (m *MockLedgerService) GetAccountEntries(accountId int, filter *models.LedgerEntryFilter) (*models.LedgerEntryPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountEntries", accountId, filter)
+	ret0, _ := ret[0].(*models.LedgerEntryPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountEntries indicates an expected call of GetAccountEntries.
+func (mr *MockLedgerServiceMockRecorder) GetAccountEntries(accountId, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountEntries", reflect.TypeOf((*MockLedgerService)(nil).GetAccountEntries), accountId, filter)
+}
+
+// ListAccounts mocks base method.
+func (m *MockLedgerService) ListAccounts() ([]*models.LedgerAccountBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts")
+	ret0, _ := ret[0].([]*models.LedgerAccountBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockLedgerServiceMockRecorder) ListAccounts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockLedgerService)(nil).ListAccounts))
+}
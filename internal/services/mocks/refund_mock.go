@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: refund.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	reflect "reflect"
+
+	models "github.com/Pythonyan3/payment-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRefundService is a mock of RefundService interface.
+type MockRefundService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefundServiceMockRecorder
+}
+
+// MockRefundServiceMockRecorder is the mock recorder for MockRefundService.
+type MockRefundServiceMockRecorder struct {
+	mock *MockRefundService
+}
+
+// NewMockRefundService creates a new mock instance.
+func NewMockRefundService(ctrl *gomock.Controller) *MockRefundService {
+	mock := &MockRefundService{ctrl: ctrl}
+	mock.recorder = &MockRefundServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefundService) EXPECT() *MockRefundServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRefundService) Create(transactionId int, refundInput *models.RefundInput) (*models.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", transactionId, refundInput)
+	ret0, _ := ret[0].(*models.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRefundServiceMockRecorder) Create(transactionId, refundInput interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRefundService)(nil).Create), transactionId, refundInput)
+}
+
+// GetById mocks base method.
+func (m *MockRefundService) GetById(transactionId, refundId int) (*models.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", transactionId, refundId)
+	ret0, _ := ret[0].(*models.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockRefundServiceMockRecorder) GetById(transactionId, refundId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockRefundService)(nil).GetById), transactionId, refundId)
+}
+
+// GetTransaction mocks base method.
+func (m *MockRefundService) GetTransaction(transactionId int) (*models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", transactionId)
+	ret0, _ := ret[0].(*models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockRefundServiceMockRecorder) GetTransaction(transactionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockRefundService)(nil).GetTransaction), transactionId)
+}
+
+// ListByTransaction mocks base method.
+func (m *MockRefundService) ListByTransaction(transactionId int) ([]*models.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByTransaction", transactionId)
+	ret0, _ := ret[0].([]*models.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByTransaction indicates an expected call of ListByTransaction.
+func (mr *MockRefundServiceMockRecorder) ListByTransaction(transactionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByTransaction", reflect.TypeOf((*MockRefundService)(nil).ListByTransaction), transactionId)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockRefundService) UpdateStatus(transactionId, refundId int, status, role string) (*models.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", transactionId, refundId, status, role)
+	ret0, _ := ret[0].(*models.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockRefundServiceMockRecorder) UpdateStatus(transactionId, refundId, status, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockRefundService)(nil).UpdateStatus), transactionId, refundId, status, role)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stats.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	reflect "reflect"
+
+	models "github.com/Pythonyan3/payment-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStatsService is a mock of StatsService interface.
+type MockStatsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsServiceMockRecorder
+}
+
+// MockStatsServiceMockRecorder is the mock recorder for MockStatsService.
+type MockStatsServiceMockRecorder struct {
+	mock *MockStatsService
+}
+
+// NewMockStatsService creates a new mock instance.
+func NewMockStatsService(ctrl *gomock.Controller) *MockStatsService {
+	mock := &MockStatsService{ctrl: ctrl}
+	mock.recorder = &MockStatsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsService) EXPECT() *MockStatsServiceMockRecorder {
+	return m.recorder
+}
+
+// TransactionStats mocks base method.
+func (m *MockStatsService) TransactionStats(filter *models.TransactionStatsFilter) (*models.TransactionStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransactionStats", filter)
+	ret0, _ := ret[0].(*models.TransactionStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransactionStats indicates an expected call of TransactionStats.
+func (mr *MockStatsServiceMockRecorder) TransactionStats(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactionStats", reflect.TypeOf((*MockStatsService)(nil).TransactionStats), filter)
+}
@@ -8,6 +8,7 @@ import (
 	http "net/http"
 	reflect "reflect"
 
+	auth "github.com/Pythonyan3/payment-service/internal/auth"
 	models "github.com/Pythonyan3/payment-service/internal/models"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +36,21 @@ func (m *MockTransactionService) EXPECT() *MockTransactionServiceMockRecorder {
 	return m.recorder
 }
 
+// Cancel mocks base method.
+func (m *MockTransactionService) Cancel(transactionId int, principal *auth.Principal) (*models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", transactionId, principal)
+	ret0, _ := ret[0].(*models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockTransactionServiceMockRecorder) Cancel(transactionId, principal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockTransactionService)(nil).Cancel), transactionId, principal)
+}
+
 // Create mocks base method.
 func (m *MockTransactionService) Create(transactionInput *models.TransactionInput) (*models.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -65,19 +81,49 @@ func (mr *MockTransactionServiceMockRecorder) GetById(transactionId interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockTransactionService)(nil).GetById), transactionId)
 }
 
+// Search mocks base method.
+func (m *MockTransactionService) Search(filter *models.TransactionSearchFilter) (*models.TransactionPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", filter)
+	ret0, _ := ret[0].(*models.TransactionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockTransactionServiceMockRecorder) Search(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockTransactionService)(nil).Search), filter)
+}
+
+// Sync mocks base method.
+func (m *MockTransactionService) Sync(transactionId int) (*models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", transactionId)
+	ret0, _ := ret[0].(*models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockTransactionServiceMockRecorder) Sync(transactionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockTransactionService)(nil).Sync), transactionId)
+}
+
 // UpdateStatus mocks base method.
-func (m *MockTransactionService) UpdateStatus(transactionId int, status string) (*models.Transaction, error) {
+func (m *MockTransactionService) UpdateStatus(transactionId int, status, role string) (*models.Transaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStatus", transactionId, status)
+	ret := m.ctrl.Call(m, "UpdateStatus", transactionId, status, role)
 	ret0, _ := ret[0].(*models.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateStatus indicates an expected call of UpdateStatus.
-func (mr *MockTransactionServiceMockRecorder) UpdateStatus(transactionId, status interface{}) *gomock.Call {
+func (mr *MockTransactionServiceMockRecorder) UpdateStatus(transactionId, status, role interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockTransactionService)(nil).UpdateStatus), transactionId, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockTransactionService)(nil).UpdateStatus), transactionId, status, role)
 }
 
 // MockAuthMiddleware is a mock of AuthMiddleware interface.
@@ -116,3 +162,132 @@ func (mr *MockAuthMiddlewareMockRecorder) AuthMiddleware(next interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthMiddleware", reflect.TypeOf((*MockAuthMiddleware)(nil).AuthMiddleware), next)
 }
+
+// RequireScope mocks base method.
+func (m *MockAuthMiddleware) RequireScope(scopes ...string) func(http.HandlerFunc) http.HandlerFunc {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range scopes {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RequireScope", varargs...)
+	ret0, _ := ret[0].(func(http.HandlerFunc) http.HandlerFunc)
+	return ret0
+}
+
+// RequireScope indicates an expected call of RequireScope.
+func (mr *MockAuthMiddlewareMockRecorder) RequireScope(scopes ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequireScope", reflect.TypeOf((*MockAuthMiddleware)(nil).RequireScope), scopes...)
+}
+
+// MockIdempotencyMiddleware is a mock of IdempotencyMiddleware interface.
+type MockIdempotencyMiddleware struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMiddlewareMockRecorder
+}
+
+// MockIdempotencyMiddlewareMockRecorder is the mock recorder for MockIdempotencyMiddleware.
+type MockIdempotencyMiddlewareMockRecorder struct {
+	mock *MockIdempotencyMiddleware
+}
+
+// NewMockIdempotencyMiddleware creates a new mock instance.
+func NewMockIdempotencyMiddleware(ctrl *gomock.Controller) *MockIdempotencyMiddleware {
+	mock := &MockIdempotencyMiddleware{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMiddlewareMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyMiddleware) EXPECT() *MockIdempotencyMiddlewareMockRecorder {
+	return m.recorder
+}
+
+// IdempotencyMiddleware mocks base method.
+func (m *MockIdempotencyMiddleware) IdempotencyMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IdempotencyMiddleware", next)
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// IdempotencyMiddleware indicates an expected call of IdempotencyMiddleware.
+func (mr *MockIdempotencyMiddlewareMockRecorder) IdempotencyMiddleware(next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IdempotencyMiddleware", reflect.TypeOf((*MockIdempotencyMiddleware)(nil).IdempotencyMiddleware), next)
+}
+
+// MockSignatureMiddleware is a mock of SignatureMiddleware interface.
+type MockSignatureMiddleware struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignatureMiddlewareMockRecorder
+}
+
+// MockSignatureMiddlewareMockRecorder is the mock recorder for MockSignatureMiddleware.
+type MockSignatureMiddlewareMockRecorder struct {
+	mock *MockSignatureMiddleware
+}
+
+// NewMockSignatureMiddleware creates a new mock instance.
+func NewMockSignatureMiddleware(ctrl *gomock.Controller) *MockSignatureMiddleware {
+	mock := &MockSignatureMiddleware{ctrl: ctrl}
+	mock.recorder = &MockSignatureMiddlewareMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSignatureMiddleware) EXPECT() *MockSignatureMiddlewareMockRecorder {
+	return m.recorder
+}
+
+// SignatureMiddleware mocks base method.
+func (m *MockSignatureMiddleware) SignatureMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignatureMiddleware", next)
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// SignatureMiddleware indicates an expected call of SignatureMiddleware.
+func (mr *MockSignatureMiddlewareMockRecorder) SignatureMiddleware(next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignatureMiddleware", reflect.TypeOf((*MockSignatureMiddleware)(nil).SignatureMiddleware), next)
+}
+
+// MockRateLimitMiddleware is a mock of RateLimitMiddleware interface.
+type MockRateLimitMiddleware struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitMiddlewareMockRecorder
+}
+
+// MockRateLimitMiddlewareMockRecorder is the mock recorder for MockRateLimitMiddleware.
+type MockRateLimitMiddlewareMockRecorder struct {
+	mock *MockRateLimitMiddleware
+}
+
+// NewMockRateLimitMiddleware creates a new mock instance.
+func NewMockRateLimitMiddleware(ctrl *gomock.Controller) *MockRateLimitMiddleware {
+	mock := &MockRateLimitMiddleware{ctrl: ctrl}
+	mock.recorder = &MockRateLimitMiddlewareMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimitMiddleware) EXPECT() *MockRateLimitMiddlewareMockRecorder {
+	return m.recorder
+}
+
+// RateLimitMiddleware mocks base method.
+func (m *MockRateLimitMiddleware) RateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimitMiddleware", next)
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// RateLimitMiddleware indicates an expected call of RateLimitMiddleware.
+func (mr *MockRateLimitMiddlewareMockRecorder) RateLimitMiddleware(next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimitMiddleware", reflect.TypeOf((*MockRateLimitMiddleware)(nil).RateLimitMiddleware), next)
+}
@@ -8,7 +8,6 @@ import (
 	reflect "reflect"
 
 	models "github.com/Pythonyan3/payment-service/internal/models"
-	
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,32 +34,91 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// Create mocks base method.
+func (m *MockUserService) Create(userInput *models.UserInput) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", userInput)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserServiceMockRecorder) Create(userInput interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserService)(nil).Create), userInput)
+}
+
+// Delete mocks base method.
+func (m *MockUserService) Delete(userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserServiceMockRecorder) Delete(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserService)(nil).Delete), userId)
+}
+
+// GetById mocks base method.
+func (m *MockUserService) GetById(userId int) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", userId)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockUserServiceMockRecorder) GetById(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockUserService)(nil).GetById), userId)
+}
+
 // GetUserTransactionsByEmail mocks base method.
-func (m *MockUserService) GetUserTransactionsByEmail(userEmail string) ([]*models.Transaction, error) {
+func (m *MockUserService) GetUserTransactionsByEmail(userEmail string, filter *models.TransactionFilter) (*models.TransactionPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserTransactionsByEmail", userEmail)
-	ret0, _ := ret[0].([]*models.Transaction)
+	ret := m.ctrl.Call(m, "GetUserTransactionsByEmail", userEmail, filter)
+	ret0, _ := ret[0].(*models.TransactionPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserTransactionsByEmail indicates an expected call of GetUserTransactionsByEmail.
-func (mr *MockUserServiceMockRecorder) GetUserTransactionsByEmail(userEmail interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) GetUserTransactionsByEmail(userEmail, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTransactionsByEmail", reflect.TypeOf((*MockUserService)(nil).GetUserTransactionsByEmail), userEmail)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTransactionsByEmail", reflect.TypeOf((*MockUserService)(nil).GetUserTransactionsByEmail), userEmail, filter)
 }
 
 // GetUserTransactionsById mocks base method.
-func (m *MockUserService) GetUserTransactionsById(userId int) ([]*models.Transaction, error) {
+func (m *MockUserService) GetUserTransactionsById(userId int, filter *models.TransactionFilter) (*models.TransactionPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserTransactionsById", userId)
-	ret0, _ := ret[0].([]*models.Transaction)
+	ret := m.ctrl.Call(m, "GetUserTransactionsById", userId, filter)
+	ret0, _ := ret[0].(*models.TransactionPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserTransactionsById indicates an expected call of GetUserTransactionsById.
-func (mr *MockUserServiceMockRecorder) GetUserTransactionsById(userId interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) GetUserTransactionsById(userId, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTransactionsById", reflect.TypeOf((*MockUserService)(nil).GetUserTransactionsById), userId, filter)
+}
+
+// Update mocks base method.
+func (m *MockUserService) Update(userId int, userInput *models.UserInput) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", userId, userInput)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockUserServiceMockRecorder) Update(userId, userInput interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTransactionsById", reflect.TypeOf((*MockUserService)(nil).GetUserTransactionsById), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserService)(nil).Update), userId, userInput)
 }
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: wallet.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	reflect "reflect"
+
+	models "github.com/Pythonyan3/payment-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWalletService is a mock of WalletService interface.
+type MockWalletService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletServiceMockRecorder
+}
+
+// MockWalletServiceMockRecorder is the mock recorder for MockWalletService.
+type MockWalletServiceMockRecorder struct {
+	mock *MockWalletService
+}
+
+// NewMockWalletService creates a new mock instance.
+func NewMockWalletService(ctrl *gomock.Controller) *MockWalletService {
+	mock := &MockWalletService{ctrl: ctrl}
+	mock.recorder = &MockWalletServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWalletService) EXPECT() *MockWalletServiceMockRecorder {
+	return m.recorder
+}
+
+// Deposit mocks base method.
+func (m *MockWalletService) Deposit(userId int, currency string, depositInput *models.WalletDepositInput) (*models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deposit", userId, currency, depositInput)
+	ret0, _ := ret[0].(*models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *MockWalletServiceMockRecorder) Deposit(userId, currency, depositInput interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockWalletService)(nil).Deposit), userId, currency, depositInput)
+}
+
+// List mocks base method.
+func (m *MockWalletService) List(userId int) ([]*models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", userId)
+	ret0, _ := ret[0].([]*models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockWalletServiceMockRecorder) List(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWalletService)(nil).List), userId)
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/providers"
+)
+
+var (
+	// Error returned when payment provider can not process the operation now
+	ErrPaymentProviderUnavailable = NewError(
+		"payment_provider_unavailable", "Payment provider is not available, try again later.", ErrUnavailable)
+	// Error returned when payment provider refuses the operation with payment current state
+	ErrPaymentProviderRejected = NewError(
+		"payment_provider_rejected", "Payment provider rejected the operation.", ErrConflict)
+	// Error returned when transaction status is synchronized but it was not sent to payment provider
+	ErrNoPaymentProvider = NewError(
+		"no_payment_provider", "Transaction is not processed by payment provider.", ErrValidation)
+)
+
+func transactionProvider(registry *providers.Registry, transaction *models.Transaction) (providers.PaymentProvider, error) {
+	/*Return provider processing the transaction, nil if transaction was not sent to provider.*/
+	if transaction.Provider == nil || transaction.ProviderReference == nil {
+		return nil, nil
+	}
+
+	return registry.Get(*transaction.Provider)
+}
+
+func forwardTransactionStatus(
+	ctx context.Context, provider providers.PaymentProvider, transaction *models.Transaction, status string,
+) error {
+	/*Perform provider operation corresponding to transaction new status: capture on success, void otherwise.*/
+	var result *providers.Result
+	var err error
+	var expected string
+
+	switch status {
+	case TransactionSuccessStatus:
+		expected = providers.StatusCaptured
+		result, err = provider.Capture(ctx, *transaction.ProviderReference, transaction.Amount)
+	case TransactionFailedStatus, TransactionCanceledStatus, TransactionExpiredStatus:
+		expected = providers.StatusVoided
+		result, err = provider.Void(ctx, *transaction.ProviderReference)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return providerError(err)
+	}
+	if result.Status != expected {
+		return ErrPaymentProviderRejected
+	}
+
+	return nil
+}
+
+func providerError(err error) error {
+	/*Map payment provider error to service error, unknown errors are wrapped as is.*/
+	switch {
+	case errors.Is(err, providers.ErrUnavailable):
+		return fmt.Errorf("%w: %s", ErrPaymentProviderUnavailable, err.Error())
+	case errors.Is(err, providers.ErrInvalidState), errors.Is(err, providers.ErrPaymentNotFound):
+		return fmt.Errorf("%w: %s", ErrPaymentProviderRejected, err.Error())
+	default:
+		return fmt.Errorf("payment provider failed: %w", err)
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/providers"
+)
+
+const (
+	// Refund Entity possible statuses
+	RefundPendingStatus string = "PENDING"
+	RefundSuccessStatus string = "SUCCESS"
+	RefundFailedStatus  string = "FAILED"
+)
+
+var (
+	// Error returned when transaction current status does not allow refunds
+	ErrRefundNotAllowed = NewError(
+		"refund_not_allowed", "Can not refund transaction with it's current status.", ErrTerminalStatus)
+	// Error returned when sum of transaction refunds exceeds transaction amount
+	ErrRefundAmountExceeded = NewError(
+		"refund_amount_exceeded", "Refund amount exceeds transaction amount available for refund.", ErrValidation)
+	// Error returned when refund does not exist or belongs to another transaction
+	ErrRefundNotFound = NewError("refund_not_found", "Refund not found.", ErrNotFound)
+	// Error returned when refund is created by principal which is not allowed to move money back (e.g. payer)
+	ErrRefundAccessDenied = NewError(
+		"refund_access_denied", "Refunds can be created only by transaction merchant or admin.", ErrForbidden)
+)
+
+// Transaction statuses which allow to create new refunds
+var RefundableTransactionStatuses []string = []string{
+	TransactionSuccessStatus,
+	TransactionPartiallyRefundedStatus,
+}
+
+type RefundRepository interface {
+	CreateRefund(refund *models.Refund) (*models.Refund, error)
+	UpdateRefundStatus(refund *models.Refund, status string) (*models.Refund, error)
+	GetRefundById(refundId int) (*models.Refund, error)
+	GetTransactionRefunds(transactionId int) ([]*models.Refund, error)
+}
+
+type TransactionGetter interface {
+	GetTransactionById(transactionId int) (*models.Transaction, error)
+}
+
+type RefundService struct {
+	repo         RefundRepository
+	transactions TransactionGetter
+	providers    *providers.Registry
+}
+
+func NewRefundService(
+	repo RefundRepository, transactions TransactionGetter, providers *providers.Registry,
+) *RefundService {
+	/*Refund service constructor function.*/
+	return &RefundService{repo: repo, transactions: transactions, providers: providers}
+}
+
+func (service *RefundService) Create(transactionId int, refundInput *models.RefundInput) (*models.Refund, error) {
+	/*
+		Create new refund of transaction (add new record to DB) and refund it's amount by payment provider.
+
+		Repository checks transaction status and available for refund amount atomically, so PENDING refund
+		reserves the amount before provider is requested and concurrent refunds can not exceed it.
+		Refund refused by provider is stored with FAILED status, otherwise it stays PENDING until provider proceeds it.
+	*/
+	var refund models.Refund = models.Refund{
+		TransactionId: transactionId,
+		Amount:        refundInput.Amount,
+		Status:        RefundPendingStatus,
+	}
+
+	created, err := service.repo.CreateRefund(&refund)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = service.refundPayment(created); err != nil {
+		if _, updateErr := service.repo.UpdateRefundStatus(created, RefundFailedStatus); updateErr != nil {
+			log.Printf("refund %d is not marked as failed: %s", created.Id, updateErr.Error())
+		}
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (service *RefundService) UpdateStatus(transactionId int, refundId int, status string, role string) (*models.Refund, error) {
+	/*Perform refund status update (allowed only for transitions declared in RefundStateMachine).*/
+	var refund *models.Refund
+	var err error
+
+	// retrieve refund from db to update
+	refund, err = service.GetById(transactionId, refundId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// check transition from current status is allowed for the role
+	if err = RefundStateMachine.Transition(refund.Status, status, role); err != nil {
+		return nil, err
+	}
+
+	// update refund status (amount is already refunded by provider on creation),
+	// parent transaction status is updated by repository
+	refund, err = service.repo.UpdateRefundStatus(refund, status)
+
+	if err != nil {
+		return nil, fmt.Errorf("service.repo.UpdateRefundStatus failed: %w", err)
+	}
+
+	return refund, nil
+}
+
+func (service *RefundService) refundPayment(refund *models.Refund) error {
+	/*Refund amount by payment provider, transactions not sent to provider are refunded outside of the service.*/
+	transaction, err := service.transactions.GetTransactionById(refund.TransactionId)
+	if err != nil {
+		return fmt.Errorf("service.transactions.GetTransactionById failed: %w", err)
+	}
+
+	provider, err := transactionProvider(service.providers, transaction)
+	if err != nil || provider == nil {
+		return err
+	}
+
+	if _, err = provider.Refund(context.Background(), *transaction.ProviderReference, refund.Amount); err != nil {
+		return providerError(err)
+	}
+
+	return nil
+}
+
+func (service *RefundService) GetById(transactionId int, refundId int) (*models.Refund, error) {
+	/*Retrieving refund data from DB by transaction PK and refund PK.*/
+	refund, err := service.repo.GetRefundById(refundId)
+
+	if err != nil {
+		return nil, fmt.Errorf("service.repo.GetRefundById failed: %w", err)
+	}
+
+	if refund.TransactionId != transactionId {
+		return nil, ErrRefundNotFound
+	}
+
+	return refund, nil
+}
+
+func (service *RefundService) GetTransaction(transactionId int) (*models.Transaction, error) {
+	/*Retrieve refunded transaction, used to check access to it's refunds.*/
+	return service.transactions.GetTransactionById(transactionId)
+}
+
+func (service *RefundService) ListByTransaction(transactionId int) ([]*models.Refund, error) {
+	/*Retrieve list of transaction refunds.*/
+	return service.repo.GetTransactionRefunds(transactionId)
+}
+
+func AuthorizeRefund(principal *auth.Principal, transaction *models.Transaction) error {
+	/*
+		Check principal is allowed to refund transaction: admin, principal with refunds scope
+		and merchant which created transaction are, transaction owner (payer) is not.
+	*/
+	if principal.IsAdmin() || principal.HasScope(auth.ScopeRefunds) {
+		return nil
+	}
+
+	if principal.MerchantId != "" && transaction.MerchantId != nil && *transaction.MerchantId == principal.MerchantId {
+		return nil
+	}
+
+	return ErrRefundAccessDenied
+}
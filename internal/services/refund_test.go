@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/providers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// in-memory RefundRepository implementation
+type refundRepositoryStub struct {
+	refunds map[int]*models.Refund
+}
+
+func (stub *refundRepositoryStub) CreateRefund(refund *models.Refund) (*models.Refund, error) {
+	refund.Id = len(stub.refunds) + 1
+	stub.refunds[refund.Id] = refund
+	return refund, nil
+}
+
+func (stub *refundRepositoryStub) UpdateRefundStatus(refund *models.Refund, status string) (*models.Refund, error) {
+	updated := *refund
+	updated.Status = status
+	stub.refunds[refund.Id] = &updated
+	return &updated, nil
+}
+
+func (stub *refundRepositoryStub) GetRefundById(refundId int) (*models.Refund, error) {
+	refund, ok := stub.refunds[refundId]
+	if !ok {
+		return nil, ErrRefundNotFound
+	}
+	return refund, nil
+}
+
+func (stub *refundRepositoryStub) GetTransactionRefunds(transactionId int) ([]*models.Refund, error) {
+	var refunds []*models.Refund
+	for _, refund := range stub.refunds {
+		if refund.TransactionId == transactionId {
+			refunds = append(refunds, refund)
+		}
+	}
+	return refunds, nil
+}
+
+func TestRefundService_CreateProvider(t *testing.T) {
+	// Arrange
+	fake := providers.NewFakeProvider("fake")
+	name := fake.Name()
+	payment, _ := fake.Authorize(context.Background(), &providers.Payment{Amount: 1500, Currency: "EUR"})
+	fake.Capture(context.Background(), payment.Reference, 1500)
+	unknownReference := "fake_unknown"
+
+	transactions := &transactionRepositoryStub{transactions: map[int]*models.Transaction{
+		1: {Id: 1, Amount: 1500, Status: TransactionSuccessStatus, Provider: &name, ProviderReference: &payment.Reference},
+		2: {Id: 2, Amount: 1500, Status: TransactionSuccessStatus},
+		3: {Id: 3, Amount: 1500, Status: TransactionSuccessStatus, Provider: &name, ProviderReference: &unknownReference},
+	}}
+	repo := &refundRepositoryStub{refunds: map[int]*models.Refund{}}
+	service := NewRefundService(repo, transactions, providers.NewRegistry(fake))
+
+	// Act
+	// amount is refunded by provider on creation, refund waits for provider to proceed it
+	refund, err := service.Create(1, &models.RefundInput{Amount: 500})
+	assert.NoError(t, err)
+	assert.Equal(t, RefundPendingStatus, refund.Status)
+
+	// transactions not sent to provider are refunded outside of the service
+	refund, err = service.Create(2, &models.RefundInput{Amount: 1500})
+	assert.NoError(t, err)
+	assert.Equal(t, RefundPendingStatus, refund.Status)
+
+	// refund refused by provider is stored as failed
+	_, err = service.Create(3, &models.RefundInput{Amount: 500})
+	assert.True(t, errors.Is(err, ErrPaymentProviderRejected), err)
+
+	// Assert
+	assert.Equal(t, RefundFailedStatus, repo.refunds[3].Status)
+
+	result, _ := fake.QueryStatus(context.Background(), payment.Reference)
+	assert.Equal(t, int64(500), result.Refunded)
+}
+
+func TestRefundService_UpdateStatusProvider(t *testing.T) {
+	// Arrange
+	fake := providers.NewFakeProvider("fake")
+	name := fake.Name()
+	payment, _ := fake.Authorize(context.Background(), &providers.Payment{Amount: 1500, Currency: "EUR"})
+	fake.Capture(context.Background(), payment.Reference, 1500)
+
+	transactions := &transactionRepositoryStub{transactions: map[int]*models.Transaction{
+		1: {Id: 1, Amount: 1500, Status: TransactionSuccessStatus, Provider: &name, ProviderReference: &payment.Reference},
+	}}
+	repo := &refundRepositoryStub{refunds: map[int]*models.Refund{}}
+	service := NewRefundService(repo, transactions, providers.NewRegistry(fake))
+	created, err := service.Create(1, &models.RefundInput{Amount: 500})
+	assert.NoError(t, err)
+
+	// Act
+	// repeated status updates do not refund the amount again
+	refund, err := service.UpdateStatus(1, created.Id, RefundSuccessStatus, RoleProvider)
+	assert.NoError(t, err)
+	assert.Equal(t, RefundSuccessStatus, refund.Status)
+
+	_, err = service.UpdateStatus(1, created.Id, RefundSuccessStatus, RoleProvider)
+	assert.Error(t, err)
+
+	// Assert
+	result, _ := fake.QueryStatus(context.Background(), payment.Reference)
+	assert.Equal(t, int64(500), result.Refunded)
+}
+
+func TestAuthorizeRefund(t *testing.T) {
+	merchantId := "merchant-1"
+	transaction := &models.Transaction{Id: 1, UserId: 1, MerchantId: &merchantId}
+
+	testTable := []struct {
+		name        string
+		principal   *auth.Principal
+		transaction *models.Transaction
+		expectedErr error
+	}{
+		{name: "Test admin", principal: &auth.Principal{Subject: "admin", Scopes: []string{auth.ScopeAdmin}}, transaction: transaction},
+		{name: "Test refunds scope", principal: &auth.Principal{Subject: "support", Scopes: []string{auth.ScopeRefunds}}, transaction: transaction},
+		{name: "Test transaction merchant", principal: &auth.Principal{Subject: "client-1", MerchantId: merchantId}, transaction: transaction},
+		{
+			name:        "Test transaction owner",
+			principal:   &auth.Principal{Subject: "1"},
+			transaction: transaction,
+			expectedErr: ErrRefundAccessDenied,
+		},
+		{
+			name:        "Test another merchant",
+			principal:   &auth.Principal{Subject: "client-2", MerchantId: "merchant-2"},
+			transaction: transaction,
+			expectedErr: ErrRefundAccessDenied,
+		},
+		{
+			name:        "Test merchant of transaction without merchant",
+			principal:   &auth.Principal{Subject: "client-1", MerchantId: merchantId},
+			transaction: &models.Transaction{Id: 2, UserId: 1},
+			expectedErr: ErrRefundAccessDenied,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := AuthorizeRefund(testCase.principal, testCase.transaction)
+
+			if testCase.expectedErr != nil {
+				assert.True(t, errors.Is(err, testCase.expectedErr), err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import "fmt"
+
+const (
+	// Roles which may perform status transitions
+	RoleUser     string = "user"
+	RoleAdmin    string = "admin"
+	RoleSystem   string = "system"
+	RoleProvider string = "payment-provider"
+)
+
+// Allowed status transition and list of roles which may perform it
+type Transition struct {
+	From  string
+	To    string
+	Roles []string
+}
+
+// Error returned for status transition which is not allowed by state machine,
+// kind is ErrTerminalStatus (current status is terminal), ErrValidation (transition is not declared)
+// or ErrForbidden (role is not allowed to perform declared transition)
+type TransitionError struct {
+	From string
+	To   string
+	Role string
+	Kind error
+}
+
+// Declaration of entity statuses and allowed transitions between them
+// statuses without outgoing transitions are terminal
+type StateMachine struct {
+	statuses    []string
+	transitions map[string]map[string][]string
+}
+
+// Transaction entity statuses and transitions
+var TransactionStateMachine *StateMachine = NewStateMachine(
+	[]string{
+		TransactionNewStatus,
+		TransactionErrorStatus,
+		TransactionFailedStatus,
+		TransactionSuccessStatus,
+		TransactionCanceledStatus,
+		TransactionPartiallyRefundedStatus,
+		TransactionRefundedStatus,
+		TransactionExpiredStatus,
+	},
+	[]Transition{
+		{From: TransactionNewStatus, To: TransactionSuccessStatus, Roles: []string{RoleProvider}},
+		{From: TransactionNewStatus, To: TransactionFailedStatus, Roles: []string{RoleProvider}},
+		{From: TransactionNewStatus, To: TransactionCanceledStatus, Roles: []string{RoleUser, RoleAdmin}},
+		// assigned by sweeper when transaction time to live is over
+		{From: TransactionNewStatus, To: TransactionExpiredStatus, Roles: []string{RoleSystem}},
+		// assigned on successful refunds
+		{From: TransactionSuccessStatus, To: TransactionPartiallyRefundedStatus, Roles: []string{RoleSystem}},
+		{From: TransactionSuccessStatus, To: TransactionRefundedStatus, Roles: []string{RoleSystem}},
+		{From: TransactionPartiallyRefundedStatus, To: TransactionRefundedStatus, Roles: []string{RoleSystem}},
+	},
+)
+
+// Refund entity statuses and transitions
+var RefundStateMachine *StateMachine = NewStateMachine(
+	[]string{
+		RefundPendingStatus,
+		RefundSuccessStatus,
+		RefundFailedStatus,
+	},
+	[]Transition{
+		{From: RefundPendingStatus, To: RefundSuccessStatus, Roles: []string{RoleProvider}},
+		// refund refused by payment provider on creation is failed by the service
+		{From: RefundPendingStatus, To: RefundFailedStatus, Roles: []string{RoleProvider, RoleSystem}},
+	},
+)
+
+func NewStateMachine(statuses []string, transitions []Transition) *StateMachine {
+	/*State machine constructor function.*/
+	var stateMachine *StateMachine = &StateMachine{
+		statuses:    statuses,
+		transitions: make(map[string]map[string][]string, len(statuses)),
+	}
+
+	for _, status := range statuses {
+		stateMachine.transitions[status] = make(map[string][]string)
+	}
+
+	for _, transition := range transitions {
+		if _, ok := stateMachine.transitions[transition.From]; !ok {
+			panic(fmt.Sprintf("StateMachine: undeclared status %q", transition.From))
+		}
+		if _, ok := stateMachine.transitions[transition.To]; !ok {
+			panic(fmt.Sprintf("StateMachine: undeclared status %q", transition.To))
+		}
+		stateMachine.transitions[transition.From][transition.To] = transition.Roles
+	}
+
+	return stateMachine
+}
+
+func (stateMachine *StateMachine) Statuses() []string {
+	/*Return list of all declared statuses.*/
+	return stateMachine.statuses
+}
+
+func (stateMachine *StateMachine) IsTerminal(status string) bool {
+	/*Check status has no outgoing transitions.*/
+	return len(stateMachine.transitions[status]) == 0
+}
+
+func (stateMachine *StateMachine) Transition(from string, to string, role string) error {
+	/*Check transition from one status to another is allowed for the role, return *TransitionError otherwise.*/
+	var transitionError *TransitionError = &TransitionError{From: from, To: to, Role: role, Kind: ErrValidation}
+
+	if stateMachine.IsTerminal(from) {
+		transitionError.Kind = ErrTerminalStatus
+		return transitionError
+	}
+
+	roles, ok := stateMachine.transitions[from][to]
+	if !ok {
+		return transitionError
+	}
+
+	for _, allowedRole := range roles {
+		if allowedRole == role {
+			return nil
+		}
+	}
+
+	transitionError.Kind = ErrForbidden
+	return transitionError
+}
+
+func (err *TransitionError) Error() string {
+	/*Return text representation of transition error.*/
+	switch err.Kind {
+	case ErrTerminalStatus:
+		return fmt.Sprintf("Can not update entity with it's current status %s.", err.From)
+	case ErrForbidden:
+		return fmt.Sprintf("Status transition from %s to %s is not allowed for %s.", err.From, err.To, err.Role)
+	default:
+		return fmt.Sprintf("Status transition from %s to %s is not allowed.", err.From, err.To)
+	}
+}
+
+func (err *TransitionError) Unwrap() error {
+	/*Return kind of the error.*/
+	return err.Kind
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateMachine_Transition(t *testing.T) {
+	// Arrange
+	testTable := []struct {
+		name         string
+		from         string
+		to           string
+		role         string
+		expectedKind error
+	}{
+		{
+			name: "Test proceed new transaction (ok)",
+			from: TransactionNewStatus,
+			to:   TransactionSuccessStatus,
+			role: RoleProvider,
+		},
+		{
+			name: "Test cancel new transaction (ok)",
+			from: TransactionNewStatus,
+			to:   TransactionCanceledStatus,
+			role: RoleUser,
+		},
+		{
+			name: "Test expire new transaction (ok)",
+			from: TransactionNewStatus,
+			to:   TransactionExpiredStatus,
+			role: RoleSystem,
+		},
+		{
+			name:         "Test expire new transaction (forbidden role)",
+			from:         TransactionNewStatus,
+			to:           TransactionExpiredStatus,
+			role:         RoleAdmin,
+			expectedKind: ErrForbidden,
+		},
+		{
+			name:         "Test proceed new transaction (forbidden role)",
+			from:         TransactionNewStatus,
+			to:           TransactionSuccessStatus,
+			role:         RoleUser,
+			expectedKind: ErrForbidden,
+		},
+		{
+			name:         "Test undeclared transition",
+			from:         TransactionNewStatus,
+			to:           TransactionErrorStatus,
+			role:         RoleProvider,
+			expectedKind: ErrValidation,
+		},
+		{
+			name:         "Test refund successful transaction (forbidden role)",
+			from:         TransactionSuccessStatus,
+			to:           TransactionRefundedStatus,
+			role:         RoleUser,
+			expectedKind: ErrForbidden,
+		},
+		{
+			name:         "Test terminal status",
+			from:         TransactionFailedStatus,
+			to:           TransactionCanceledStatus,
+			role:         RoleUser,
+			expectedKind: ErrTerminalStatus,
+		},
+		{
+			name:         "Test undeclared transition from not terminal status",
+			from:         TransactionSuccessStatus,
+			to:           TransactionCanceledStatus,
+			role:         RoleUser,
+			expectedKind: ErrValidation,
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := TransactionStateMachine.Transition(testCase.from, testCase.to, testCase.role)
+
+			// Assert
+			if testCase.expectedKind == nil {
+				assert.NoError(t, err)
+				return
+			}
+			transitionError, ok := err.(*TransitionError)
+			assert.True(t, ok)
+			assert.Equal(t, testCase.expectedKind, transitionError.Kind)
+			assert.True(t, errors.Is(err, testCase.expectedKind), err)
+		})
+	}
+}
+
+func TestStateMachine_IsTerminal(t *testing.T) {
+	assert.False(t, TransactionStateMachine.IsTerminal(TransactionNewStatus))
+	assert.True(t, TransactionStateMachine.IsTerminal(TransactionErrorStatus))
+	assert.True(t, TransactionStateMachine.IsTerminal(TransactionFailedStatus))
+	assert.False(t, TransactionStateMachine.IsTerminal(TransactionSuccessStatus))
+	assert.False(t, TransactionStateMachine.IsTerminal(TransactionPartiallyRefundedStatus))
+	assert.True(t, TransactionStateMachine.IsTerminal(TransactionRefundedStatus))
+	assert.True(t, TransactionStateMachine.IsTerminal(TransactionCanceledStatus))
+	assert.True(t, TransactionStateMachine.IsTerminal(TransactionExpiredStatus))
+}
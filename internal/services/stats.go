@@ -0,0 +1,112 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/money"
+)
+
+// Max number of buckets in requested statistics window
+const maxStatsBuckets int = 1000
+
+// Error returned when statistics window contains too many buckets of requested interval
+var ErrStatsWindowTooLarge = NewError(
+	"stats_window_too_large", "Statistics window contains too many buckets, use longer interval.", ErrValidation)
+
+type StatsRepository interface {
+	GetTransactionStats(filter *models.TransactionStatsFilter) ([]*models.TransactionStatsRow, error)
+}
+
+type StatsService struct {
+	repo StatsRepository
+}
+
+func NewStatsService(repo StatsRepository) *StatsService {
+	/*Statistics service constructor function.*/
+	return &StatsService{repo: repo}
+}
+
+func (service *StatsService) TransactionStats(filter *models.TransactionStatsFilter) (*models.TransactionStats, error) {
+	/*Retrieve statistics of transactions created within the window, aggregated by repository per bucket.*/
+	var stats *models.TransactionStats = &models.TransactionStats{
+		From:     filter.From,
+		To:       filter.To,
+		Interval: filter.Interval,
+		Buckets:  make([]*models.TransactionStatsBucket, 0),
+		Total:    models.TransactionStatsSummary{Totals: make([]*models.TransactionStatsTotal, 0)},
+	}
+	var bucket *models.TransactionStatsBucket
+
+	if statsBucketsCount(filter) > maxStatsBuckets {
+		return nil, ErrStatsWindowTooLarge
+	}
+
+	rows, err := service.repo.GetTransactionStats(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		switch {
+		case row.Bucket == nil && row.Status == nil:
+			// whole window row
+			stats.Total = statsSummary(row, stats.Total.Totals)
+		case row.Bucket == nil:
+			stats.Total.Totals = append(stats.Total.Totals, statsTotal(row))
+		case row.Status == nil:
+			// bucket row goes before rows of it's statuses and currencies
+			bucket = &models.TransactionStatsBucket{
+				Start:                   row.Bucket.UTC(),
+				TransactionStatsSummary: statsSummary(row, make([]*models.TransactionStatsTotal, 0)),
+			}
+			stats.Buckets = append(stats.Buckets, bucket)
+		case bucket != nil:
+			bucket.Totals = append(bucket.Totals, statsTotal(row))
+		}
+	}
+
+	return stats, nil
+}
+
+func statsBucketsCount(filter *models.TransactionStatsFilter) int {
+	/*Return number of buckets of filter interval in filter window (including partial ones).*/
+	var window time.Duration = filter.To.Sub(filter.From)
+
+	switch filter.Interval {
+	case "hour":
+		return int(window/time.Hour) + 1
+	case "month":
+		from, to := filter.From.UTC(), filter.To.UTC()
+		return (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	default:
+		return int(window/(24*time.Hour)) + 1
+	}
+}
+
+func statsSummary(row *models.TransactionStatsRow, totals []*models.TransactionStatsTotal) models.TransactionStatsSummary {
+	/*Build summary from aggregated row, success rate is share of successful outcomes among processed transactions.*/
+	var summary models.TransactionStatsSummary = models.TransactionStatsSummary{
+		Count:                row.Count,
+		AvgProcessingSeconds: row.AvgProcessingSeconds,
+		Totals:               totals,
+	}
+
+	if row.Processed > 0 {
+		rate := float64(row.Successful) / float64(row.Processed)
+		summary.SuccessRate = &rate
+	}
+
+	return summary
+}
+
+func statsTotal(row *models.TransactionStatsRow) *models.TransactionStatsTotal {
+	/*Build status and currency total from aggregated row.*/
+	return &models.TransactionStatsTotal{
+		Status:          *row.Status,
+		Currency:        *row.Currency,
+		Count:           row.Count,
+		Amount:          row.Amount,
+		AmountFormatted: money.Format(row.Amount, *row.Currency),
+	}
+}
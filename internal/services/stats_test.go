@@ -0,0 +1,114 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statsRepositoryStub struct {
+	rows []*models.TransactionStatsRow
+}
+
+func (repo *statsRepositoryStub) GetTransactionStats(filter *models.TransactionStatsFilter) ([]*models.TransactionStatsRow, error) {
+	return repo.rows, nil
+}
+
+func TestStatsService_TransactionStats(t *testing.T) {
+	// Arrange
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	bucket := time.Date(2022, 6, 12, 0, 0, 0, 0, time.UTC)
+	newStatus, successStatus, eur := TransactionNewStatus, TransactionSuccessStatus, "EUR"
+	avg := 30.0
+	rate := 0.5
+
+	repo := &statsRepositoryStub{rows: []*models.TransactionStatsRow{
+		{Bucket: &bucket, Count: 3, Successful: 1, Processed: 2, AvgProcessingSeconds: &avg},
+		{Bucket: &bucket, Status: &newStatus, Currency: &eur, Count: 1, Amount: 1000},
+		{Bucket: &bucket, Status: &successStatus, Currency: &eur, Count: 2, Amount: 2550},
+		{Count: 3, Successful: 1, Processed: 2, AvgProcessingSeconds: &avg},
+		{Status: &newStatus, Currency: &eur, Count: 1, Amount: 1000},
+		{Status: &successStatus, Currency: &eur, Count: 2, Amount: 2550},
+	}}
+	totals := []*models.TransactionStatsTotal{
+		{Status: newStatus, Currency: eur, Count: 1, Amount: 1000, AmountFormatted: "10.00"},
+		{Status: successStatus, Currency: eur, Count: 2, Amount: 2550, AmountFormatted: "25.50"},
+	}
+	summary := models.TransactionStatsSummary{Count: 3, SuccessRate: &rate, AvgProcessingSeconds: &avg, Totals: totals}
+
+	// Act
+	stats, err := NewStatsService(repo).TransactionStats(
+		&models.TransactionStatsFilter{From: from, To: from.AddDate(0, 1, 0), Interval: "day"},
+	)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &models.TransactionStats{
+		From:     from,
+		To:       from.AddDate(0, 1, 0),
+		Interval: "day",
+		Buckets:  []*models.TransactionStatsBucket{{Start: bucket, TransactionStatsSummary: summary}},
+		Total:    summary,
+	}, stats)
+}
+
+func TestStatsService_TransactionStatsEmpty(t *testing.T) {
+	// Arrange
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := &statsRepositoryStub{rows: []*models.TransactionStatsRow{{Count: 0}}}
+
+	// Act
+	stats, err := NewStatsService(repo).TransactionStats(
+		&models.TransactionStatsFilter{From: from, To: from.Add(time.Hour), Interval: "hour"},
+	)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, stats.Buckets)
+	assert.Equal(t, int64(0), stats.Total.Count)
+	assert.Nil(t, stats.Total.SuccessRate)
+	assert.Empty(t, stats.Total.Totals)
+}
+
+func TestStatsService_TransactionStatsWindowTooLarge(t *testing.T) {
+	// Arrange
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		filter        *models.TransactionStatsFilter
+		expectedError error
+	}{
+		{
+			name:   "Test hourly stats for a month",
+			filter: &models.TransactionStatsFilter{From: from, To: from.AddDate(0, 1, 0), Interval: "hour"},
+		},
+		{
+			name:          "Test hourly stats for a year",
+			filter:        &models.TransactionStatsFilter{From: from, To: from.AddDate(1, 0, 0), Interval: "hour"},
+			expectedError: ErrStatsWindowTooLarge,
+		},
+		{
+			name:          "Test daily stats for ten years",
+			filter:        &models.TransactionStatsFilter{From: from, To: from.AddDate(10, 0, 0), Interval: "day"},
+			expectedError: ErrStatsWindowTooLarge,
+		},
+		{
+			name:   "Test monthly stats for ten years",
+			filter: &models.TransactionStatsFilter{From: from, To: from.AddDate(10, 0, 0), Interval: "month"},
+		},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewStatsService(&statsRepositoryStub{}).TransactionStats(testCase.filter)
+
+			// Assert
+			assert.Equal(t, testCase.expectedError, err)
+		})
+	}
+}
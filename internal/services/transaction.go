@@ -1,11 +1,17 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"log"
+	"time"
 
+	"github.com/Pythonyan3/payment-service/internal/auth"
 	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/money"
+	"github.com/Pythonyan3/payment-service/internal/providers"
+	"github.com/Pythonyan3/payment-service/internal/risk"
 )
 
 const (
@@ -15,47 +21,171 @@ const (
 	TransactionFailedStatus   string = "FAILED"
 	TransactionSuccessStatus  string = "SUCCESS"
 	TransactionCanceledStatus string = "CANCELED"
-
-	// Error message for updating transactions with terminal status
-	TerminalStatusErrorMessage string = "TransactionService: cannot update transaction with it's current status."
+	// Assigned by sweeper to NEW transaction which expiration time has come
+	TransactionExpiredStatus string = "EXPIRED"
+	// Statuses assigned to SUCCESS transaction by refunds
+	TransactionPartiallyRefundedStatus string = "PARTIALLY_REFUNDED"
+	TransactionRefundedStatus          string = "REFUNDED"
 )
 
 type TransactionRepository interface {
-	CreateTransaction(transaction *models.Transaction) (*models.Transaction, error)
-	UpdateTransactionStatus(transaction *models.Transaction, status string) (*models.Transaction, error)
+	CreateTransaction(transaction *models.Transaction, decision *models.RiskDecision) (*models.Transaction, error)
+	UpdateTransactionStatus(transaction *models.Transaction, status string, forward TransactionForward) (*models.Transaction, error)
 	GetTransactionById(transactionId int) (*models.Transaction, error)
+	SearchTransactions(filter *models.TransactionSearchFilter) ([]*models.Transaction, error)
+	ExpireTransactions(limit int, forward TransactionForward) ([]*models.Transaction, error)
 }
 
+// Operation performed by repository while transaction row is locked before it's status is updated,
+// status is not updated if it fails
+type TransactionForward func(transaction *models.Transaction) error
+
 type TransactionService struct {
-	repo TransactionRepository
+	repo      TransactionRepository
+	users     UserGetter
+	providers *providers.Registry
+	risk      *risk.Engine
+	// default time to live of NEW transaction
+	ttl time.Duration
+	now func() time.Time
 }
 
-func NewTransactionService(repo TransactionRepository) *TransactionService {
-	/*Transaction service constructor function.*/
-	return &TransactionService{repo: repo}
+func NewTransactionService(
+	repo TransactionRepository, users UserGetter, providers *providers.Registry, risk *risk.Engine, ttl time.Duration,
+) *TransactionService {
+	/*Transaction service constructor function, NEW transactions expire after ttl unless it is set by the client.*/
+	return &TransactionService{repo: repo, users: users, providers: providers, risk: risk, ttl: ttl, now: time.Now}
 }
 
 func (service *TransactionService) Create(transactionInput *models.TransactionInput) (*models.Transaction, error) {
-	/*Create new transaction (add new record to DB).*/
+	/*
+		Create new transaction (add new record to DB), transaction email is taken from the user record.
+
+		Transaction blocked by risk rules is created with ERROR status and is not sent to payment provider,
+		otherwise amount is authorized by provider selected by merchant and currency,
+		transaction declined by provider is created with FAILED status.
+		NEW transaction is expired by sweeper after it's time to live.
+	*/
+	var provider providers.PaymentProvider
+	var decision *models.RiskDecision
+	var created *models.Transaction
+	amount, err := inputAmount(transactionInput)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := service.users.GetUserById(transactionInput.UserId)
+	if err != nil {
+		return nil, err
+	}
+
 	var transaction models.Transaction = models.Transaction{
-		UserId:    transactionInput.UserId,
-		Amount:    transactionInput.Amount,
-		Currency:  transactionInput.Currency,
-		UserEmail: transactionInput.UserEmail,
+		UserId:    user.Id,
+		Amount:    amount.Minor,
+		Currency:  amount.Currency.Code,
+		UserEmail: user.Email,
+	}
+	if transactionInput.MerchantId != "" {
+		transaction.MerchantId = &transactionInput.MerchantId
 	}
 
-	// 1/5 of all trasnactions should be created with 'Error' status
-	if rand.Intn(100) > 80 {
+	// evaluate risk rules, decision is stored along with the transaction
+	decision, err = service.risk.Evaluate(&transaction)
+	if err != nil {
+		return nil, fmt.Errorf("service.risk.Evaluate failed: %w", err)
+	}
+
+	if decision.Outcome == risk.OutcomeBlock {
 		transaction.Status = TransactionErrorStatus
 	} else {
-		transaction.Status = TransactionNewStatus
+		provider = service.providers.Select(transactionInput.MerchantId, transaction.Currency)
+		if err = authorizeTransaction(provider, &transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	if transaction.Status == TransactionNewStatus {
+		var expiresAt time.Time = service.now().Add(service.ttl)
+		if transactionInput.TTLSeconds > 0 {
+			expiresAt = service.now().Add(time.Duration(transactionInput.TTLSeconds) * time.Second)
+		}
+		transaction.ExpiresAt = &expiresAt
 	}
 
-	return service.repo.CreateTransaction(&transaction)
+	created, err = service.repo.CreateTransaction(&transaction, decision)
+	if err != nil && transaction.Status == TransactionNewStatus {
+		// funds are not held by the service (e.g. wallet funds are not enough), release provider authorization,
+		// authorization which is not voided is left to be released by the provider and has to be tracked by logs
+		if _, voidErr := provider.Void(context.Background(), *transaction.ProviderReference); voidErr != nil {
+			log.Printf(
+				"TransactionService: void of not created transaction at provider %q (reference %q) failed: %s",
+				provider.Name(), *transaction.ProviderReference, voidErr.Error())
+		}
+	}
+
+	return created, err
 }
 
-func (service *TransactionService) UpdateStatus(transactionId int, status string) (*models.Transaction, error) {
-	/*Perform transaction status update (allowed only for transactions with status 'NEW').*/
+func authorizeTransaction(provider providers.PaymentProvider, transaction *models.Transaction) error {
+	/*Authorize transaction amount by provider, set transaction status and provider reference.*/
+	var name string = provider.Name()
+
+	result, err := provider.Authorize(context.Background(), &providers.Payment{
+		Amount:    transaction.Amount,
+		Currency:  transaction.Currency,
+		UserId:    transaction.UserId,
+		UserEmail: transaction.UserEmail,
+	})
+	if err != nil {
+		return providerError(err)
+	}
+
+	transaction.Provider = &name
+	transaction.ProviderReference = &result.Reference
+	transaction.Status = TransactionNewStatus
+	if result.Status == providers.StatusDeclined {
+		transaction.Status = TransactionFailedStatus
+	}
+
+	return nil
+}
+
+func inputAmount(transactionInput *models.TransactionInput) (money.Amount, error) {
+	/*Build amount from minor units or decimal string, amount must be positive and fit currency precision.*/
+	var amount money.Amount
+	var field string = "amount"
+	var err error
+
+	if transactionInput.AmountDecimal != "" {
+		field = "amount_decimal"
+		amount, err = money.ParseAmount(transactionInput.AmountDecimal, transactionInput.Currency)
+	} else {
+		amount, err = money.NewAmount(transactionInput.Amount, transactionInput.Currency)
+	}
+
+	switch {
+	case err == nil:
+		return amount, nil
+	case errors.Is(err, money.ErrUnknownCurrency):
+		return amount, &ValidationError{Fields: []FieldError{
+			{Field: "currency", Tag: "currency", Message: "must be a supported ISO 4217 currency code"},
+		}}
+	case errors.Is(err, money.ErrPrecision):
+		currency, _ := money.LookupCurrency(transactionInput.Currency)
+		return amount, &ValidationError{Fields: []FieldError{{
+			Field:   field,
+			Tag:     "precision",
+			Message: fmt.Sprintf("must have at most %d decimal places for %s", currency.Exponent, currency.Code),
+		}}}
+	case errors.Is(err, money.ErrNotPositive):
+		return amount, &ValidationError{Fields: []FieldError{{Field: field, Tag: "gt", Message: "must be greater than 0"}}}
+	default:
+		return amount, &ValidationError{Fields: []FieldError{{Field: field, Tag: "decimal", Message: err.Error()}}}
+	}
+}
+
+func (service *TransactionService) UpdateStatus(transactionId int, status string, role string) (*models.Transaction, error) {
+	/*Perform transaction status update (allowed only for transitions declared in TransactionStateMachine).*/
 	var transaction *models.Transaction
 	var err error
 
@@ -66,13 +196,167 @@ func (service *TransactionService) UpdateStatus(transactionId int, status string
 		return nil, fmt.Errorf("service.repo.GetTransactionById failed: %w", err)
 	}
 
-	// check transaction current status and return error if it has not 'NEW' status
-	if transaction.Status != TransactionNewStatus {
-		return nil, errors.New(TerminalStatusErrorMessage)
+	return service.updateStatus(transaction, status, role)
+}
+
+func (service *TransactionService) Cancel(transactionId int, principal *auth.Principal) (*models.Transaction, error) {
+	/*Cancel transaction on behalf of it's owner or admin.*/
+	var transaction *models.Transaction
+	var role string = RoleUser
+	var err error
+
+	// retrieve transaction from db to cancel
+	transaction, err = service.repo.GetTransactionById(transactionId)
+
+	if err != nil {
+		return nil, fmt.Errorf("service.repo.GetTransactionById failed: %w", err)
+	}
+
+	if err = AuthorizeTransaction(principal, transaction); err != nil {
+		return nil, err
+	}
+
+	if principal.IsAdmin() {
+		role = RoleAdmin
+	}
+
+	return service.updateStatus(transaction, TransactionCanceledStatus, role)
+}
+
+func (service *TransactionService) GetById(transactionId int) (*models.Transaction, error) {
+	/*Retriving transaction data from DB by transaction PK.*/
+	return service.repo.GetTransactionById(transactionId)
+}
+
+func (service *TransactionService) Search(filter *models.TransactionSearchFilter) (*models.TransactionPage, error) {
+	/*
+		Retrieve page of all transactions matching filter params in requested order.
+
+		Cursor is accepted only with the same sorting it was issued for.
+	*/
+	var err error
+	var transactions []*models.Transaction
+	var pageFilter models.TransactionSearchFilter = *filter
+	var page *models.TransactionPage
+
+	if filter.Cursor != "" {
+		pageFilter.After, err = models.DecodeTransactionSearchCursor(filter.Cursor)
+		if err != nil || pageFilter.After.Sort != filter.Sort || pageFilter.After.Order != filter.Order {
+			return nil, ErrInvalidCursor
+		}
+	}
+	// request one extra row to find out whether the next page exists
+	pageFilter.Limit = filter.Limit + 1
+
+	if transactions, err = service.repo.SearchTransactions(&pageFilter); err != nil {
+		return nil, err
+	}
+
+	page = &models.TransactionPage{Items: transactions, Limit: filter.Limit}
+	if len(transactions) > filter.Limit {
+		page.Items = transactions[:filter.Limit]
+		last := page.Items[filter.Limit-1]
+		page.NextCursor = (&models.TransactionSearchCursor{
+			Sort:      filter.Sort,
+			Order:     filter.Order,
+			CreatedAt: last.CreatedAt,
+			UpdatedAt: last.UpdatedAt,
+			Amount:    last.Amount,
+			Id:        last.Id,
+		}).Encode()
+	}
+
+	return page, nil
+}
+
+func (service *TransactionService) Sync(transactionId int) (*models.Transaction, error) {
+	/*
+		Synchronize NEW transaction status with payment state reported by it's provider,
+		used when provider operation succeeded but transaction status update failed.
+	*/
+	var transaction *models.Transaction
+	var result *providers.Result
+	var status string
+	var err error
+
+	transaction, err = service.repo.GetTransactionById(transactionId)
+	if err != nil {
+		return nil, fmt.Errorf("service.repo.GetTransactionById failed: %w", err)
+	}
+
+	provider, err := transactionProvider(service.providers, transaction)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, ErrNoPaymentProvider
+	}
+
+	if result, err = provider.QueryStatus(context.Background(), *transaction.ProviderReference); err != nil {
+		return nil, providerError(err)
+	}
+
+	switch result.Status {
+	case providers.StatusCaptured, providers.StatusRefunded:
+		status = TransactionSuccessStatus
+	case providers.StatusVoided, providers.StatusDeclined:
+		status = TransactionFailedStatus
+	}
+	if transaction.Status != TransactionNewStatus || status == "" {
+		// nothing to synchronize, only NEW transactions are waiting for provider outcome
+		return transaction, nil
+	}
+
+	// provider has already performed the operation, only transaction status is updated
+	if err = TransactionStateMachine.Transition(transaction.Status, status, RoleProvider); err != nil {
+		return nil, err
+	}
+
+	transaction, err = service.repo.UpdateTransactionStatus(transaction, status, nil)
+	if err != nil {
+		return nil, fmt.Errorf("service.repo.UpdateTransactionStatus failed: %w", err)
+	}
+
+	return transaction, nil
+}
+
+func (service *TransactionService) Expire(limit int) (int, error) {
+	/*
+		Move up to limit NEW transactions which expiration time has come to EXPIRED status, return number of them.
+
+		Payments of expired transactions are voided by providers while transaction rows are locked (before status update),
+		void failures are only logged since provider authorization expires by itself as well.
+	*/
+	var void TransactionForward = service.forward(TransactionExpiredStatus)
+
+	transactions, err := service.repo.ExpireTransactions(limit, func(transaction *models.Transaction) error {
+		if err := void(transaction); err != nil {
+			log.Printf("TransactionService: void of expired transaction %d failed: %s", transaction.Id, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("service.repo.ExpireTransactions failed: %w", err)
+	}
+
+	return len(transactions), nil
+}
+
+func (service *TransactionService) updateStatus(transaction *models.Transaction, status string, role string) (*models.Transaction, error) {
+	/*
+		Check status transition is allowed for the role and update transaction status,
+		payment of transaction is captured or voided by provider while transaction row is locked (before the update),
+		so provider operation is not performed for transaction which status was changed concurrently.
+	*/
+	var err error
+
+	// check transition from current status is allowed for the role
+	if err = TransactionStateMachine.Transition(transaction.Status, status, role); err != nil {
+		return nil, err
 	}
 
 	// update transaction status
-	transaction, err = service.repo.UpdateTransactionStatus(transaction, status)
+	transaction, err = service.repo.UpdateTransactionStatus(transaction, status, service.forward(status))
 
 	if err != nil {
 		return nil, fmt.Errorf("service.repo.UpdateTransactionStatus failed: %w", err)
@@ -81,7 +365,41 @@ func (service *TransactionService) UpdateStatus(transactionId int, status string
 	return transaction, nil
 }
 
-func (service *TransactionService) GetById(transactionId int) (*models.Transaction, error) {
-	/*Retriving transaction data from DB by transaction PK.*/
-	return service.repo.GetTransactionById(transactionId)
+func (service *TransactionService) forward(status string) TransactionForward {
+	/*Return provider operation corresponding to transaction new status, transactions without provider are skipped.*/
+	return func(transaction *models.Transaction) error {
+		provider, err := transactionProvider(service.providers, transaction)
+		if err != nil || provider == nil {
+			return err
+		}
+
+		return forwardTransactionStatus(context.Background(), provider, transaction, status)
+	}
+}
+
+func AuthorizeTransaction(principal *auth.Principal, transaction *models.Transaction) error {
+	/*Check principal is allowed to access transaction: only owner and admin are.*/
+	if AuthorizeUser(principal, transaction.UserId) != nil {
+		return ErrTransactionAccessDenied
+	}
+
+	return nil
+}
+
+func AuthorizeUser(principal *auth.Principal, userId int) error {
+	/*Check principal is allowed to access data of the user: only user itself and admin are.*/
+	if principal.IsAdmin() || principal.IsUser(userId) {
+		return nil
+	}
+
+	return ErrUserAccessDenied
+}
+
+func AuthorizeUserEmail(principal *auth.Principal, userEmail string) error {
+	/*Check principal is allowed to access data of the user with email: only user itself and admin are.*/
+	if principal.IsAdmin() || principal.IsUserEmail(userEmail) {
+		return nil
+	}
+
+	return ErrUserAccessDenied
 }
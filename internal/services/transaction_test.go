@@ -0,0 +1,723 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/providers"
+	"github.com/Pythonyan3/payment-service/internal/risk"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// in-memory TransactionRepository implementation
+type transactionRepositoryStub struct {
+	transactions map[int]*models.Transaction
+	// decision stored with the last created transaction
+	decision *models.RiskDecision
+}
+
+func (stub *transactionRepositoryStub) CreateTransaction(transaction *models.Transaction, decision *models.RiskDecision) (*models.Transaction, error) {
+	transaction.Id = len(stub.transactions) + 1
+	stub.transactions[transaction.Id] = transaction
+	stub.decision = decision
+	return transaction, nil
+}
+
+func (stub *transactionRepositoryStub) UpdateTransactionStatus(
+	transaction *models.Transaction, status string, forward TransactionForward,
+) (*models.Transaction, error) {
+	if forward != nil {
+		if err := forward(transaction); err != nil {
+			return nil, err
+		}
+	}
+	updated := *transaction
+	updated.Status = status
+	stub.transactions[transaction.Id] = &updated
+	return &updated, nil
+}
+
+func (stub *transactionRepositoryStub) GetTransactionById(transactionId int) (*models.Transaction, error) {
+	transaction, ok := stub.transactions[transactionId]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	return transaction, nil
+}
+
+func (stub *transactionRepositoryStub) ExpireTransactions(limit int, forward TransactionForward) ([]*models.Transaction, error) {
+	var expired []*models.Transaction
+	for id := 1; id <= len(stub.transactions) && len(expired) < limit; id++ {
+		transaction := stub.transactions[id]
+		if transaction.Status == TransactionNewStatus && transaction.ExpiresAt != nil && !transaction.ExpiresAt.After(time.Now()) {
+			if err := forward(transaction); err != nil {
+				return nil, err
+			}
+			transaction.Status = TransactionExpiredStatus
+			expired = append(expired, transaction)
+		}
+	}
+	return expired, nil
+}
+
+func (stub *transactionRepositoryStub) SearchTransactions(filter *models.TransactionSearchFilter) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	for id := 1; id <= len(stub.transactions) && len(transactions) < filter.Limit; id++ {
+		transactions = append(transactions, stub.transactions[id])
+	}
+	return transactions, nil
+}
+
+// in-memory UserGetter implementation
+type userGetterStub map[int]*models.User
+
+func (stub userGetterStub) GetUserById(userId int) (*models.User, error) {
+	user, ok := stub[userId]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+var testUsers userGetterStub = userGetterStub{1: {Id: 1, Email: "email@mail.ru"}}
+
+// default time to live of test transactions
+const testTTL time.Duration = time.Hour
+
+func newTestProviders() *providers.Registry {
+	/*Return registry with fake provider used for all payments.*/
+	return providers.NewRegistry(providers.NewFakeProvider("fake"))
+}
+
+func newTestRisk() *risk.Engine {
+	/*Return risk engine without rules, every transaction is allowed.*/
+	return risk.NewEngine(risk.NewRules(), nil)
+}
+
+func TestTransactionService_Cancel(t *testing.T) {
+	testTable := []struct {
+		name           string
+		principal      *auth.Principal
+		expectedStatus string
+		expectedErr    error
+	}{
+		{
+			name:           "Test cancel by owner",
+			principal:      &auth.Principal{Subject: "1"},
+			expectedStatus: TransactionCanceledStatus,
+		},
+		{
+			name:           "Test cancel by admin",
+			principal:      &auth.Principal{Subject: "admin", Scopes: []string{auth.ScopeAdmin}},
+			expectedStatus: TransactionCanceledStatus,
+		},
+		{
+			name:        "Test cancel by another user",
+			principal:   &auth.Principal{Subject: "2"},
+			expectedErr: ErrTransactionAccessDenied,
+		},
+		{
+			name:        "Test cancel by provider",
+			principal:   &auth.Principal{Subject: "provider", Scopes: []string{RoleProvider}},
+			expectedErr: ErrTransactionAccessDenied,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{
+				1: {Id: 1, UserId: 1, Status: TransactionNewStatus},
+			}}
+			service := NewTransactionService(repo, testUsers, newTestProviders(), newTestRisk(), testTTL)
+
+			transaction, err := service.Cancel(1, testCase.principal)
+
+			if testCase.expectedErr != nil {
+				assert.True(t, errors.Is(err, testCase.expectedErr), err)
+				assert.Equal(t, TransactionNewStatus, repo.transactions[1].Status)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedStatus, transaction.Status)
+		})
+	}
+}
+
+// TransactionRepository implementation which loses every status update to concurrent request
+type concurrentUpdateRepositoryStub struct {
+	transactionRepositoryStub
+}
+
+func (stub *concurrentUpdateRepositoryStub) UpdateTransactionStatus(
+	transaction *models.Transaction, status string, forward TransactionForward,
+) (*models.Transaction, error) {
+	// row version differs, so forward is not performed
+	return nil, ErrConcurrentUpdate
+}
+
+func TestTransactionService_ConcurrentUpdate(t *testing.T) {
+	testTable := []struct {
+		name   string
+		update func(service *TransactionService) (*models.Transaction, error)
+	}{
+		{
+			name: "Test proceed concurrently updated transaction",
+			update: func(service *TransactionService) (*models.Transaction, error) {
+				return service.UpdateStatus(1, TransactionSuccessStatus, RoleProvider)
+			},
+		},
+		{
+			name: "Test cancel concurrently updated transaction",
+			update: func(service *TransactionService) (*models.Transaction, error) {
+				return service.Cancel(1, &auth.Principal{Subject: "1"})
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &concurrentUpdateRepositoryStub{transactionRepositoryStub{transactions: map[int]*models.Transaction{
+				1: {Id: 1, UserId: 1, Status: TransactionNewStatus},
+			}}}
+			service := NewTransactionService(repo, testUsers, newTestProviders(), newTestRisk(), testTTL)
+
+			transaction, err := testCase.update(service)
+
+			// conflict is passed to the caller as is, so it is mapped to 409 response
+			assert.Nil(t, transaction)
+			assert.True(t, errors.Is(err, ErrConcurrentUpdate), err)
+			assert.True(t, errors.Is(err, ErrConflict), err)
+			assert.Equal(t, TransactionNewStatus, repo.transactions[1].Status)
+		})
+	}
+}
+
+// TransactionRepository implementation which locks transaction rows and checks their versions
+// like postgres repository does and keeps wallet balances of the only user
+type lockingRepositoryStub struct {
+	transactionRepositoryStub
+	mu        sync.Mutex
+	available int64
+	held      int64
+}
+
+func (stub *lockingRepositoryStub) GetTransactionById(transactionId int) (*models.Transaction, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	transaction := *stub.transactions[transactionId]
+	return &transaction, nil
+}
+
+func (stub *lockingRepositoryStub) UpdateTransactionStatus(
+	transaction *models.Transaction, status string, forward TransactionForward,
+) (*models.Transaction, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if stub.transactions[transaction.Id].Version != transaction.Version {
+		return nil, ErrConcurrentUpdate
+	}
+	locked := *stub.transactions[transaction.Id]
+	if err := forward(&locked); err != nil {
+		return nil, err
+	}
+	stub.setStatus(&locked, status)
+	return &locked, nil
+}
+
+func (stub *lockingRepositoryStub) ExpireTransactions(limit int, forward TransactionForward) ([]*models.Transaction, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	var expired []*models.Transaction
+	for id := 1; id <= len(stub.transactions) && len(expired) < limit; id++ {
+		locked := *stub.transactions[id]
+		if locked.Status == TransactionNewStatus && !locked.ExpiresAt.After(time.Now()) {
+			if err := forward(&locked); err != nil {
+				return nil, err
+			}
+			stub.setStatus(&locked, TransactionExpiredStatus)
+			expired = append(expired, &locked)
+		}
+	}
+	return expired, nil
+}
+
+func (stub *lockingRepositoryStub) setStatus(transaction *models.Transaction, status string) {
+	transaction.Status = status
+	transaction.Version++
+	available, held := TransactionWalletChange(transaction)
+	stub.available += available
+	stub.held += held
+	stored := *transaction
+	stub.transactions[transaction.Id] = &stored
+}
+
+// PaymentProvider implementation which pauses after capture until sweeper expired transactions (or timeout),
+// so sweeper runs between provider operation and status update unless transaction row is locked
+type pausingProvider struct {
+	*providers.FakeProvider
+	captured chan struct{}
+	expired  chan struct{}
+}
+
+func (provider *pausingProvider) Capture(ctx context.Context, reference string, amount int64) (*providers.Result, error) {
+	result, err := provider.FakeProvider.Capture(ctx, reference, amount)
+	close(provider.captured)
+	select {
+	case <-provider.expired:
+	case <-time.After(100 * time.Millisecond):
+	}
+	return result, err
+}
+
+func TestTransactionService_ProceedExpireRace(t *testing.T) {
+	// Arrange
+	var proceedErr error
+	var proceeded chan struct{} = make(chan struct{})
+	provider := &pausingProvider{
+		FakeProvider: providers.NewFakeProvider("fake"),
+		captured:     make(chan struct{}),
+		expired:      make(chan struct{}),
+	}
+	payment, _ := provider.Authorize(context.Background(), &providers.Payment{Amount: 1500, Currency: "EUR", UserId: 1})
+	name := provider.Name()
+	expiresAt := time.Now().Add(-time.Minute)
+	// NEW transaction holds it's amount in the wallet, captured amount is debited, released one is available again
+	repo := &lockingRepositoryStub{
+		transactionRepositoryStub: transactionRepositoryStub{transactions: map[int]*models.Transaction{
+			1: {
+				Id: 1, UserId: 1, Amount: 1500, Currency: "EUR", Status: TransactionNewStatus, Version: 1,
+				Provider: &name, ProviderReference: &payment.Reference, ExpiresAt: &expiresAt,
+			},
+		}},
+		held: 1500,
+	}
+	service := NewTransactionService(repo, testUsers, providers.NewRegistry(provider), newTestRisk(), testTTL)
+
+	// Act
+	go func() {
+		defer close(proceeded)
+		_, proceedErr = service.UpdateStatus(1, TransactionSuccessStatus, RoleProvider)
+	}()
+	// sweeper runs after payment is captured, but before proceed request stored the status
+	<-provider.captured
+	expiredCount, expireErr := service.Expire(10)
+	close(provider.expired)
+	<-proceeded
+
+	// Assert
+	// sweeper waits for locked transaction, so payment state at provider and wallet balances match stored status
+	assert.NoError(t, expireErr)
+	assert.Equal(t, 0, expiredCount)
+	assert.NoError(t, proceedErr)
+	assert.Equal(t, TransactionSuccessStatus, repo.transactions[1].Status)
+	result, _ := provider.QueryStatus(context.Background(), payment.Reference)
+	assert.Equal(t, providers.StatusCaptured, result.Status)
+	assert.Equal(t, int64(0), repo.available)
+	assert.Equal(t, int64(0), repo.held)
+}
+
+func TestTransactionService_CreateAmount(t *testing.T) {
+	testTable := []struct {
+		name           string
+		input          *models.TransactionInput
+		expectedAmount int64
+		expectedField  string
+		expectedTag    string
+	}{
+		{
+			name:           "Test create with minor units amount",
+			input:          &models.TransactionInput{UserId: 1, Amount: 1550, Currency: "EUR"},
+			expectedAmount: 1550,
+		},
+		{
+			name:           "Test create with decimal amount",
+			input:          &models.TransactionInput{UserId: 1, AmountDecimal: "15.5", Currency: "EUR"},
+			expectedAmount: 1550,
+		},
+		{
+			name:          "Test create with decimal amount exceeding currency precision",
+			input:         &models.TransactionInput{UserId: 1, AmountDecimal: "15.5", Currency: "JPY"},
+			expectedField: "amount_decimal",
+			expectedTag:   "precision",
+		},
+		{
+			name:          "Test create with negative amount",
+			input:         &models.TransactionInput{UserId: 1, Amount: -100, Currency: "EUR"},
+			expectedField: "amount",
+			expectedTag:   "gt",
+		},
+		{
+			name:          "Test create with unknown currency",
+			input:         &models.TransactionInput{UserId: 1, Amount: 100, Currency: "ABC"},
+			expectedField: "currency",
+			expectedTag:   "currency",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{}}
+			service := NewTransactionService(repo, testUsers, newTestProviders(), newTestRisk(), testTTL)
+
+			transaction, err := service.Create(testCase.input)
+
+			if testCase.expectedField != "" {
+				var validationErr *ValidationError
+				assert.True(t, errors.As(err, &validationErr), err)
+				assert.Equal(t, testCase.expectedField, validationErr.Fields[0].Field)
+				assert.Equal(t, testCase.expectedTag, validationErr.Fields[0].Tag)
+				assert.Empty(t, repo.transactions)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedAmount, transaction.Amount)
+		})
+	}
+}
+
+func TestTransactionService_CreateUser(t *testing.T) {
+	repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{}}
+	service := NewTransactionService(repo, testUsers, newTestProviders(), newTestRisk(), testTTL)
+
+	// email of transaction is taken from the user record
+	transaction, err := service.Create(&models.TransactionInput{UserId: 1, Amount: 100, Currency: "EUR"})
+	assert.NoError(t, err)
+	assert.Equal(t, "email@mail.ru", transaction.UserEmail)
+
+	_, err = service.Create(&models.TransactionInput{UserId: 2, Amount: 100, Currency: "EUR"})
+	assert.True(t, errors.Is(err, ErrUserNotFound), err)
+	assert.Len(t, repo.transactions, 1)
+}
+
+func TestTransactionService_Search(t *testing.T) {
+	repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{
+		1: {Id: 1, Amount: 300},
+		2: {Id: 2, Amount: 200},
+		3: {Id: 3, Amount: 100},
+	}}
+	service := NewTransactionService(repo, testUsers, newTestProviders(), newTestRisk(), testTTL)
+	filter := &models.TransactionSearchFilter{Sort: "amount", Order: "desc", Limit: 2}
+
+	// Act
+	page, err := service.Search(filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	cursor, err := models.DecodeTransactionSearchCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.TransactionSearchCursor{Sort: "amount", Order: "desc", Amount: 200, Id: 2}, cursor)
+
+	// cursor issued for another sorting is rejected
+	_, err = service.Search(&models.TransactionSearchFilter{Sort: "amount", Order: "asc", Limit: 2, Cursor: page.NextCursor})
+	assert.True(t, errors.Is(err, ErrInvalidCursor), err)
+
+	page, err = service.Search(&models.TransactionSearchFilter{Sort: "amount", Order: "desc", Limit: 3})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 3)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestTransactionService_CreateProvider(t *testing.T) {
+	testTable := []struct {
+		name           string
+		amount         int64
+		expectedStatus string
+		expectedErr    error
+	}{
+		{name: "Test create authorized transaction", amount: 1500, expectedStatus: TransactionNewStatus},
+		{name: "Test create declined transaction", amount: 1551, expectedStatus: TransactionFailedStatus},
+		{name: "Test create with unavailable provider", amount: 1591, expectedErr: ErrPaymentProviderUnavailable},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{}}
+			service := NewTransactionService(repo, testUsers, newTestProviders(), newTestRisk(), testTTL)
+
+			transaction, err := service.Create(&models.TransactionInput{UserId: 1, Amount: testCase.amount, Currency: "EUR"})
+
+			if testCase.expectedErr != nil {
+				assert.True(t, errors.Is(err, testCase.expectedErr), err)
+				assert.Empty(t, repo.transactions)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedStatus, transaction.Status)
+			assert.Equal(t, "fake", *transaction.Provider)
+			assert.Equal(t, "fake_1", *transaction.ProviderReference)
+		})
+	}
+}
+
+// TransactionRepository implementation which can not hold transaction funds
+type insufficientFundsRepositoryStub struct {
+	transactionRepositoryStub
+}
+
+func (stub *insufficientFundsRepositoryStub) CreateTransaction(transaction *models.Transaction, decision *models.RiskDecision) (*models.Transaction, error) {
+	return nil, ErrInsufficientFunds
+}
+
+func TestTransactionService_CreateVoid(t *testing.T) {
+	// Arrange
+	fake := providers.NewFakeProvider("fake")
+	repo := &insufficientFundsRepositoryStub{transactionRepositoryStub{transactions: map[int]*models.Transaction{}}}
+	service := NewTransactionService(repo, testUsers, providers.NewRegistry(fake), newTestRisk(), testTTL)
+
+	// Act
+	transaction, err := service.Create(&models.TransactionInput{UserId: 1, Amount: 1500, Currency: "EUR"})
+
+	// Assert
+	assert.Nil(t, transaction)
+	assert.True(t, errors.Is(err, ErrInsufficientFunds), err)
+	// authorization of not created transaction is released
+	result, _ := fake.QueryStatus(context.Background(), "fake_1")
+	assert.Equal(t, providers.StatusVoided, result.Status)
+}
+
+func TestTransactionService_CreateRisk(t *testing.T) {
+	rules := risk.NewRules()
+	rules.AmountLimits["EUR"] = 10000
+	rules.BlockedDomains["spam.com"] = true
+	users := userGetterStub{1: testUsers[1], 2: {Id: 2, Email: "user@mail.spam.com"}}
+
+	testTable := []struct {
+		name            string
+		input           *models.TransactionInput
+		expectedStatus  string
+		expectedOutcome string
+		expectedRules   []string
+	}{
+		{
+			name:            "Test create allowed transaction",
+			input:           &models.TransactionInput{UserId: 1, Amount: 10000, Currency: "EUR"},
+			expectedStatus:  TransactionNewStatus,
+			expectedOutcome: risk.OutcomeAllow,
+			expectedRules:   []string{},
+		},
+		{
+			name:            "Test create transaction exceeding amount limit",
+			input:           &models.TransactionInput{UserId: 1, Amount: 10001, Currency: "EUR"},
+			expectedStatus:  TransactionErrorStatus,
+			expectedOutcome: risk.OutcomeBlock,
+			expectedRules:   []string{risk.RuleAmountLimit},
+		},
+		{
+			name:            "Test create transaction matching several rules",
+			input:           &models.TransactionInput{UserId: 2, Amount: 10001, Currency: "EUR"},
+			expectedStatus:  TransactionErrorStatus,
+			expectedOutcome: risk.OutcomeBlock,
+			expectedRules:   []string{risk.RuleAmountLimit, risk.RuleBlockedDomain},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{}}
+			service := NewTransactionService(repo, users, newTestProviders(), risk.NewEngine(rules, nil), testTTL)
+
+			transaction, err := service.Create(testCase.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedStatus, transaction.Status)
+			assert.Equal(t, testCase.expectedOutcome, repo.decision.Outcome)
+			rules := []string{}
+			for _, reason := range repo.decision.Reasons {
+				rules = append(rules, reason.Rule)
+			}
+			assert.Equal(t, testCase.expectedRules, rules)
+			if testCase.expectedStatus == TransactionErrorStatus {
+				// blocked transaction is not sent to payment provider
+				assert.Nil(t, transaction.Provider)
+			}
+		})
+	}
+}
+
+func TestTransactionService_UpdateStatusProvider(t *testing.T) {
+	testTable := []struct {
+		name             string
+		status           string
+		role             string
+		captureFirst     bool
+		expectedStatus   string
+		expectedProvider string
+		expectedErr      error
+	}{
+		{
+			name:             "Test proceed captures payment",
+			status:           TransactionSuccessStatus,
+			role:             RoleProvider,
+			expectedStatus:   TransactionSuccessStatus,
+			expectedProvider: providers.StatusCaptured,
+		},
+		{
+			name:             "Test fail voids payment",
+			status:           TransactionFailedStatus,
+			role:             RoleProvider,
+			expectedStatus:   TransactionFailedStatus,
+			expectedProvider: providers.StatusVoided,
+		},
+		{
+			name:             "Test cancel voids payment",
+			status:           TransactionCanceledStatus,
+			role:             RoleUser,
+			expectedStatus:   TransactionCanceledStatus,
+			expectedProvider: providers.StatusVoided,
+		},
+		{
+			name:             "Test cancel of payment captured by provider",
+			status:           TransactionCanceledStatus,
+			role:             RoleUser,
+			captureFirst:     true,
+			expectedStatus:   TransactionNewStatus,
+			expectedProvider: providers.StatusCaptured,
+			expectedErr:      ErrPaymentProviderRejected,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			fake := providers.NewFakeProvider("fake")
+			payment, _ := fake.Authorize(context.Background(), &providers.Payment{Amount: 1500, Currency: "EUR"})
+			if testCase.captureFirst {
+				fake.Capture(context.Background(), payment.Reference, 1500)
+			}
+			name := fake.Name()
+			repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{
+				1: {Id: 1, UserId: 1, Amount: 1500, Status: TransactionNewStatus, Provider: &name, ProviderReference: &payment.Reference},
+			}}
+			service := NewTransactionService(repo, testUsers, providers.NewRegistry(fake), newTestRisk(), testTTL)
+
+			_, err := service.UpdateStatus(1, testCase.status, testCase.role)
+
+			if testCase.expectedErr != nil {
+				assert.True(t, errors.Is(err, testCase.expectedErr), err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.expectedStatus, repo.transactions[1].Status)
+			result, _ := fake.QueryStatus(context.Background(), payment.Reference)
+			assert.Equal(t, testCase.expectedProvider, result.Status)
+		})
+	}
+}
+
+func TestTransactionService_Sync(t *testing.T) {
+	// Arrange
+	fake := providers.NewFakeProvider("fake")
+	name := fake.Name()
+	captured, _ := fake.Authorize(context.Background(), &providers.Payment{Amount: 1500, Currency: "EUR"})
+	fake.Capture(context.Background(), captured.Reference, 1500)
+	authorized, _ := fake.Authorize(context.Background(), &providers.Payment{Amount: 1500, Currency: "EUR"})
+	repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{
+		1: {Id: 1, Status: TransactionNewStatus, Provider: &name, ProviderReference: &captured.Reference},
+		2: {Id: 2, Status: TransactionNewStatus, Provider: &name, ProviderReference: &authorized.Reference},
+		3: {Id: 3, Status: TransactionNewStatus},
+	}}
+	service := NewTransactionService(repo, testUsers, providers.NewRegistry(fake), newTestRisk(), testTTL)
+
+	// Act
+	synced, err := service.Sync(1)
+	assert.NoError(t, err)
+	assert.Equal(t, TransactionSuccessStatus, synced.Status)
+
+	// payment is still waiting for capture
+	synced, err = service.Sync(2)
+	assert.NoError(t, err)
+	assert.Equal(t, TransactionNewStatus, synced.Status)
+
+	_, err = service.Sync(3)
+	assert.True(t, errors.Is(err, ErrNoPaymentProvider), err)
+}
+
+func TestTransactionService_CreateExpiry(t *testing.T) {
+	now := time.Date(2022, 6, 12, 18, 9, 14, 0, time.UTC)
+	rules := risk.NewRules()
+	rules.AmountLimits["EUR"] = 10000
+
+	testTable := []struct {
+		name              string
+		input             *models.TransactionInput
+		expectedExpiresAt *time.Time
+	}{
+		{
+			name:              "Test create with default TTL",
+			input:             &models.TransactionInput{UserId: 1, Amount: 1500, Currency: "EUR"},
+			expectedExpiresAt: func() *time.Time { expiresAt := now.Add(testTTL); return &expiresAt }(),
+		},
+		{
+			name:              "Test create with transaction TTL",
+			input:             &models.TransactionInput{UserId: 1, Amount: 1500, Currency: "EUR", TTLSeconds: 300},
+			expectedExpiresAt: func() *time.Time { expiresAt := now.Add(5 * time.Minute); return &expiresAt }(),
+		},
+		{
+			// declined transaction is not expired
+			name:  "Test create FAILED transaction",
+			input: &models.TransactionInput{UserId: 1, Amount: 1551, Currency: "EUR", TTLSeconds: 300},
+		},
+		{
+			name:  "Test create ERROR transaction",
+			input: &models.TransactionInput{UserId: 1, Amount: 10001, Currency: "EUR"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{}}
+			service := NewTransactionService(repo, testUsers, newTestProviders(), risk.NewEngine(rules, nil), testTTL)
+			service.now = func() time.Time { return now }
+
+			transaction, err := service.Create(testCase.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedExpiresAt, transaction.ExpiresAt)
+		})
+	}
+}
+
+func TestTransactionService_Expire(t *testing.T) {
+	// Arrange
+	fake := providers.NewFakeProvider("fake")
+	name := fake.Name()
+	authorized, _ := fake.Authorize(context.Background(), &providers.Payment{Amount: 1500, Currency: "EUR"})
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	repo := &transactionRepositoryStub{transactions: map[int]*models.Transaction{
+		1: {Id: 1, Status: TransactionNewStatus, ExpiresAt: &past, Provider: &name, ProviderReference: &authorized.Reference},
+		2: {Id: 2, Status: TransactionNewStatus, ExpiresAt: &past},
+		3: {Id: 3, Status: TransactionNewStatus, ExpiresAt: &past},
+		4: {Id: 4, Status: TransactionNewStatus, ExpiresAt: &future},
+		5: {Id: 5, Status: TransactionSuccessStatus, ExpiresAt: &past},
+	}}
+	service := NewTransactionService(repo, testUsers, providers.NewRegistry(fake), newTestRisk(), testTTL)
+
+	// Act
+	first, err := service.Expire(2)
+	assert.NoError(t, err)
+	second, err := service.Expire(2)
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 2, first)
+	assert.Equal(t, 1, second)
+	for id, expectedStatus := range map[int]string{
+		1: TransactionExpiredStatus,
+		2: TransactionExpiredStatus,
+		3: TransactionExpiredStatus,
+		4: TransactionNewStatus,
+		5: TransactionSuccessStatus,
+	} {
+		assert.Equal(t, expectedStatus, repo.transactions[id].Status, id)
+	}
+	// payment of expired transaction is voided
+	result, _ := fake.QueryStatus(context.Background(), authorized.Reference)
+	assert.Equal(t, providers.StatusVoided, result.Status)
+}
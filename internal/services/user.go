@@ -2,9 +2,27 @@ package services
 
 import "github.com/Pythonyan3/payment-service/internal/models"
 
+var (
+	// Error returned when pagination cursor can not be decoded
+	ErrInvalidCursor = NewError("invalid_cursor", "Invalid pagination cursor.", ErrValidation)
+	// Error returned when email belongs to another user
+	ErrUserEmailTaken = NewError("user_email_taken", "User with the same email already exists.", ErrConflict)
+	// Error returned when deleted user is referenced by transactions or wallets
+	ErrUserInUse = NewError("user_in_use", "User has transactions or wallets and can not be deleted.", ErrConflict)
+)
+
+// Source of user records for services referring to users
+type UserGetter interface {
+	GetUserById(userId int) (*models.User, error)
+}
+
 type UserRepository interface {
-	GetUserTransactionsById(userId int) ([]*models.Transaction, error)
-	GetUserTransactionsByEmail(userEmail string) ([]*models.Transaction, error)
+	UserGetter
+	CreateUser(user *models.User) (*models.User, error)
+	GetUserByEmail(userEmail string) (*models.User, error)
+	UpdateUser(user *models.User) (*models.User, error)
+	DeleteUser(userId int) error
+	GetUserTransactionsById(userId int, filter *models.TransactionFilter) ([]*models.Transaction, error)
 }
 
 type UserService struct {
@@ -16,12 +34,82 @@ func NewUserService(repo UserRepository) *UserService {
 	return &UserService{repo: repo}
 }
 
-func (service *UserService) GetUserTransactionsById(userId int) ([]*models.Transaction, error) {
-	/*Retrieve list of transactions filtered by user id.*/
-	return service.repo.GetUserTransactionsById(userId)
+func (service *UserService) Create(userInput *models.UserInput) (*models.User, error) {
+	/*Create new user (add new record to DB).*/
+	return service.repo.CreateUser(&models.User{Email: userInput.Email, Name: userInput.Name})
+}
+
+func (service *UserService) GetById(userId int) (*models.User, error) {
+	/*Retrieve user by id.*/
+	return service.repo.GetUserById(userId)
+}
+
+func (service *UserService) Update(userId int, userInput *models.UserInput) (*models.User, error) {
+	/*Replace user data, emails of already created transactions are not changed.*/
+	return service.repo.UpdateUser(&models.User{Id: userId, Email: userInput.Email, Name: userInput.Name})
+}
+
+func (service *UserService) Delete(userId int) error {
+	/*Delete user, only users without transactions and wallets can be deleted.*/
+	return service.repo.DeleteUser(userId)
+}
+
+func (service *UserService) GetUserTransactionsById(userId int, filter *models.TransactionFilter) (*models.TransactionPage, error) {
+	/*Retrieve page of transactions filtered by user id.*/
+	var err error
+	var pageFilter *models.TransactionFilter
+	var transactions []*models.Transaction
+
+	if pageFilter, err = newPageFilter(filter); err != nil {
+		return nil, err
+	}
+
+	if transactions, err = service.repo.GetUserTransactionsById(userId, pageFilter); err != nil {
+		return nil, err
+	}
+
+	return newTransactionPage(transactions, filter.Limit), nil
+}
+
+func (service *UserService) GetUserTransactionsByEmail(userEmail string, filter *models.TransactionFilter) (*models.TransactionPage, error) {
+	/*Retrieve page of transactions of the user found by email.*/
+	user, err := service.repo.GetUserByEmail(userEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.GetUserTransactionsById(user.Id, filter)
+}
+
+func newPageFilter(filter *models.TransactionFilter) (*models.TransactionFilter, error) {
+	/*
+		Return copy of filter prepared for repository call.
+
+		Decode cursor and request one extra row to find out whether the next page exists.
+	*/
+	var pageFilter models.TransactionFilter = *filter
+
+	if filter.Cursor != "" {
+		cursor, err := models.DecodeTransactionCursor(filter.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		pageFilter.After = cursor
+	}
+	pageFilter.Limit = filter.Limit + 1
+
+	return &pageFilter, nil
 }
 
-func (service *UserService) GetUserTransactionsByEmail(userEmail string) ([]*models.Transaction, error) {
-	/*Retrieve list of transactions filtered by user email.*/
-	return service.repo.GetUserTransactionsByEmail(userEmail)
+func newTransactionPage(transactions []*models.Transaction, limit int) *models.TransactionPage {
+	/*Build transactions page, extra row (if exists) is dropped and used as a sign of the next page.*/
+	var page *models.TransactionPage = &models.TransactionPage{Items: transactions, Limit: limit}
+
+	if len(transactions) > limit {
+		page.Items = transactions[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = (&models.TransactionCursor{CreatedAt: last.CreatedAt, Id: last.Id}).Encode()
+	}
+
+	return page
 }
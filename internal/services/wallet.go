@@ -0,0 +1,53 @@
+package services
+
+import "github.com/Pythonyan3/payment-service/internal/models"
+
+var (
+	// Error returned when wallet available funds are less than transaction amount
+	ErrInsufficientFunds = NewError("insufficient_funds", "Not enough funds in the wallet.", ErrPaymentRequired)
+	// Error returned when wallet balance would exceed max amount
+	ErrWalletBalanceExceeded = NewError(
+		"wallet_balance_exceeded", "Wallet balance would exceed max allowed amount.", ErrValidation)
+)
+
+type WalletRepository interface {
+	GetUserWallets(userId int) ([]*models.Wallet, error)
+	DepositWallet(userId int, currency string, amount int64) (*models.Wallet, error)
+}
+
+type WalletService struct {
+	repo WalletRepository
+}
+
+func NewWalletService(repo WalletRepository) *WalletService {
+	/*Wallet service constructor function.*/
+	return &WalletService{repo: repo}
+}
+
+func (service *WalletService) List(userId int) ([]*models.Wallet, error) {
+	/*Retrieve all wallets of the user.*/
+	return service.repo.GetUserWallets(userId)
+}
+
+func (service *WalletService) Deposit(userId int, currency string, depositInput *models.WalletDepositInput) (*models.Wallet, error) {
+	/*Add funds to available balance of user wallet, wallet is created on the first deposit.*/
+	return service.repo.DepositWallet(userId, currency, depositInput.Amount)
+}
+
+func TransactionWalletChange(transaction *models.Transaction) (available int64, held int64) {
+	/*
+		Return change of user wallet balances caused by assigned transaction status.
+
+		NEW holds available funds, SUCCESS debits held funds, FAILED, CANCELED and EXPIRED return held funds back.
+	*/
+	switch transaction.Status {
+	case TransactionNewStatus:
+		return -transaction.Amount, transaction.Amount
+	case TransactionSuccessStatus:
+		return 0, -transaction.Amount
+	case TransactionFailedStatus, TransactionCanceledStatus, TransactionExpiredStatus:
+		return transaction.Amount, -transaction.Amount
+	default:
+		return 0, 0
+	}
+}
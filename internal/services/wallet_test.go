@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionWalletChange(t *testing.T) {
+	// Arrange
+	testTable := []struct {
+		name              string
+		status            string
+		expectedAvailable int64
+		expectedHeld      int64
+	}{
+		{name: "Test hold new transaction", status: TransactionNewStatus, expectedAvailable: -1500, expectedHeld: 1500},
+		{name: "Test capture successful transaction", status: TransactionSuccessStatus, expectedHeld: -1500},
+		{name: "Test release failed transaction", status: TransactionFailedStatus, expectedAvailable: 1500, expectedHeld: -1500},
+		{name: "Test release canceled transaction", status: TransactionCanceledStatus, expectedAvailable: 1500, expectedHeld: -1500},
+		{name: "Test release expired transaction", status: TransactionExpiredStatus, expectedAvailable: 1500, expectedHeld: -1500},
+		{name: "Test errored transaction", status: TransactionErrorStatus},
+		{name: "Test refunded transaction", status: TransactionRefundedStatus},
+	}
+
+	// Act
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			available, held := TransactionWalletChange(&models.Transaction{Amount: 1500, Status: testCase.status})
+
+			// Assert
+			assert.Equal(t, testCase.expectedAvailable, available)
+			assert.Equal(t, testCase.expectedHeld, held)
+		})
+	}
+}
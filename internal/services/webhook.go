@@ -0,0 +1,158 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+)
+
+const (
+	// Transaction events sent to webhook endpoints
+	TransactionCreatedEvent       string = "transaction.created"
+	TransactionStatusChangedEvent string = "transaction.status_changed"
+
+	// Webhook delivery possible statuses
+	WebhookDeliveryPendingStatus   string = "PENDING"
+	WebhookDeliveryDeliveredStatus string = "DELIVERED"
+	WebhookDeliveryDeadStatus      string = "DEAD"
+
+	// Length of generated webhook endpoint secret in bytes
+	webhookSecretLength int = 32
+)
+
+// Error returned when webhook endpoint URL points to internal network of the service
+var ErrWebhookEndpointURLNotAllowed = NewError(
+	"webhook_endpoint_url_not_allowed", "Webhook endpoint URL must resolve to public addresses only.", ErrValidation)
+
+type WebhookEndpointRepository interface {
+	CreateWebhookEndpoint(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	GetWebhookEndpoints(merchantId *string) ([]*models.WebhookEndpoint, error)
+	DeactivateWebhookEndpoint(endpointId int, merchantId *string) (*models.WebhookEndpoint, error)
+}
+
+type WebhookService struct {
+	repo     WebhookEndpointRepository
+	lookupIP func(host string) ([]net.IP, error)
+}
+
+func NewWebhookService(repo WebhookEndpointRepository) *WebhookService {
+	/*Webhook service constructor function.*/
+	return &WebhookService{repo: repo, lookupIP: net.LookupIP}
+}
+
+func (service *WebhookService) Create(
+	endpointInput *models.WebhookEndpointInput, principal *auth.Principal,
+) (*models.WebhookEndpoint, error) {
+	/*
+		Register new webhook endpoint of principal's merchant.
+
+		Endpoint secret used for signing events is generated and returned only once.
+	*/
+	var secret []byte = make([]byte, webhookSecretLength)
+
+	merchantId, err := webhookEndpointMerchant(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = service.checkURL(endpointInput.URL); err != nil {
+		return nil, err
+	}
+
+	if _, err = rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("rand.Read failed: %w", err)
+	}
+
+	return service.repo.CreateWebhookEndpoint(&models.WebhookEndpoint{
+		URL:        endpointInput.URL,
+		MerchantId: merchantId,
+		Secret:     hex.EncodeToString(secret),
+	})
+}
+
+func (service *WebhookService) List(principal *auth.Principal) ([]*models.WebhookEndpoint, error) {
+	/*Retrieve list of active webhook endpoints available to principal, secrets are not exposed.*/
+	merchantId, err := webhookEndpointMerchant(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints, err := service.repo.GetWebhookEndpoints(merchantId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+
+	return endpoints, nil
+}
+
+func (service *WebhookService) Deactivate(endpointId int, principal *auth.Principal) error {
+	/*Deactivate webhook endpoint available to principal, it won't receive events anymore.*/
+	merchantId, err := webhookEndpointMerchant(principal)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.repo.DeactivateWebhookEndpoint(endpointId, merchantId)
+
+	return err
+}
+
+func (service *WebhookService) checkURL(rawURL string) error {
+	/*
+		Check that every address of webhook endpoint URL host is public.
+
+		Addresses are checked again by dispatcher on every connection, so host can not be moved to internal
+		network after registration.
+	*/
+	endpointURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrWebhookEndpointURLNotAllowed
+	}
+
+	ips, err := service.lookupIP(endpointURL.Hostname())
+	if err != nil {
+		return ErrWebhookEndpointURLNotAllowed
+	}
+
+	for _, ip := range ips {
+		if !IsWebhookIPAllowed(ip) {
+			return ErrWebhookEndpointURLNotAllowed
+		}
+	}
+
+	return nil
+}
+
+func IsWebhookIPAllowed(ip net.IP) bool {
+	/*Check that webhook request can be sent to IP address, loopback, private and link-local addresses are denied.*/
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified())
+}
+
+func webhookEndpointMerchant(principal *auth.Principal) (*string, error) {
+	/*
+		Return merchant which webhook endpoints are managed by principal.
+
+		Admin manages endpoints of all merchants (nil merchant), other principals manage only endpoints
+		of their own merchant and principals without merchant are not allowed to manage endpoints.
+	*/
+	if principal.IsAdmin() {
+		return nil, nil
+	}
+
+	if principal.MerchantId == "" {
+		return nil, ErrWebhookEndpointAccessDenied
+	}
+
+	return &principal.MerchantId, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/Pythonyan3/payment-service/internal/auth"
+	"github.com/Pythonyan3/payment-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// in-memory WebhookEndpointRepository implementation
+type webhookEndpointRepositoryStub struct {
+	endpoints []*models.WebhookEndpoint
+}
+
+func (stub *webhookEndpointRepositoryStub) CreateWebhookEndpoint(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	endpoint.Id = len(stub.endpoints) + 1
+	endpoint.IsActive = true
+	stub.endpoints = append(stub.endpoints, endpoint)
+	return endpoint, nil
+}
+
+func (stub *webhookEndpointRepositoryStub) GetWebhookEndpoints(merchantId *string) ([]*models.WebhookEndpoint, error) {
+	var endpoints []*models.WebhookEndpoint
+	for _, endpoint := range stub.endpoints {
+		if endpoint.IsActive && stub.available(endpoint, merchantId) {
+			copied := *endpoint
+			endpoints = append(endpoints, &copied)
+		}
+	}
+	return endpoints, nil
+}
+
+func (stub *webhookEndpointRepositoryStub) DeactivateWebhookEndpoint(
+	endpointId int, merchantId *string,
+) (*models.WebhookEndpoint, error) {
+	if endpointId > len(stub.endpoints) || !stub.available(stub.endpoints[endpointId-1], merchantId) {
+		return nil, ErrWebhookEndpointNotFound
+	}
+	stub.endpoints[endpointId-1].IsActive = false
+	return stub.endpoints[endpointId-1], nil
+}
+
+func (stub *webhookEndpointRepositoryStub) available(endpoint *models.WebhookEndpoint, merchantId *string) bool {
+	return merchantId == nil || (endpoint.MerchantId != nil && *endpoint.MerchantId == *merchantId)
+}
+
+// net.LookupIP replacement which resolves hosts of the map and IP literals
+func lookupIPStub(hosts map[string]string) func(host string) ([]net.IP, error) {
+	return func(host string) ([]net.IP, error) {
+		if ip := net.ParseIP(host); ip != nil {
+			return []net.IP{ip}, nil
+		}
+		if ip, ok := hosts[host]; ok {
+			return []net.IP{net.ParseIP(ip)}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+}
+
+func TestWebhookService(t *testing.T) {
+	repo := &webhookEndpointRepositoryStub{}
+	service := NewWebhookService(repo)
+	service.lookupIP = lookupIPStub(map[string]string{
+		"admin.example.com": "93.184.216.34", "merchant.example.com": "93.184.216.34",
+	})
+	admin := &auth.Principal{Subject: "admin", Scopes: []string{auth.ScopeAdmin}}
+	merchant1 := &auth.Principal{Subject: "client-1", MerchantId: "merchant-1"}
+	merchant2 := &auth.Principal{Subject: "client-2", MerchantId: "merchant-2"}
+
+	// admin endpoint has no merchant and receives events of all merchants
+	adminEndpoint, err := service.Create(&models.WebhookEndpointInput{URL: "https://admin.example.com"}, admin)
+	assert.NoError(t, err)
+	assert.Nil(t, adminEndpoint.MerchantId)
+	assert.NotEmpty(t, adminEndpoint.Secret)
+
+	// merchant endpoint is owned by principal's merchant
+	endpoint, err := service.Create(&models.WebhookEndpointInput{URL: "https://merchant.example.com"}, merchant1)
+	assert.NoError(t, err)
+	assert.Equal(t, "merchant-1", *endpoint.MerchantId)
+
+	// principal without merchant can not manage endpoints
+	_, err = service.Create(&models.WebhookEndpointInput{URL: "https://user.example.com"}, &auth.Principal{Subject: "1"})
+	assert.True(t, errors.Is(err, ErrWebhookEndpointAccessDenied), err)
+
+	// merchant lists only own endpoints, admin lists all of them
+	endpoints, err := service.List(merchant1)
+	assert.NoError(t, err)
+	assert.Len(t, endpoints, 1)
+	assert.Equal(t, endpoint.Id, endpoints[0].Id)
+	assert.Empty(t, endpoints[0].Secret)
+	endpoints, _ = service.List(merchant2)
+	assert.Len(t, endpoints, 0)
+	endpoints, _ = service.List(admin)
+	assert.Len(t, endpoints, 2)
+
+	// endpoint of another merchant is not found
+	err = service.Deactivate(endpoint.Id, merchant2)
+	assert.True(t, errors.Is(err, ErrWebhookEndpointNotFound), err)
+	assert.NoError(t, service.Deactivate(endpoint.Id, merchant1))
+	assert.NoError(t, service.Deactivate(adminEndpoint.Id, admin))
+}
+
+func TestWebhookService_CreateURL(t *testing.T) {
+	repo := &webhookEndpointRepositoryStub{}
+	service := NewWebhookService(repo)
+	service.lookupIP = lookupIPStub(map[string]string{
+		"public.example.com": "93.184.216.34", "internal.example.com": "10.0.0.5",
+	})
+	admin := &auth.Principal{Subject: "admin", Scopes: []string{auth.ScopeAdmin}}
+
+	testTable := []struct {
+		name          string
+		url           string
+		expectedError error
+	}{
+		{
+			name: "Test public host",
+			url:  "https://public.example.com/hook",
+		},
+		{
+			name: "Test public IP",
+			url:  "http://93.184.216.34:8080/hook",
+		},
+		{
+			name:          "Test host resolved to private IP",
+			url:           "https://internal.example.com/hook",
+			expectedError: ErrWebhookEndpointURLNotAllowed,
+		},
+		{
+			name:          "Test not resolved host",
+			url:           "https://unknown.example.com/hook",
+			expectedError: ErrWebhookEndpointURLNotAllowed,
+		},
+		{
+			name:          "Test loopback",
+			url:           "http://127.0.0.1:8000/hook",
+			expectedError: ErrWebhookEndpointURLNotAllowed,
+		},
+		{
+			name:          "Test IPv6 loopback",
+			url:           "http://[::1]:8000/hook",
+			expectedError: ErrWebhookEndpointURLNotAllowed,
+		},
+		{
+			name:          "Test private",
+			url:           "http://192.168.1.10/hook",
+			expectedError: ErrWebhookEndpointURLNotAllowed,
+		},
+		{
+			name:          "Test link-local metadata",
+			url:           "http://169.254.169.254/latest/meta-data",
+			expectedError: ErrWebhookEndpointURLNotAllowed,
+		},
+		{
+			name:          "Test unspecified",
+			url:           "http://0.0.0.0/hook",
+			expectedError: ErrWebhookEndpointURLNotAllowed,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := service.Create(&models.WebhookEndpointInput{URL: testCase.url}, admin)
+
+			if testCase.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, testCase.expectedError), err)
+			}
+		})
+	}
+}
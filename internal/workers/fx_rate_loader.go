@@ -0,0 +1,71 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+)
+
+type FxRateRepository interface {
+	SaveFxRates(rates []*models.FxRate) (int, error)
+}
+
+// Source of daily FX rates, e.g. local file or external rates API
+type FxRateProvider interface {
+	Rates(ctx context.Context) ([]*models.FxRate, error)
+}
+
+// Background worker which periodically loads FX rates from provider to DB
+type FxRateLoader struct {
+	repo     FxRateRepository
+	provider FxRateProvider
+	interval time.Duration
+}
+
+func NewFxRateLoader(repo FxRateRepository, provider FxRateProvider, interval time.Duration) *FxRateLoader {
+	/*FX rate loader constructor function.*/
+	return &FxRateLoader{repo: repo, provider: provider, interval: interval}
+}
+
+func (loader *FxRateLoader) Run(ctx context.Context) {
+	/*Load rates at once and then periodically until context is canceled.*/
+	var ticker *time.Ticker = time.NewTicker(loader.interval)
+	defer ticker.Stop()
+
+	log.Println("FxRateLoader: started.")
+
+	for {
+		if err := loader.Load(ctx); err != nil {
+			log.Printf("loader.Load failed: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("FxRateLoader: stopped.")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (loader *FxRateLoader) Load(ctx context.Context) error {
+	/*Retrieve rates from provider and save new or changed ones.*/
+	rates, err := loader.provider.Rates(ctx)
+	if err != nil {
+		return fmt.Errorf("loader.provider.Rates failed: %w", err)
+	}
+
+	saved, err := loader.repo.SaveFxRates(rates)
+	if err != nil {
+		return fmt.Errorf("loader.repo.SaveFxRates failed: %w", err)
+	}
+
+	if saved > 0 {
+		log.Printf("FxRateLoader: %d of %d rates saved.", saved, len(rates))
+	}
+
+	return nil
+}
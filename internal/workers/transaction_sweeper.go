@@ -0,0 +1,70 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+type TransactionExpirer interface {
+	Expire(limit int) (int, error)
+}
+
+// Background worker which moves expired NEW transactions to EXPIRED status by batches
+type TransactionSweeper struct {
+	service   TransactionExpirer
+	interval  time.Duration
+	batchSize int
+}
+
+func NewTransactionSweeper(service TransactionExpirer, interval time.Duration, batchSize int) *TransactionSweeper {
+	/*Transaction sweeper constructor function.*/
+	return &TransactionSweeper{service: service, interval: interval, batchSize: batchSize}
+}
+
+func (sweeper *TransactionSweeper) Run(ctx context.Context) {
+	/*Sweep expired transactions periodically until context is canceled.*/
+	var ticker *time.Ticker = time.NewTicker(sweeper.interval)
+	defer ticker.Stop()
+
+	log.Println("TransactionSweeper: started.")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("TransactionSweeper: stopped.")
+			return
+		case <-ticker.C:
+			if err := sweeper.Sweep(ctx); err != nil {
+				log.Printf("sweeper.Sweep failed: %s", err.Error())
+			}
+		}
+	}
+}
+
+func (sweeper *TransactionSweeper) Sweep(ctx context.Context) error {
+	/*
+		Expire batches of transactions until there are no more expired ones or context is canceled,
+		batch which is in progress is always completed.
+	*/
+	var total int
+
+	for ctx.Err() == nil {
+		expired, err := sweeper.service.Expire(sweeper.batchSize)
+		if err != nil {
+			return fmt.Errorf("sweeper.service.Expire failed: %w", err)
+		}
+		total += expired
+
+		if expired < sweeper.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("TransactionSweeper: %d transactions expired.", total)
+	}
+
+	return nil
+}
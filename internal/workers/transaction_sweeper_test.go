@@ -0,0 +1,119 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TransactionExpirer implementation with fixed number of expired transactions
+type transactionExpirerStub struct {
+	mu      sync.Mutex
+	pending int
+	batches []int
+	err     error
+	// called after every batch
+	onBatch func()
+}
+
+func (stub *transactionExpirerStub) Expire(limit int) (int, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	if stub.err != nil {
+		return 0, stub.err
+	}
+
+	expired := limit
+	if stub.pending < limit {
+		expired = stub.pending
+	}
+	stub.pending -= expired
+	stub.batches = append(stub.batches, expired)
+	if stub.onBatch != nil {
+		stub.onBatch()
+	}
+	return expired, nil
+}
+
+func TestTransactionSweeper_Sweep(t *testing.T) {
+	testTable := []struct {
+		name            string
+		pending         int
+		expectedBatches []int
+	}{
+		{name: "Test no expired transactions", pending: 0, expectedBatches: []int{0}},
+		{name: "Test partial batch", pending: 3, expectedBatches: []int{3}},
+		{name: "Test several batches", pending: 25, expectedBatches: []int{10, 10, 5}},
+		{name: "Test full batches", pending: 20, expectedBatches: []int{10, 10, 0}},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			service := &transactionExpirerStub{pending: testCase.pending}
+			sweeper := NewTransactionSweeper(service, time.Minute, 10)
+
+			err := sweeper.Sweep(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedBatches, service.batches)
+			assert.Equal(t, 0, service.pending)
+		})
+	}
+}
+
+func TestTransactionSweeper_SweepCanceled(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	service := &transactionExpirerStub{pending: 100, onBatch: cancel}
+	sweeper := NewTransactionSweeper(service, time.Minute, 10)
+
+	// Act
+	err := sweeper.Sweep(ctx)
+
+	// Assert
+	// batch in progress is completed, the next one is not started
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10}, service.batches)
+}
+
+func TestTransactionSweeper_SweepError(t *testing.T) {
+	service := &transactionExpirerStub{pending: 10, err: errors.New("some error")}
+	sweeper := NewTransactionSweeper(service, time.Minute, 10)
+
+	err := sweeper.Sweep(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestTransactionSweeper_Run(t *testing.T) {
+	// Arrange
+	var done chan struct{} = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	service := &transactionExpirerStub{pending: 5}
+	sweeper := NewTransactionSweeper(service, 10*time.Millisecond, 10)
+
+	// Act
+	go func() {
+		sweeper.Run(ctx)
+		close(done)
+	}()
+
+	// Assert
+	assert.Eventually(t, func() bool {
+		service.mu.Lock()
+		defer service.mu.Unlock()
+		return service.pending == 0
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper was not stopped")
+	}
+}
@@ -0,0 +1,263 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+)
+
+const (
+	// Headers sent with every webhook request
+	WebhookIdHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+
+	// Max amount of deliveries performed by one dispatch
+	webhookDispatchBatchSize = 100
+	// Max length of response body saved as delivery error
+	webhookErrorMaxLength = 512
+)
+
+type WebhookDeliveryRepository interface {
+	ClaimWebhookDeliveries(limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	UpdateWebhookDelivery(delivery *models.WebhookDelivery) error
+}
+
+// Webhook request body
+type webhookMessage struct {
+	Id        int             `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Background worker which delivers outbox events to webhook endpoints
+type WebhookDispatcher struct {
+	repo        WebhookDeliveryRepository
+	client      *http.Client
+	interval    time.Duration
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+}
+
+func NewWebhookDispatcher(
+	repo WebhookDeliveryRepository,
+	client *http.Client,
+	interval time.Duration,
+	maxAttempts int,
+	baseBackoff time.Duration,
+	maxBackoff time.Duration,
+) *WebhookDispatcher {
+	/*Webhook dispatcher constructor function.*/
+	return &WebhookDispatcher{
+		repo:        repo,
+		client:      client,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		baseBackoff: baseBackoff,
+		maxBackoff:  maxBackoff,
+		now:         time.Now,
+	}
+}
+
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	/*
+		Return HTTP client for webhook requests which refuses to connect to internal network addresses.
+
+		Address is checked after host resolution on every connection (redirects included), so endpoint host
+		can not be moved to internal network after registration.
+	*/
+	var dialer *net.Dialer = &net.Dialer{Timeout: timeout, Control: webhookDialControl}
+	var transport *http.Transport = http.DefaultTransport.(*http.Transport).Clone()
+
+	// requests are not sent through proxy, otherwise proxy address is checked instead of endpoint one
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func (dispatcher *WebhookDispatcher) Run(ctx context.Context) {
+	/*Dispatch pending deliveries periodically until context is canceled.*/
+	var ticker *time.Ticker = time.NewTicker(dispatcher.interval)
+	defer ticker.Stop()
+
+	log.Println("WebhookDispatcher: started.")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("WebhookDispatcher: stopped.")
+			return
+		case <-ticker.C:
+			if err := dispatcher.Dispatch(ctx); err != nil {
+				log.Printf("dispatcher.Dispatch failed: %s", err.Error())
+			}
+		}
+	}
+}
+
+func (dispatcher *WebhookDispatcher) Dispatch(ctx context.Context) error {
+	/*Perform one attempt of delivery for every pending delivery which next attempt time has come.*/
+	var err error
+	var deliveries []*models.WebhookDelivery
+
+	// claim deliveries for the time enough to perform all of them
+	deliveries, err = dispatcher.repo.ClaimWebhookDeliveries(
+		webhookDispatchBatchSize, dispatcher.client.Timeout*webhookDispatchBatchSize+dispatcher.interval)
+
+	if err != nil {
+		return fmt.Errorf("dispatcher.repo.ClaimWebhookDeliveries failed: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			// not performed deliveries are returned to the queue after lease expiration
+			return nil
+		}
+
+		// attempt interrupted by shutdown is not recorded, delivery is due again after lease expiration
+		if !dispatcher.deliver(ctx, delivery) {
+			return nil
+		}
+
+		if err = dispatcher.repo.UpdateWebhookDelivery(delivery); err != nil {
+			return fmt.Errorf("dispatcher.repo.UpdateWebhookDelivery failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (dispatcher *WebhookDispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) bool {
+	/*
+		Send webhook request and fill delivery struct with attempt result.
+
+		Return false if request failed because context is canceled, delivery struct is not changed in this case.
+	*/
+	var err error = dispatcher.send(ctx, delivery)
+
+	if err != nil && ctx.Err() != nil {
+		return false
+	}
+
+	delivery.Attempts++
+
+	if err == nil {
+		delivery.Status = services.WebhookDeliveryDeliveredStatus
+		delivery.LastError = ""
+		return true
+	}
+
+	delivery.LastError = err.Error()
+
+	// move delivery to dead-letter state when all of attempts are exhausted
+	if delivery.Attempts >= dispatcher.maxAttempts {
+		log.Printf("WebhookDispatcher: delivery %d is dead: %s", delivery.Id, delivery.LastError)
+		delivery.Status = services.WebhookDeliveryDeadStatus
+		return true
+	}
+
+	delivery.NextAttemptAt = dispatcher.now().Add(dispatcher.backoff(delivery.Attempts))
+	return true
+}
+
+func (dispatcher *WebhookDispatcher) send(ctx context.Context, delivery *models.WebhookDelivery) error {
+	/*Send signed webhook request, any non 2xx response status is treated as failure.*/
+	var err error
+	var body []byte
+	var request *http.Request
+	var response *http.Response
+	var timestamp int64 = dispatcher.now().Unix()
+
+	body, err = json.Marshal(webhookMessage{
+		Id:        delivery.EventId,
+		Type:      delivery.EventType,
+		CreatedAt: delivery.EventCreatedAt,
+		Data:      delivery.EventPayload,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err = http.NewRequestWithContext(ctx, http.MethodPost, delivery.EndpointURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookIdHeader, strconv.Itoa(delivery.EventId))
+	request.Header.Set(WebhookEventHeader, delivery.EventType)
+	request.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(delivery.EndpointSecret, timestamp, body))
+
+	response, err = dispatcher.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, webhookErrorMaxLength))
+		return fmt.Errorf("unexpected response status %d: %s", response.StatusCode, responseBody)
+	}
+
+	return nil
+}
+
+func (dispatcher *WebhookDispatcher) backoff(attempts int) time.Duration {
+	/*Return delay before the next attempt, delay is doubled after every failed attempt.*/
+	var delay time.Duration = dispatcher.baseBackoff
+
+	for i := 1; i < attempts && delay < dispatcher.maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > dispatcher.maxBackoff {
+		delay = dispatcher.maxBackoff
+	}
+
+	return delay
+}
+
+func webhookDialControl(network string, address string, conn syscall.RawConn) error {
+	/*Reject connection to resolved address which is not allowed to receive webhooks.*/
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !services.IsWebhookIPAllowed(ip) {
+		return fmt.Errorf("webhook address %s is not allowed", address)
+	}
+
+	return nil
+}
+
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	/*Return hex encoded HMAC-SHA256 signature of "{timestamp}.{body}" string.*/
+	var mac = hmac.New(sha256.New, []byte(secret))
+
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,232 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Pythonyan3/payment-service/internal/models"
+	"github.com/Pythonyan3/payment-service/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// in-memory WebhookDeliveryRepository implementation
+type webhookDeliveryRepositoryStub struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	deliveries []*models.WebhookDelivery
+}
+
+func (stub *webhookDeliveryRepositoryStub) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	claimed := make([]*models.WebhookDelivery, 0)
+	for _, delivery := range stub.deliveries {
+		if delivery.Status == services.WebhookDeliveryPendingStatus && !delivery.NextAttemptAt.After(stub.now()) {
+			delivery.NextAttemptAt = stub.now().Add(lease)
+			copied := *delivery
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+func (stub *webhookDeliveryRepositoryStub) UpdateWebhookDelivery(delivery *models.WebhookDelivery) error {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	for i, stored := range stub.deliveries {
+		if stored.Id == delivery.Id {
+			copied := *delivery
+			stub.deliveries[i] = &copied
+		}
+	}
+	return nil
+}
+
+func newTestDelivery(url string) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		Id:             1,
+		EventId:        10,
+		EndpointId:     100,
+		Status:         services.WebhookDeliveryPendingStatus,
+		EndpointURL:    url,
+		EndpointSecret: "secret",
+		EventType:      services.TransactionStatusChangedEvent,
+		EventPayload:   []byte(`{"id":1,"status":"SUCCESS"}`),
+	}
+}
+
+func TestWebhookDispatcher_Dispatch(t *testing.T) {
+	// Arrange
+	var received []*http.Request
+	var receivedBodies [][]byte
+	now := time.Date(2022, 6, 12, 18, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		receivedBodies = append(receivedBodies, body)
+	}))
+	defer server.Close()
+
+	repo := &webhookDeliveryRepositoryStub{
+		now:        func() time.Time { return now },
+		deliveries: []*models.WebhookDelivery{newTestDelivery(server.URL)},
+	}
+	dispatcher := NewWebhookDispatcher(repo, server.Client(), time.Second, 3, time.Second, time.Minute)
+	dispatcher.now = repo.now
+
+	// Act
+	err := dispatcher.Dispatch(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	assert.Equal(t, services.WebhookDeliveryDeliveredStatus, repo.deliveries[0].Status)
+	assert.Equal(t, 1, repo.deliveries[0].Attempts)
+
+	request := received[0]
+	timestamp, err := strconv.ParseInt(request.Header.Get(WebhookTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, now.Unix(), timestamp)
+	assert.Equal(t, "10", request.Header.Get(WebhookIdHeader))
+	assert.Equal(t, services.TransactionStatusChangedEvent, request.Header.Get(WebhookEventHeader))
+	assert.Equal(
+		t, "sha256="+SignWebhook("secret", timestamp, receivedBodies[0]), request.Header.Get(WebhookSignatureHeader),
+	)
+
+	var message webhookMessage
+	require.NoError(t, json.Unmarshal(receivedBodies[0], &message))
+	assert.Equal(t, 10, message.Id)
+	assert.JSONEq(t, `{"id":1,"status":"SUCCESS"}`, string(message.Data))
+}
+
+func TestWebhookDispatcher_Retries(t *testing.T) {
+	// Arrange
+	var attempts int
+	now := time.Date(2022, 6, 12, 18, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := &webhookDeliveryRepositoryStub{
+		now:        func() time.Time { return now },
+		deliveries: []*models.WebhookDelivery{newTestDelivery(server.URL)},
+	}
+	dispatcher := NewWebhookDispatcher(repo, server.Client(), time.Second, 3, time.Second, time.Minute)
+	dispatcher.now = repo.now
+
+	// Act & Assert
+	// first failed attempt, retry is scheduled after base backoff
+	require.NoError(t, dispatcher.Dispatch(context.Background()))
+	assert.Equal(t, services.WebhookDeliveryPendingStatus, repo.deliveries[0].Status)
+	assert.Equal(t, now.Add(time.Second), repo.deliveries[0].NextAttemptAt)
+	assert.Contains(t, repo.deliveries[0].LastError, "503")
+
+	// delivery is not performed before next attempt time
+	require.NoError(t, dispatcher.Dispatch(context.Background()))
+	assert.Equal(t, 1, attempts)
+
+	// second failed attempt, backoff is doubled
+	now = now.Add(time.Second)
+	require.NoError(t, dispatcher.Dispatch(context.Background()))
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, now.Add(2*time.Second), repo.deliveries[0].NextAttemptAt)
+
+	// third failed attempt, delivery is moved to dead-letter state
+	now = now.Add(2 * time.Second)
+	require.NoError(t, dispatcher.Dispatch(context.Background()))
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, services.WebhookDeliveryDeadStatus, repo.deliveries[0].Status)
+	assert.Equal(t, 3, repo.deliveries[0].Attempts)
+
+	// dead delivery is not performed anymore
+	now = now.Add(time.Hour)
+	require.NoError(t, dispatcher.Dispatch(context.Background()))
+	assert.Equal(t, 3, attempts)
+}
+
+func TestWebhookDispatcher_Canceled(t *testing.T) {
+	// Arrange
+	now := time.Date(2022, 6, 12, 18, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	released := make(chan struct{})
+
+	// shutdown happens while the request is in flight
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-released
+	}))
+	defer server.Close()
+	defer close(released)
+
+	repo := &webhookDeliveryRepositoryStub{
+		now:        func() time.Time { return now },
+		deliveries: []*models.WebhookDelivery{newTestDelivery(server.URL)},
+	}
+	dispatcher := NewWebhookDispatcher(repo, server.Client(), time.Second, 3, time.Second, time.Minute)
+	dispatcher.now = repo.now
+
+	// Act
+	err := dispatcher.Dispatch(ctx)
+
+	// Assert
+	// interrupted attempt is not recorded, delivery is left claimed until lease expiration
+	require.NoError(t, err)
+	assert.Equal(t, services.WebhookDeliveryPendingStatus, repo.deliveries[0].Status)
+	assert.Equal(t, 0, repo.deliveries[0].Attempts)
+	assert.Empty(t, repo.deliveries[0].LastError)
+	assert.True(t, repo.deliveries[0].NextAttemptAt.After(now))
+}
+
+func TestWebhookDispatcher_InternalAddress(t *testing.T) {
+	// Arrange
+	var received int
+	now := time.Date(2022, 6, 12, 18, 0, 0, 0, time.UTC)
+
+	// test server listens on loopback address
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+	}))
+	defer server.Close()
+
+	repo := &webhookDeliveryRepositoryStub{
+		now:        func() time.Time { return now },
+		deliveries: []*models.WebhookDelivery{newTestDelivery(server.URL)},
+	}
+	dispatcher := NewWebhookDispatcher(repo, NewWebhookClient(time.Second), time.Second, 3, time.Second, time.Minute)
+	dispatcher.now = repo.now
+
+	// Act
+	err := dispatcher.Dispatch(context.Background())
+
+	// Assert
+	// connection is refused before request is sent, attempt is recorded as failed
+	require.NoError(t, err)
+	assert.Equal(t, 0, received)
+	assert.Equal(t, 1, repo.deliveries[0].Attempts)
+	assert.Contains(t, repo.deliveries[0].LastError, "is not allowed")
+}
+
+func TestWebhookDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(nil, nil, time.Second, 10, time.Second, 10*time.Second)
+
+	assert.Equal(t, time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 2*time.Second, dispatcher.backoff(2))
+	assert.Equal(t, 8*time.Second, dispatcher.backoff(4))
+	assert.Equal(t, 10*time.Second, dispatcher.backoff(5))
+	assert.Equal(t, 10*time.Second, dispatcher.backoff(50))
+}
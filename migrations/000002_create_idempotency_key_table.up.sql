@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "idempotency_key" (
+    -- keys are unique per authenticated subject, so clients can not replay responses of each other
+    subject varchar(255) not null default '',
+    key varchar(255) not null,
+    request_hash char(64) not null,
+    response_status integer,
+    -- headers of stored response (e.g. Content-Type) are replayed along with it's body
+    response_headers jsonb,
+    response_body bytea,
+    created_at timestamp with time zone default now()::timestamptz,
+    expires_at timestamp with time zone not null,
+    primary key (subject, key)
+);
+
+COMMIT;
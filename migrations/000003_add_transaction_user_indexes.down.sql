@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS transaction_user_email_created_at_idx;
+DROP INDEX IF EXISTS transaction_user_id_created_at_idx;
+
+COMMIT;
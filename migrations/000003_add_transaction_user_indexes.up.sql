@@ -0,0 +1,6 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS transaction_user_id_created_at_idx ON "transaction" (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS transaction_user_email_created_at_idx ON "transaction" (user_email, created_at DESC, id DESC);
+
+COMMIT;
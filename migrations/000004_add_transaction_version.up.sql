@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS version integer not null default 0;
+
+COMMIT;
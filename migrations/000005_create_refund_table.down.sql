@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP TABLE IF EXISTS "refund";
+
+ALTER TABLE "transaction" DROP CONSTRAINT IF EXISTS transaction_status_check;
+ALTER TABLE "transaction" ADD CONSTRAINT transaction_status_check CHECK (
+    status IN ('NEW', 'ERROR', 'SUCCESS', 'FAILED', 'CANCELED')
+);
+ALTER TABLE "transaction" ALTER COLUMN status TYPE varchar(8);
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+ALTER TABLE "transaction" ALTER COLUMN status TYPE varchar(18);
+ALTER TABLE "transaction" DROP CONSTRAINT IF EXISTS transaction_status_check;
+ALTER TABLE "transaction" ADD CONSTRAINT transaction_status_check CHECK (
+    status IN ('NEW', 'ERROR', 'SUCCESS', 'FAILED', 'CANCELED', 'PARTIALLY_REFUNDED', 'REFUNDED')
+);
+
+CREATE TABLE IF NOT EXISTS "refund" (
+    id serial not null unique,
+    transaction_id integer not null references "transaction" (id),
+    amount bigint not null CHECK (amount > 0),
+    status varchar(8) not null default 'PENDING' CHECK (status IN ('PENDING', 'SUCCESS', 'FAILED')),
+    created_at timestamp with time zone default now()::timestamptz,
+    updated_at timestamp with time zone default now()::timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS refund_transaction_id_idx ON "refund" (transaction_id);
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "webhook_endpoint" (
+    id serial not null unique,
+    url varchar(2048) not null,
+    secret varchar(128) not null,
+    is_active boolean not null default true,
+    created_at timestamp with time zone default now()::timestamptz
+);
+
+-- transactional outbox, events are inserted in the same db transaction as transaction changes
+CREATE TABLE IF NOT EXISTS "webhook_event" (
+    id serial not null unique,
+    event_type varchar(64) not null,
+    transaction_id integer not null references "transaction" (id),
+    payload jsonb not null,
+    created_at timestamp with time zone default now()::timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS "webhook_delivery" (
+    id serial not null unique,
+    event_id integer not null references "webhook_event" (id),
+    endpoint_id integer not null references "webhook_endpoint" (id),
+    status varchar(9) not null default 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts integer not null default 0,
+    next_attempt_at timestamp with time zone not null default now()::timestamptz,
+    last_error text not null default '',
+    created_at timestamp with time zone default now()::timestamptz,
+    updated_at timestamp with time zone default now()::timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_pending_idx ON "webhook_delivery" (next_attempt_at) WHERE status = 'PENDING';
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- service-to-service credentials, only SHA-256 hash of the key is stored
+CREATE TABLE IF NOT EXISTS "api_key" (
+    id serial not null unique,
+    provider varchar(255) not null,
+    prefix varchar(16) not null,
+    key_hash char(64) not null unique,
+    scopes text[] not null default '{}',
+    created_at timestamp with time zone default now()::timestamptz,
+    last_used_at timestamp with time zone,
+    revoked_at timestamp with time zone
+);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "transaction" DROP CONSTRAINT IF EXISTS transaction_amount_positive;
+
+COMMIT;
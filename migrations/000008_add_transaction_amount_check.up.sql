@@ -0,0 +1,6 @@
+BEGIN;
+
+-- amounts are positive values in minor units of currency, existing rows are not checked
+ALTER TABLE "transaction" ADD CONSTRAINT transaction_amount_positive CHECK (amount > 0) NOT VALID;
+
+COMMIT;
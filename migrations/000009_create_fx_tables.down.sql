@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS "fx_conversion";
+DROP TABLE IF EXISTS "fx_rate";
+
+COMMIT;
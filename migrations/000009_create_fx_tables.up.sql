@@ -0,0 +1,30 @@
+BEGIN;
+
+-- daily FX rates, rate is amount of quote currency for one unit of base currency
+CREATE TABLE IF NOT EXISTS "fx_rate" (
+    id serial not null unique,
+    base_currency char(3) not null,
+    quote_currency char(3) not null,
+    rate numeric not null check (rate > 0),
+    effective_date date not null,
+    source varchar(255) not null,
+    created_at timestamp with time zone default now()::timestamptz,
+    updated_at timestamp with time zone default now()::timestamptz,
+    unique (base_currency, quote_currency, effective_date)
+);
+
+-- audit of transaction amounts conversions, the same conversion is recorded once
+CREATE TABLE IF NOT EXISTS "fx_conversion" (
+    id serial not null unique,
+    transaction_id integer not null references "transaction" (id),
+    rate_id integer not null references "fx_rate" (id),
+    from_currency char(3) not null,
+    to_currency char(3) not null,
+    rate numeric not null,
+    amount bigint not null,
+    converted_amount bigint not null,
+    created_at timestamp with time zone default now()::timestamptz,
+    unique (transaction_id, rate_id, to_currency, rate)
+);
+
+COMMIT;
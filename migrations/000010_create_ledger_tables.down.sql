@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS "ledger_posting";
+DROP TABLE IF EXISTS "ledger_entry";
+DROP TABLE IF EXISTS "ledger_account";
+DROP FUNCTION IF EXISTS ledger_entry_balance_check();
+DROP FUNCTION IF EXISTS ledger_posting_immutable();
+
+COMMIT;
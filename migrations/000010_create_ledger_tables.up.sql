@@ -0,0 +1,101 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "ledger_account" (
+    id serial not null unique,
+    code varchar(255) not null,
+    currency char(3) not null,
+    created_at timestamp with time zone default now()::timestamptz,
+    unique (code, currency)
+);
+
+CREATE TABLE IF NOT EXISTS "ledger_entry" (
+    id serial not null unique,
+    kind varchar(16) not null CHECK (kind IN ('authorize', 'capture', 'release', 'refund')),
+    transaction_id integer references "transaction" (id),
+    refund_id integer references "refund" (id),
+    created_at timestamp with time zone default now()::timestamptz
+);
+
+-- debit amounts are positive, credit amounts are negative
+CREATE TABLE IF NOT EXISTS "ledger_posting" (
+    id serial not null unique,
+    entry_id integer not null references "ledger_entry" (id),
+    account_id integer not null references "ledger_account" (id),
+    amount bigint not null CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entry_transaction_id_idx ON "ledger_entry" (transaction_id);
+CREATE INDEX IF NOT EXISTS ledger_posting_entry_id_idx ON "ledger_posting" (entry_id);
+CREATE INDEX IF NOT EXISTS ledger_posting_account_id_idx ON "ledger_posting" (account_id);
+
+-- postings of every entry must be balanced per currency, checked on commit
+CREATE OR REPLACE FUNCTION ledger_entry_balance_check() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM "ledger_posting" posting
+        JOIN "ledger_account" account ON account.id = posting.account_id
+        WHERE posting.entry_id = NEW.entry_id
+        GROUP BY account.currency
+        HAVING sum(posting.amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'ledger entry % is not balanced', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_posting_balance_check AFTER INSERT ON "ledger_posting"
+DEFERRABLE INITIALLY DEFERRED FOR EACH ROW EXECUTE PROCEDURE ledger_entry_balance_check();
+
+-- postings are immutable, mistakes are corrected by new entries
+CREATE OR REPLACE FUNCTION ledger_posting_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger postings can not be changed';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_posting_immutable BEFORE UPDATE OR DELETE ON "ledger_posting"
+FOR EACH ROW EXECUTE PROCEDURE ledger_posting_immutable();
+
+-- backfill entries of existing transactions and refunds according to their current statuses
+INSERT INTO "ledger_entry" (kind, transaction_id, created_at)
+SELECT 'authorize', id, created_at FROM "transaction" WHERE status <> 'ERROR';
+
+INSERT INTO "ledger_entry" (kind, transaction_id, created_at)
+SELECT 'capture', id, updated_at FROM "transaction"
+WHERE status IN ('SUCCESS', 'PARTIALLY_REFUNDED', 'REFUNDED');
+
+INSERT INTO "ledger_entry" (kind, transaction_id, created_at)
+SELECT 'release', id, updated_at FROM "transaction" WHERE status IN ('FAILED', 'CANCELED');
+
+INSERT INTO "ledger_entry" (kind, transaction_id, refund_id, created_at)
+SELECT 'refund', transaction_id, id, updated_at FROM "refund" WHERE status = 'SUCCESS';
+
+CREATE TEMPORARY TABLE ledger_backfill ON COMMIT DROP AS
+SELECT entry.id AS entry_id, posting.code, t.currency, posting.amount
+FROM "ledger_entry" entry
+JOIN "transaction" t ON t.id = entry.transaction_id
+LEFT JOIN "refund" r ON r.id = entry.refund_id
+CROSS JOIN LATERAL (VALUES
+    (CASE entry.kind
+        WHEN 'authorize' THEN 'user:' || t.user_id
+        WHEN 'refund' THEN 'revenue'
+        ELSE 'holds' END,
+    COALESCE(r.amount, t.amount)),
+    (CASE entry.kind
+        WHEN 'authorize' THEN 'holds'
+        WHEN 'capture' THEN 'revenue'
+        ELSE 'user:' || t.user_id END,
+    -COALESCE(r.amount, t.amount))
+) AS posting (code, amount);
+
+INSERT INTO "ledger_account" (code, currency)
+SELECT DISTINCT code, currency FROM ledger_backfill
+ON CONFLICT (code, currency) DO NOTHING;
+
+INSERT INTO "ledger_posting" (entry_id, account_id, amount)
+SELECT backfill.entry_id, account.id, backfill.amount FROM ledger_backfill backfill
+JOIN "ledger_account" account ON account.code = backfill.code AND account.currency = backfill.currency
+ORDER BY backfill.entry_id;
+
+COMMIT;
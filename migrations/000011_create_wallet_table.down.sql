@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE "ledger_entry" DROP CONSTRAINT IF EXISTS ledger_entry_kind_check;
+ALTER TABLE "ledger_entry" ADD CONSTRAINT ledger_entry_kind_check CHECK (
+    kind IN ('authorize', 'capture', 'release', 'refund')
+);
+ALTER TABLE "ledger_entry" DROP COLUMN IF EXISTS wallet_id;
+DROP TABLE IF EXISTS "wallet";
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+-- user balances per currency in minor units, available funds can not be overdrawn
+CREATE TABLE IF NOT EXISTS "wallet" (
+    id serial not null unique,
+    user_id integer not null,
+    currency char(3) not null,
+    available bigint not null default 0 CHECK (available >= 0),
+    held bigint not null default 0 CHECK (held >= 0),
+    created_at timestamp with time zone default now()::timestamptz,
+    updated_at timestamp with time zone default now()::timestamptz,
+    unique (user_id, currency)
+);
+
+-- amounts of existing NEW transactions are held, so they can be captured or released
+INSERT INTO "wallet" (user_id, currency, held)
+SELECT user_id, currency, SUM(amount) FROM "transaction" WHERE status = 'NEW' GROUP BY user_id, currency;
+
+-- deposits to wallets are recorded to the ledger
+ALTER TABLE "ledger_entry" ADD COLUMN IF NOT EXISTS wallet_id integer references "wallet" (id);
+ALTER TABLE "ledger_entry" DROP CONSTRAINT IF EXISTS ledger_entry_kind_check;
+ALTER TABLE "ledger_entry" ADD CONSTRAINT ledger_entry_kind_check CHECK (
+    kind IN ('authorize', 'capture', 'release', 'refund', 'deposit')
+);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE "wallet" DROP CONSTRAINT IF EXISTS wallet_user_id_fkey;
+ALTER TABLE "transaction" DROP CONSTRAINT IF EXISTS transaction_user_id_fkey;
+DROP TABLE IF EXISTS "users";
+
+COMMIT;
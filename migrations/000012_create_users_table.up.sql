@@ -0,0 +1,39 @@
+BEGIN;
+
+-- "user" is reserved word, so table name is plural
+CREATE TABLE IF NOT EXISTS "users" (
+    id serial not null unique,
+    email varchar(254) not null,
+    name varchar(128) not null default '',
+    created_at timestamp with time zone default now()::timestamptz,
+    updated_at timestamp with time zone default now()::timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx ON "users" (lower(email));
+
+-- every user gets email of it's latest transaction, email shared by several users is kept by the user
+-- with the lowest id, others get placeholder address of reserved .invalid domain to be updated later
+INSERT INTO "users" (id, email, created_at)
+SELECT
+    id,
+    CASE WHEN row_number() OVER (PARTITION BY lower(email) ORDER BY id) = 1
+        THEN email ELSE 'user' || id || '@users.invalid' END,
+    created_at
+FROM (
+    SELECT DISTINCT ON (user_id)
+        user_id AS id, user_email AS email, MIN(created_at) OVER (PARTITION BY user_id) AS created_at
+    FROM "transaction"
+    ORDER BY user_id, created_at DESC, id DESC
+) latest;
+
+-- users funded wallets without any transaction
+INSERT INTO "users" (id, email)
+SELECT DISTINCT user_id, 'user' || user_id || '@users.invalid' FROM "wallet"
+ON CONFLICT (id) DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('users', 'id'), COALESCE((SELECT MAX(id) FROM "users"), 0) + 1, false);
+
+ALTER TABLE "transaction" ADD CONSTRAINT transaction_user_id_fkey FOREIGN KEY (user_id) REFERENCES "users" (id);
+ALTER TABLE "wallet" ADD CONSTRAINT wallet_user_id_fkey FOREIGN KEY (user_id) REFERENCES "users" (id);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS transaction_amount_idx;
+DROP INDEX IF EXISTS transaction_updated_at_idx;
+DROP INDEX IF EXISTS transaction_created_at_idx;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- indexes used by transactions search for every sort field, btree index is scanned in both directions
+CREATE INDEX IF NOT EXISTS transaction_created_at_idx ON "transaction" (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS transaction_updated_at_idx ON "transaction" (updated_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS transaction_amount_idx ON "transaction" (amount DESC, id DESC);
+
+COMMIT;
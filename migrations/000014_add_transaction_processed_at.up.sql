@@ -0,0 +1,14 @@
+BEGIN;
+
+-- time when NEW transaction got it's outcome (SUCCESS, FAILED or CANCELED), used by statistics
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS processed_at timestamp with time zone;
+
+-- outcome time of existing transactions is the time of their capture or release ledger entry
+UPDATE "transaction" t SET processed_at = entry.created_at
+FROM (
+    SELECT transaction_id, MIN(created_at) AS created_at FROM "ledger_entry"
+    WHERE kind IN ('capture', 'release') GROUP BY transaction_id
+) entry
+WHERE entry.transaction_id = t.id;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS transaction_provider_reference_idx;
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS provider_reference;
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS provider;
+
+COMMIT;
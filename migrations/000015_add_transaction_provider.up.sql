@@ -0,0 +1,11 @@
+BEGIN;
+
+-- payment provider processing transaction and provider's payment reference,
+-- existing transactions were processed outside of the service so they have no provider
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS provider varchar(64);
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS provider_reference varchar(128);
+
+CREATE UNIQUE INDEX IF NOT EXISTS transaction_provider_reference_idx
+    ON "transaction" (provider, provider_reference) WHERE provider IS NOT NULL;
+
+COMMIT;
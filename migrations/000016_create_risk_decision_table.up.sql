@@ -0,0 +1,12 @@
+BEGIN;
+
+-- outcome of risk rules evaluated on transaction creation with reasons of matched rules
+CREATE TABLE IF NOT EXISTS "risk_decision" (
+    id serial not null unique,
+    transaction_id integer not null unique references "transaction" (id),
+    outcome varchar(5) not null CHECK (outcome IN ('ALLOW', 'BLOCK')),
+    reasons jsonb not null default '[]',
+    created_at timestamp with time zone default now()::timestamptz
+);
+
+COMMIT;
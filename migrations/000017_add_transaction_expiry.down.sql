@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP INDEX IF EXISTS transaction_expires_at_idx;
+
+-- expired transactions released funds the same way as canceled ones
+UPDATE "transaction" SET status = 'CANCELED' WHERE status = 'EXPIRED';
+ALTER TABLE "transaction" DROP CONSTRAINT IF EXISTS transaction_status_check;
+ALTER TABLE "transaction" ADD CONSTRAINT transaction_status_check CHECK (
+    status IN ('NEW', 'ERROR', 'SUCCESS', 'FAILED', 'CANCELED', 'PARTIALLY_REFUNDED', 'REFUNDED')
+);
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS expires_at;
+
+COMMIT;
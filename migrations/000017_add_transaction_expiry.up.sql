@@ -0,0 +1,15 @@
+BEGIN;
+
+-- NEW transaction is moved to EXPIRED status by sweeper when it's expiration time has come
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS expires_at timestamp with time zone;
+ALTER TABLE "transaction" DROP CONSTRAINT IF EXISTS transaction_status_check;
+ALTER TABLE "transaction" ADD CONSTRAINT transaction_status_check CHECK (
+    status IN ('NEW', 'ERROR', 'SUCCESS', 'FAILED', 'CANCELED', 'PARTIALLY_REFUNDED', 'REFUNDED', 'EXPIRED')
+);
+
+-- existing NEW transactions get default time to live from the migration time
+UPDATE "transaction" SET expires_at = now()::timestamptz + interval '24 hours' WHERE status = 'NEW';
+
+CREATE INDEX IF NOT EXISTS transaction_expires_at_idx ON "transaction" (expires_at) WHERE status = 'NEW';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS webhook_endpoint_merchant_id_idx;
+ALTER TABLE "webhook_endpoint" DROP COLUMN IF EXISTS merchant_id;
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS merchant_id;
+
+COMMIT;
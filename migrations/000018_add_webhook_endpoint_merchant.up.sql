@@ -0,0 +1,11 @@
+BEGIN;
+
+-- merchant of the client which created transaction, transaction events are sent to endpoints of the merchant only
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS merchant_id varchar(255);
+
+-- endpoint without merchant is registered by admin and receives events of all transactions
+ALTER TABLE "webhook_endpoint" ADD COLUMN IF NOT EXISTS merchant_id varchar(255);
+
+CREATE INDEX IF NOT EXISTS webhook_endpoint_merchant_id_idx ON "webhook_endpoint" (merchant_id) WHERE is_active;
+
+COMMIT;